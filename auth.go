@@ -191,7 +191,9 @@ func (a *AuthTime) keyString() string {
 }
 
 // newAuthorization 通过一系列步骤生成最终需要的 Authorization 字符串
-func newAuthorization(secretID, secretKey string, req *http.Request, authTime *AuthTime, signHost bool) string {
+//
+//	extraSignHeaders: 除 NeedSignHeaders 外额外需要签入的头部
+func newAuthorization(secretID, secretKey string, req *http.Request, authTime *AuthTime, signHost bool, extraSignHeaders ...string) string {
 	signTime := authTime.signString()
 	keyTime := authTime.keyString()
 	signKey := calSignKey(secretKey, keyTime)
@@ -201,7 +203,7 @@ func newAuthorization(secretID, secretKey string, req *http.Request, authTime *A
 	}
 	formatHeaders := *new(string)
 	signedHeaderList := *new([]string)
-	formatHeaders, signedHeaderList = genFormatHeaders(req.Header)
+	formatHeaders, signedHeaderList = genFormatHeaders(req.Header, extraSignHeaders...)
 	formatParameters, signedParameterList := genFormatParameters(req.URL.Query())
 	formatString := genFormatString(req.Method, *req.URL, formatParameters, formatHeaders)
 
@@ -287,10 +289,10 @@ func genFormatParameters(parameters url.Values) (formatParameters string, signed
 }
 
 // genFormatHeaders 生成 FormatHeaders 和 SignedHeaderList
-func genFormatHeaders(headers http.Header) (formatHeaders string, signedHeaderList []string) {
+func genFormatHeaders(headers http.Header, extraSignHeaders ...string) (formatHeaders string, signedHeaderList []string) {
 	hs := valuesSignMap{}
 	for key, values := range headers {
-		if isSignHeader(strings.ToLower(key)) {
+		if isSignHeader(strings.ToLower(key)) || containsFold(extraSignHeaders, key) {
 			for _, value := range values {
 				hs.Add(key, value)
 				signedHeaderList = append(signedHeaderList, strings.ToLower(safeURLEncode(key)))
@@ -316,6 +318,15 @@ func calHMACDigest(key, msg, signMethod string) []byte {
 	return h.Sum(nil)
 }
 
+func containsFold(list []string, key string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, key) {
+			return true
+		}
+	}
+	return false
+}
+
 func isSignHeader(key string) bool {
 	for k, v := range NeedSignHeaders {
 		if key == k && v {
@@ -0,0 +1,279 @@
+package cos
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 签名校验失败的错误码
+const (
+	VerifyErrMissingAuthorization  = "MissingAuthorization"
+	VerifyErrInvalidAuthorization  = "InvalidAuthorization"
+	VerifyErrInvalidAccessKeyId    = "InvalidAccessKeyId"
+	VerifyErrRequestExpired        = "RequestExpired"
+	VerifyErrRequestNotYetValid    = "RequestNotYetValid"
+	VerifyErrSignatureDoesNotMatch = "SignatureDoesNotMatch"
+)
+
+var authorizationParams = map[string]bool{
+	"q-sign-algorithm": true,
+	"q-ak":             true,
+	"q-sign-time":      true,
+	"q-key-time":       true,
+	"q-header-list":    true,
+	"q-url-param-list": true,
+	"q-signature":      true,
+}
+
+// SignatureVerifyError 签名校验失败时返回的错误
+type SignatureVerifyError struct {
+	Code    string
+	Message string
+	// 请求中携带的 q-ak
+	SecretID string
+	// 服务端重新计算的 FormatString 与 StringToSign，便于排查签名不一致的问题
+	FormatString string
+	StringToSign string
+}
+
+func (e *SignatureVerifyError) Error() string {
+	if e.SecretID != "" {
+		return fmt.Sprintf("%v: %v (q-ak: %v)", e.Code, e.Message, e.SecretID)
+	}
+	return fmt.Sprintf("%v: %v", e.Code, e.Message)
+}
+
+// AuthorizationInfo 解析后的签名信息
+type AuthorizationInfo struct {
+	SignAlgorithm string
+	SecretID      string
+	SignStartTime time.Time
+	SignEndTime   time.Time
+	KeyStartTime  time.Time
+	KeyEndTime    time.Time
+	// q-sign-time 与 q-key-time 的原始值，参与签名计算
+	SignTime      string
+	KeyTime       string
+	HeaderList    []string
+	ParameterList []string
+	Signature     string
+}
+
+// SecretLookup 根据 SecretId 查找对应的 SecretKey
+type SecretLookup func(secretID string) (secretKey string, err error)
+
+// VerifyOptions is the option of VerifyRequest
+type VerifyOptions struct {
+	// 校验时使用的当前时间，为空时使用 time.Now()
+	Now time.Time
+	// 允许的时钟偏差
+	ClockSkew time.Duration
+}
+
+// ParseAuthorization 解析 q-sign-algorithm=sha1&q-ak=...&q-signature=... 格式的签名字符串
+func ParseAuthorization(authorization string) (*AuthorizationInfo, error) {
+	values := map[string]string{}
+	for _, pair := range strings.Split(authorization, "&") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, invalidAuthorizationError("malformed field %q", pair)
+		}
+		values[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+	}
+	return parseAuthorizationValues(values)
+}
+
+func parseAuthorizationValues(values map[string]string) (*AuthorizationInfo, error) {
+	for key := range authorizationParams {
+		if key == "q-header-list" || key == "q-url-param-list" {
+			continue
+		}
+		if values[key] == "" {
+			return nil, invalidAuthorizationError("missing %v", key)
+		}
+	}
+	info := &AuthorizationInfo{
+		SignAlgorithm: values["q-sign-algorithm"],
+		SecretID:      values["q-ak"],
+		SignTime:      values["q-sign-time"],
+		KeyTime:       values["q-key-time"],
+		HeaderList:    splitSignList(values["q-header-list"]),
+		ParameterList: splitSignList(values["q-url-param-list"]),
+		Signature:     values["q-signature"],
+	}
+	var err error
+	if info.SignStartTime, info.SignEndTime, err = parseSignTime(info.SignTime); err != nil {
+		return nil, invalidAuthorizationError("invalid q-sign-time %q", info.SignTime)
+	}
+	if info.KeyStartTime, info.KeyEndTime, err = parseSignTime(info.KeyTime); err != nil {
+		return nil, invalidAuthorizationError("invalid q-key-time %q", info.KeyTime)
+	}
+	return info, nil
+}
+
+func splitSignList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ToLower(s), ";")
+}
+
+func parseSignTime(s string) (start, end time.Time, err error) {
+	ts := strings.Split(s, ";")
+	if len(ts) != 2 {
+		return start, end, fmt.Errorf("invalid time range")
+	}
+	st, err := strconv.ParseInt(ts[0], 10, 64)
+	if err != nil {
+		return
+	}
+	et, err := strconv.ParseInt(ts[1], 10, 64)
+	if err != nil {
+		return
+	}
+	if st > et {
+		return start, end, fmt.Errorf("invalid time range")
+	}
+	return time.Unix(st, 0), time.Unix(et, 0), nil
+}
+
+func invalidAuthorizationError(format string, args ...interface{}) *SignatureVerifyError {
+	return &SignatureVerifyError{
+		Code:    VerifyErrInvalidAuthorization,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// requestAuthorization 从请求中取出签名，依次查找 Authorization 头部、sign 参数以及 q-* 参数，
+// 返回签名信息以及去除签名字段后的请求参数
+func requestAuthorization(req *http.Request) (*AuthorizationInfo, url.Values, error) {
+	query := req.URL.Query()
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		info, err := ParseAuthorization(auth)
+		return info, query, err
+	}
+	if sign := query.Get("sign"); sign != "" {
+		query.Del("sign")
+		info, err := ParseAuthorization(sign)
+		return info, query, err
+	}
+	if query.Get("q-sign-algorithm") != "" {
+		values := map[string]string{}
+		for key, vs := range query {
+			if authorizationParams[strings.ToLower(key)] {
+				values[strings.ToLower(key)] = vs[0]
+				query.Del(key)
+			}
+		}
+		info, err := parseAuthorizationValues(values)
+		return info, query, err
+	}
+	return nil, nil, &SignatureVerifyError{
+		Code:    VerifyErrMissingAuthorization,
+		Message: "request is not signed",
+	}
+}
+
+// VerifyRequest 校验请求的 COS 签名，签名可以位于 Authorization 头部或请求参数（预签名 URL）中。
+// 校验失败时返回 *SignatureVerifyError。
+func VerifyRequest(req *http.Request, lookup SecretLookup, opt ...*VerifyOptions) error {
+	var vopt VerifyOptions
+	if len(opt) > 0 && opt[0] != nil {
+		vopt = *opt[0]
+	}
+	if vopt.Now.IsZero() {
+		vopt.Now = time.Now()
+	}
+	info, query, err := requestAuthorization(req)
+	if err != nil {
+		return err
+	}
+	if info.SignAlgorithm != sha1SignAlgorithm {
+		return invalidAuthorizationError("unsupported q-sign-algorithm %q", info.SignAlgorithm)
+	}
+	verr := &SignatureVerifyError{SecretID: info.SecretID}
+
+	secretKey, err := lookup(info.SecretID)
+	if err != nil || secretKey == "" {
+		verr.Code = VerifyErrInvalidAccessKeyId
+		verr.Message = "the access key id does not exist"
+		if err != nil {
+			verr.Message = err.Error()
+		}
+		return verr
+	}
+
+	for _, window := range [][2]time.Time{
+		{info.SignStartTime, info.SignEndTime},
+		{info.KeyStartTime, info.KeyEndTime},
+	} {
+		if vopt.Now.Before(window[0].Add(-vopt.ClockSkew)) {
+			verr.Code = VerifyErrRequestNotYetValid
+			verr.Message = fmt.Sprintf("request is not valid until %v", window[0].UTC().Format(time.RFC3339))
+			return verr
+		}
+		if vopt.Now.After(window[1].Add(vopt.ClockSkew)) {
+			verr.Code = VerifyErrRequestExpired
+			verr.Message = fmt.Sprintf("request has expired at %v", window[1].UTC().Format(time.RFC3339))
+			return verr
+		}
+	}
+
+	// 只有 q-header-list 与 q-url-param-list 中声明的字段参与签名
+	headers := http.Header{}
+	for key, values := range req.Header {
+		if containsFold(info.HeaderList, safeURLEncode(key)) {
+			headers[key] = values
+		}
+	}
+	if containsFold(info.HeaderList, "host") {
+		host := req.Host
+		if host == "" {
+			host = req.URL.Host
+		}
+		headers.Set("Host", host)
+	}
+	params := url.Values{}
+	for key, values := range query {
+		if containsFold(info.ParameterList, safeURLEncode(key)) {
+			params[key] = values
+		}
+	}
+	formatHeaders, signedHeaderList := genFormatHeaders(headers, info.HeaderList...)
+	formatParameters, signedParameterList := genFormatParameters(params)
+	if missing := missingSignList(info.HeaderList, signedHeaderList); len(missing) > 0 {
+		verr.Code = VerifyErrSignatureDoesNotMatch
+		verr.Message = fmt.Sprintf("signed headers are missing: %v", strings.Join(missing, ";"))
+		return verr
+	}
+	if missing := missingSignList(info.ParameterList, signedParameterList); len(missing) > 0 {
+		verr.Code = VerifyErrSignatureDoesNotMatch
+		verr.Message = fmt.Sprintf("signed parameters are missing: %v", strings.Join(missing, ";"))
+		return verr
+	}
+
+	verr.FormatString = genFormatString(req.Method, *req.URL, formatParameters, formatHeaders)
+	verr.StringToSign = calStringToSign(sha1SignAlgorithm, info.KeyTime, verr.FormatString)
+	signature := calSignature(calSignKey(secretKey, info.KeyTime), verr.StringToSign)
+	if !hmac.Equal([]byte(signature), []byte(strings.ToLower(info.Signature))) {
+		verr.Code = VerifyErrSignatureDoesNotMatch
+		verr.Message = "the request signature we calculated does not match the signature you provided"
+		return verr
+	}
+	return nil
+}
+
+func missingSignList(declared, signed []string) []string {
+	var missing []string
+	for _, key := range declared {
+		if !containsFold(signed, key) {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,126 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func testSecretLookup(secretID string) (string, error) {
+	if secretID == "QmFzZTY0IGlzIGEgZ*******" {
+		return "ZfbOA78asKUYBcXFrJD0a1I*******", nil
+	}
+	return "", fmt.Errorf("unknown secret id %v", secretID)
+}
+
+func testVerifyErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+	if code == "" {
+		if err != nil {
+			t.Fatalf("VerifyRequest returned error: %v", err)
+		}
+		return
+	}
+	verr, ok := err.(*SignatureVerifyError)
+	if !ok {
+		t.Fatalf("VerifyRequest returned %v, want %v", err, code)
+	}
+	if verr.Code != code {
+		t.Fatalf("VerifyRequest returned code %v, want %v", verr.Code, code)
+	}
+}
+
+func TestVerifyRequest_Transport(t *testing.T) {
+	setup()
+	defer teardown()
+
+	u, _ := url.Parse(server.URL)
+	client := NewClient(&BaseURL{u, u, u, u, u, u}, &http.Client{
+		Transport: &AuthorizationTransport{
+			SecretID:     "QmFzZTY0IGlzIGEgZ*******",
+			SecretKey:    "ZfbOA78asKUYBcXFrJD0a1I*******",
+			SessionToken: "token-example",
+		},
+	})
+	client.Conf.EnableCRC = false
+	mux.HandleFunc("/dir/test.txt", func(w http.ResponseWriter, r *http.Request) {
+		if err := VerifyRequest(r, testSecretLookup); err != nil {
+			t.Errorf("VerifyRequest returned error: %v", err)
+		}
+	})
+	opt := &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			ContentType: "text/plain",
+			XCosMetaXXX: &http.Header{},
+		},
+	}
+	opt.XCosMetaXXX.Add("x-cos-meta-test", "test")
+	_, err := client.Object.Put(context.Background(), "dir/test.txt", bytes.NewReader([]byte("test")), opt)
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	u, _ := url.Parse("https://examplebucket-1250000000.cos.ap-guangzhou.myqcloud.com")
+	cred := NewTokenCredential("QmFzZTY0IGlzIGEgZ*******", "ZfbOA78asKUYBcXFrJD0a1I*******", "")
+	p := NewPresigner(&BaseURL{BucketURL: u}, cred)
+	startTime := time.Unix(int64(1622702557), 0)
+	now := &VerifyOptions{Now: startTime.Add(time.Minute)}
+	query := &url.Values{}
+	query.Set("versionId", "MTg0NDUxNTc1NjIzMTQ1MDAwODg")
+	header := &http.Header{}
+	header.Set("Content-Type", "text/plain")
+
+	// 预签名 URL
+	presignedURL, err := p.PresignURL(http.MethodGet, "dir/测试.txt", &PresignOptions{StartTime: startTime, Query: query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, presignedURL.String(), nil)
+	testVerifyErrorCode(t, VerifyRequest(req, testSecretLookup, now), "")
+
+	// 签名合并在 sign 参数中
+	presignedURL, _ = p.PresignURL(http.MethodGet, "dir/测试.txt", &PresignOptions{StartTime: startTime, Query: query, SignMerged: true})
+	req = httptest.NewRequest(http.MethodGet, presignedURL.String(), nil)
+	testVerifyErrorCode(t, VerifyRequest(req, testSecretLookup, now), "")
+
+	// Authorization 头部
+	h, _ := p.SignHeader(http.MethodPut, "test.txt", &PresignOptions{StartTime: startTime, Header: header})
+	req = httptest.NewRequest(http.MethodPut, u.String()+"/test.txt", nil)
+	req.Header = h
+	testVerifyErrorCode(t, VerifyRequest(req, testSecretLookup, now), "")
+
+	// 篡改签名头部
+	req.Header.Set("Content-Type", "application/json")
+	err = VerifyRequest(req, testSecretLookup, now)
+	testVerifyErrorCode(t, err, VerifyErrSignatureDoesNotMatch)
+	if err.(*SignatureVerifyError).StringToSign == "" {
+		t.Errorf("SignatureVerifyError.StringToSign is empty")
+	}
+
+	// 缺少签名头部
+	req.Header.Del("Content-Type")
+	testVerifyErrorCode(t, VerifyRequest(req, testSecretLookup, now), VerifyErrSignatureDoesNotMatch)
+
+	// 时间窗口
+	req.Header = h
+	req.Header.Set("Content-Type", "text/plain")
+	testVerifyErrorCode(t, VerifyRequest(req, testSecretLookup, &VerifyOptions{Now: startTime.Add(2 * time.Hour)}), VerifyErrRequestExpired)
+	testVerifyErrorCode(t, VerifyRequest(req, testSecretLookup, &VerifyOptions{Now: startTime.Add(-time.Minute)}), VerifyErrRequestNotYetValid)
+	testVerifyErrorCode(t, VerifyRequest(req, testSecretLookup, &VerifyOptions{Now: startTime.Add(-time.Minute), ClockSkew: 2 * time.Minute}), "")
+
+	// 未知的 SecretId
+	testVerifyErrorCode(t, VerifyRequest(req, func(string) (string, error) { return "", nil }, now), VerifyErrInvalidAccessKeyId)
+
+	// 签名格式错误
+	req.Header.Set("Authorization", "q-sign-algorithm=sha1&q-ak")
+	testVerifyErrorCode(t, VerifyRequest(req, testSecretLookup, now), VerifyErrInvalidAuthorization)
+	req.Header.Del("Authorization")
+	testVerifyErrorCode(t, VerifyRequest(req, testSecretLookup, now), VerifyErrMissingAuthorization)
+}
@@ -0,0 +1,517 @@
+package cos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// BucketConfig 以声明式文档描述存储桶的各项配置，字段为 nil 时表示不管理该项配置。
+// 文档为 JSON 或 YAML 格式，各项配置的内容与对应的 Put 接口参数相同。
+// 需要删除某项配置时，将其设置为空对象，如 "cors": {}；Versioning、Logging 与 Accelerate 不支持删除，
+// 设置为空对象时 PlanConfig 返回错误，需写出完整的配置(如 Status 为 Suspended)。
+// Inventory 与 IntelligentTiering 以 ID 为键，设置后不在其中的 ID 会被删除
+type BucketConfig struct {
+	Lifecycle          *BucketPutLifecycleOptions                     `json:"lifecycle,omitempty"`
+	CORS               *BucketPutCORSOptions                          `json:"cors,omitempty"`
+	Versioning         *BucketPutVersionOptions                       `json:"versioning,omitempty"`
+	Replication        *PutBucketReplicationOptions                   `json:"replication,omitempty"`
+	Inventory          map[string]*BucketPutInventoryOptions          `json:"inventory,omitempty"`
+	Logging            *BucketPutLoggingOptions                       `json:"logging,omitempty"`
+	Tagging            *BucketPutTaggingOptions                       `json:"tagging,omitempty"`
+	Referer            *BucketPutRefererOptions                       `json:"referer,omitempty"`
+	Website            *BucketPutWebsiteOptions                       `json:"website,omitempty"`
+	Encryption         *BucketPutEncryptionOptions                    `json:"encryption,omitempty"`
+	Accelerate         *BucketPutAccelerateOptions                    `json:"accelerate,omitempty"`
+	IntelligentTiering map[string]*BucketPutIntelligentTieringOptions `json:"intelligentTiering,omitempty"`
+	Origin             *BucketPutOriginOptions                        `json:"origin,omitempty"`
+	Domain             *BucketPutDomainOptions                        `json:"domain,omitempty"`
+}
+
+// ParseBucketConfig 解析存储桶配置文档，以 { 开头的文档按 JSON 解析，否则按 YAML 解析。
+// YAML 只支持常用的子集(块状的映射与序列、单行的 [] 与 {}、引号字符串与注释)，键与 JSON 文档相同
+func ParseBucketConfig(data []byte) (*BucketConfig, error) {
+	if trimmed := strings.TrimSpace(string(data)); !strings.HasPrefix(trimmed, "{") {
+		v, err := parseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	var cfg BucketConfig
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// 配置变更的类型
+const (
+	BucketConfigCreate = "create"
+	BucketConfigUpdate = "update"
+	BucketConfigDelete = "delete"
+)
+
+// BucketConfigDiff 一个字段的差异，Path 如 Rules[0].Expiration.Days
+type BucketConfigDiff struct {
+	Path    string      `json:"path"`
+	Current interface{} `json:"current,omitempty"`
+	Desired interface{} `json:"desired,omitempty"`
+}
+
+// BucketConfigChange 一项配置的变更
+type BucketConfigChange struct {
+	// 配置项，如 lifecycle、inventory/<id>
+	Section string             `json:"section"`
+	Action  string             `json:"action"`
+	Diffs   []BucketConfigDiff `json:"diffs,omitempty"`
+
+	apply func(ctx context.Context) error
+}
+
+// BucketConfigPlan 是 PlanConfig 的结果，只包含需要变更的配置项
+type BucketConfigPlan struct {
+	Changes []BucketConfigChange `json:"changes"`
+}
+
+// HasChanges 当前配置与期望配置是否不一致(存在漂移)
+func (p *BucketConfigPlan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// bucketConfigSection 一项受管理的配置
+type bucketConfigSection struct {
+	name    string
+	desired interface{}
+	get     func(ctx context.Context) (interface{}, error)
+	put     func(ctx context.Context) error
+	// 不支持删除的配置为 nil，期望配置为空时返回错误
+	del func(ctx context.Context) error
+}
+
+// ignoreNotFound 配置不存在时返回 nil
+func ignoreNotFound(v interface{}, err error) (interface{}, error) {
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *BucketService) configSections(ctx context.Context, cfg *BucketConfig) ([]bucketConfigSection, error) {
+	var sections []bucketConfigSection
+	if cfg.Lifecycle != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "lifecycle",
+			desired: cfg.Lifecycle,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetLifecycle(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutLifecycle(ctx, cfg.Lifecycle); return err },
+			del: func(ctx context.Context) error { _, err := s.DeleteLifecycle(ctx); return err },
+		})
+	}
+	if cfg.CORS != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "cors",
+			desired: cfg.CORS,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetCORS(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutCORS(ctx, cfg.CORS); return err },
+			del: func(ctx context.Context) error { _, err := s.DeleteCORS(ctx); return err },
+		})
+	}
+	if cfg.Versioning != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "versioning",
+			desired: cfg.Versioning,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetVersioning(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutVersioning(ctx, cfg.Versioning); return err },
+		})
+	}
+	if cfg.Replication != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "replication",
+			desired: cfg.Replication,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetBucketReplication(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutBucketReplication(ctx, cfg.Replication); return err },
+			del: func(ctx context.Context) error { _, err := s.DeleteBucketReplication(ctx); return err },
+		})
+	}
+	if cfg.Logging != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "logging",
+			desired: cfg.Logging,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetLogging(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutLogging(ctx, cfg.Logging); return err },
+		})
+	}
+	if cfg.Tagging != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "tagging",
+			desired: cfg.Tagging,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetTagging(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutTagging(ctx, cfg.Tagging); return err },
+			del: func(ctx context.Context) error { _, err := s.DeleteTagging(ctx); return err },
+		})
+	}
+	if cfg.Referer != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "referer",
+			desired: cfg.Referer,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetReferer(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutReferer(ctx, cfg.Referer); return err },
+			del: func(ctx context.Context) error { _, err := s.DeleteReferer(ctx); return err },
+		})
+	}
+	if cfg.Website != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "website",
+			desired: cfg.Website,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetWebsite(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutWebsite(ctx, cfg.Website); return err },
+			del: func(ctx context.Context) error { _, err := s.DeleteWebsite(ctx); return err },
+		})
+	}
+	if cfg.Encryption != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "encryption",
+			desired: cfg.Encryption,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetEncryption(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutEncryption(ctx, cfg.Encryption); return err },
+			del: func(ctx context.Context) error { _, err := s.DeleteEncryption(ctx); return err },
+		})
+	}
+	if cfg.Accelerate != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "accelerate",
+			desired: cfg.Accelerate,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetAccelerate(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutAccelerate(ctx, cfg.Accelerate); return err },
+		})
+	}
+	if cfg.Origin != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "origin",
+			desired: cfg.Origin,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetOrigin(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutOrigin(ctx, cfg.Origin); return err },
+			del: func(ctx context.Context) error { _, err := s.DeleteOrigin(ctx); return err },
+		})
+	}
+	if cfg.Domain != nil {
+		sections = append(sections, bucketConfigSection{
+			name:    "domain",
+			desired: cfg.Domain,
+			get: func(ctx context.Context) (interface{}, error) {
+				res, _, err := s.GetDomain(ctx)
+				return ignoreNotFound(res, err)
+			},
+			put: func(ctx context.Context) error { _, err := s.PutDomain(ctx, cfg.Domain); return err },
+			del: func(ctx context.Context) error { _, err := s.DeleteDomain(ctx); return err },
+		})
+	}
+
+	if cfg.Inventory != nil {
+		current := map[string]interface{}{}
+		token := ""
+		for {
+			res, _, err := s.ListInventoryConfigurations(ctx, token)
+			if err != nil && !IsNotFoundError(err) {
+				return nil, err
+			}
+			if err != nil {
+				break
+			}
+			for i := range res.InventoryConfigurations {
+				current[res.InventoryConfigurations[i].ID] = &res.InventoryConfigurations[i]
+			}
+			if !res.IsTruncated {
+				break
+			}
+			token = res.NextContinuationToken
+		}
+		for _, id := range unionKeys(current, cfg.Inventory) {
+			id, desired := id, cfg.Inventory[id]
+			section := bucketConfigSection{
+				name: "inventory/" + id,
+				get: func(ctx context.Context) (interface{}, error) {
+					return current[id], nil
+				},
+				del: func(ctx context.Context) error { _, err := s.DeleteInventory(ctx, id); return err },
+			}
+			if desired != nil {
+				section.desired = desired
+				section.put = func(ctx context.Context) error { _, err := s.PutInventory(ctx, id, desired); return err }
+			}
+			sections = append(sections, section)
+		}
+	}
+	if cfg.IntelligentTiering != nil {
+		current := map[string]interface{}{}
+		res, _, err := s.ListIntelligentTiering(ctx)
+		if err != nil && !IsNotFoundError(err) {
+			return nil, err
+		}
+		if err == nil {
+			for _, c := range res.Configurations {
+				current[c.Id] = c
+			}
+		}
+		for _, id := range unionKeys(current, cfg.IntelligentTiering) {
+			id, desired := id, cfg.IntelligentTiering[id]
+			section := bucketConfigSection{
+				name: "intelligentTiering/" + id,
+				get: func(ctx context.Context) (interface{}, error) {
+					return current[id], nil
+				},
+				del: func(ctx context.Context) error { _, err := s.DeleteIntelligentTiering(ctx, id); return err },
+			}
+			if desired != nil {
+				if desired.Id == "" {
+					desired.Id = id
+				}
+				section.desired = desired
+				section.put = func(ctx context.Context) error { _, err := s.PutIntelligentTieringV2(ctx, desired); return err }
+			}
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+// unionKeys 返回两个 map 的键的并集，按字典序排列
+func unionKeys(a map[string]interface{}, b interface{}) []string {
+	set := map[string]bool{}
+	for k := range a {
+		set[k] = true
+	}
+	for _, k := range reflect.ValueOf(b).MapKeys() {
+		set[k.String()] = true
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PlanConfig 获取存储桶当前的配置并与 cfg 比较，返回需要执行的变更
+func (s *BucketService) PlanConfig(ctx context.Context, cfg *BucketConfig) (*BucketConfigPlan, error) {
+	plan := &BucketConfigPlan{Changes: []BucketConfigChange{}}
+	if cfg == nil {
+		return plan, nil
+	}
+	sections, err := s.configSections(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, section := range sections {
+		current, err := section.get(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get %v failed: %v", section.name, err)
+		}
+		cur, err := normalizeBucketConfig(current)
+		if err != nil {
+			return nil, err
+		}
+		desired, err := normalizeBucketConfig(section.desired)
+		if err != nil {
+			return nil, err
+		}
+
+		change := BucketConfigChange{Section: section.name}
+		switch {
+		case desired == nil && section.del == nil:
+			return nil, fmt.Errorf("%v can not be deleted, desired config is empty", section.name)
+		case desired == nil && section.del != nil:
+			if cur == nil {
+				continue
+			}
+			change.Action = BucketConfigDelete
+			change.apply = section.del
+		case cur == nil:
+			change.Action = BucketConfigCreate
+			change.apply = section.put
+		default:
+			change.Action = BucketConfigUpdate
+			change.apply = section.put
+		}
+		diffBucketConfig("", cur, desired, &change.Diffs)
+		if len(change.Diffs) == 0 && change.Action == BucketConfigUpdate {
+			continue
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	return plan, nil
+}
+
+// ApplyConfig 按 PlanConfig 的结果只调用需要的 Put/Delete 接口，返回已执行的变更；
+// 出错时停止执行，返回的 plan 中包含出错前已执行的变更
+func (s *BucketService) ApplyConfig(ctx context.Context, cfg *BucketConfig) (*BucketConfigPlan, error) {
+	plan, err := s.PlanConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return s.Apply(ctx, plan)
+}
+
+// Apply 执行 PlanConfig 返回并经过确认的 plan，不重新比较配置，返回值与 ApplyConfig 相同。
+// plan 必须由 PlanConfig 生成，从 JSON 解码得到的 plan 不能执行
+func (s *BucketService) Apply(ctx context.Context, plan *BucketConfigPlan) (*BucketConfigPlan, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("plan is nil")
+	}
+	for _, change := range plan.Changes {
+		if change.apply == nil {
+			return nil, fmt.Errorf("%v %v is not created by PlanConfig", change.Action, change.Section)
+		}
+	}
+	applied := &BucketConfigPlan{Changes: []BucketConfigChange{}}
+	for _, change := range plan.Changes {
+		if err := change.apply(ctx); err != nil {
+			return applied, fmt.Errorf("%v %v failed: %v", change.Action, change.Section, err)
+		}
+		applied.Changes = append(applied.Changes, change)
+	}
+	return applied, nil
+}
+
+// normalizeBucketConfig 将配置转换为 JSON 的通用表示，去掉 XMLName 与零值字段，便于比较 Put 参数与 Get 结果
+func normalizeBucketConfig(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	if err := json.Unmarshal(bs, &res); err != nil {
+		return nil, err
+	}
+	return pruneBucketConfig(res), nil
+}
+
+func pruneBucketConfig(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if k == "XMLName" || k == "XOptionHeader" {
+				delete(v, k)
+				continue
+			}
+			if e = pruneBucketConfig(e); e == nil {
+				delete(v, k)
+			} else {
+				v[k] = e
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for i := range v {
+			v[i] = pruneBucketConfig(v[i])
+		}
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+	case float64:
+		if v == 0 {
+			return nil
+		}
+	case bool:
+		if !v {
+			return nil
+		}
+	}
+	return v
+}
+
+func diffBucketConfig(path string, cur, desired interface{}, diffs *[]BucketConfigDiff) {
+	cm, cok := cur.(map[string]interface{})
+	dm, dok := desired.(map[string]interface{})
+	if cok && dok {
+		keys := map[string]bool{}
+		for k := range cm {
+			keys[k] = true
+		}
+		for k := range dm {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			diffBucketConfig(p, cm[k], dm[k], diffs)
+		}
+		return
+	}
+	ca, cok := cur.([]interface{})
+	da, dok := desired.([]interface{})
+	if cok && dok && len(ca) == len(da) {
+		for i := range ca {
+			diffBucketConfig(fmt.Sprintf("%v[%d]", path, i), ca[i], da[i], diffs)
+		}
+		return
+	}
+	if !reflect.DeepEqual(cur, desired) {
+		*diffs = append(*diffs, BucketConfigDiff{Path: path, Current: cur, Desired: desired})
+	}
+}
@@ -0,0 +1,208 @@
+package cos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestParseBucketConfig(t *testing.T) {
+	cfg, err := ParseBucketConfig([]byte(`{
+	"versioning": {"Status": "Enabled"},
+	"cors": {"Rules": [{"AllowedOrigins": ["https://example.com"], "AllowedMethods": ["GET"]}]},
+	"inventory": {"list1": null}
+}`))
+	if err != nil {
+		t.Fatalf("ParseBucketConfig returned error: %v", err)
+	}
+	if cfg.Versioning.Status != "Enabled" || len(cfg.CORS.Rules) != 1 || cfg.Lifecycle != nil {
+		t.Errorf("ParseBucketConfig returned %+v", cfg)
+	}
+	if _, ok := cfg.Inventory["list1"]; !ok {
+		t.Errorf("ParseBucketConfig lost inventory list1")
+	}
+	if _, err := ParseBucketConfig([]byte(`{"unknown": {}}`)); err == nil {
+		t.Errorf("ParseBucketConfig should reject unknown section")
+	}
+
+	cfg, err = ParseBucketConfig([]byte(`
+# 与上面的 JSON 相同
+versioning:
+  Status: Enabled
+cors:
+  Rules:
+  - AllowedOrigins: ["https://example.com"]
+    AllowedMethods:
+      - GET
+    MaxAgeSeconds: 600
+inventory:
+  list1: ~
+tagging: {}
+`))
+	if err != nil {
+		t.Fatalf("ParseBucketConfig returned error: %v", err)
+	}
+	if cfg.Versioning.Status != "Enabled" || len(cfg.CORS.Rules) != 1 || cfg.Lifecycle != nil {
+		t.Errorf("ParseBucketConfig returned %+v", cfg)
+	}
+	rule := cfg.CORS.Rules[0]
+	if !reflect.DeepEqual(rule.AllowedOrigins, []string{"https://example.com"}) ||
+		!reflect.DeepEqual(rule.AllowedMethods, []string{"GET"}) || rule.MaxAgeSeconds != 600 {
+		t.Errorf("ParseBucketConfig returned cors rule %+v", rule)
+	}
+	if _, ok := cfg.Inventory["list1"]; !ok {
+		t.Errorf("ParseBucketConfig lost inventory list1")
+	}
+	if cfg.Tagging == nil || len(cfg.Tagging.TagSet) != 0 {
+		t.Errorf("ParseBucketConfig returned tagging %+v", cfg.Tagging)
+	}
+	if _, err := ParseBucketConfig([]byte("unknown:\n  a: 1\n")); err == nil {
+		t.Errorf("ParseBucketConfig should reject unknown section in yaml")
+	}
+}
+
+func TestBucketService_PlanApplyConfig(t *testing.T) {
+	setup()
+	defer teardown()
+
+	has := func(q url.Values, k string) bool {
+		_, ok := q[k]
+		return ok
+	}
+	var mu sync.Mutex
+	var calls []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.Method != http.MethodGet {
+			mu.Lock()
+			defer mu.Unlock()
+			for k := range q {
+				if k == "id" {
+					continue
+				}
+				calls = append(calls, r.Method+" "+k+" "+q.Get("id"))
+			}
+			return
+		}
+		switch {
+		case has(q, "cors"):
+			fmt.Fprint(w, `<CORSConfiguration>
+	<CORSRule><AllowedOrigin>https://example.com</AllowedOrigin><AllowedMethod>GET</AllowedMethod></CORSRule>
+</CORSConfiguration>`)
+		case has(q, "versioning"):
+			fmt.Fprint(w, `<VersioningConfiguration><Status>Suspended</Status></VersioningConfiguration>`)
+		case has(q, "tagging"):
+			fmt.Fprint(w, `<Tagging><TagSet><Tag><Key>env</Key><Value>test</Value></Tag></TagSet></Tagging>`)
+		case has(q, "inventory"):
+			fmt.Fprint(w, `<ListInventoryConfigurationResult>
+	<InventoryConfiguration><Id>old</Id><IsEnabled>true</IsEnabled></InventoryConfiguration>
+</ListInventoryConfigurationResult>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchLifecycleConfiguration</Code></Error>`)
+		}
+	})
+
+	cfg := &BucketConfig{
+		Lifecycle: &BucketPutLifecycleOptions{
+			Rules: []BucketLifecycleRule{{ID: "r1", Status: "Enabled", Filter: &BucketLifecycleFilter{Prefix: "log/"}}},
+		},
+		CORS: &BucketPutCORSOptions{
+			Rules: []BucketCORSRule{{AllowedOrigins: []string{"https://example.com"}, AllowedMethods: []string{"GET"}}},
+		},
+		Versioning: &BucketPutVersionOptions{Status: "Enabled"},
+		Tagging:    &BucketPutTaggingOptions{},
+		Inventory:  map[string]*BucketPutInventoryOptions{},
+	}
+	plan, err := client.Bucket.PlanConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Bucket.PlanConfig returned error: %v", err)
+	}
+	got := map[string]string{}
+	for _, c := range plan.Changes {
+		got[c.Section] = c.Action
+	}
+	want := map[string]string{
+		"lifecycle":     BucketConfigCreate,
+		"versioning":    BucketConfigUpdate,
+		"tagging":       BucketConfigDelete,
+		"inventory/old": BucketConfigDelete,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Bucket.PlanConfig returned %v, want %v", got, want)
+	}
+	for _, c := range plan.Changes {
+		if c.Section == "versioning" {
+			diffs := []BucketConfigDiff{{Path: "Status", Current: "Suspended", Desired: "Enabled"}}
+			if !reflect.DeepEqual(c.Diffs, diffs) {
+				t.Errorf("versioning diffs is %+v, want %+v", c.Diffs, diffs)
+			}
+		}
+	}
+	if calls != nil {
+		t.Errorf("Bucket.PlanConfig should not modify the bucket: %v", calls)
+	}
+
+	wantCalls := []string{
+		"DELETE inventory old",
+		"DELETE tagging ",
+		"PUT lifecycle ",
+		"PUT versioning ",
+	}
+	applied, err := client.Bucket.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Bucket.Apply returned error: %v", err)
+	}
+	if len(applied.Changes) != 4 {
+		t.Errorf("Bucket.Apply applied %v changes, want 4", len(applied.Changes))
+	}
+	sort.Strings(calls)
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("Bucket.Apply calls %v, want %v", calls, wantCalls)
+	}
+
+	calls = nil
+	applied, err = client.Bucket.ApplyConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Bucket.ApplyConfig returned error: %v", err)
+	}
+	if len(applied.Changes) != 4 {
+		t.Errorf("Bucket.ApplyConfig applied %v changes, want 4", len(applied.Changes))
+	}
+	sort.Strings(calls)
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("Bucket.ApplyConfig calls %v, want %v", calls, wantCalls)
+	}
+
+	// 从 JSON 解码的 plan 不能执行
+	calls = nil
+	var decoded BucketConfigPlan
+	b, _ := json.Marshal(plan)
+	json.Unmarshal(b, &decoded)
+	if _, err = client.Bucket.Apply(context.Background(), &decoded); err == nil {
+		t.Errorf("Bucket.Apply should reject a decoded plan")
+	}
+	if calls != nil {
+		t.Errorf("Bucket.Apply with a decoded plan calls %v", calls)
+	}
+
+	// 不支持删除的配置不能设置为空对象
+	for _, data := range []string{`{"logging": {}}`, `{"versioning": {}}`, `{"accelerate": {}}`} {
+		cfg, err := ParseBucketConfig([]byte(data))
+		if err != nil {
+			t.Fatalf("ParseBucketConfig returned error: %v", err)
+		}
+		if _, err = client.Bucket.PlanConfig(context.Background(), cfg); err == nil {
+			t.Errorf("Bucket.PlanConfig should reject the empty config %v", data)
+		}
+	}
+	if calls != nil {
+		t.Errorf("Bucket.PlanConfig with an empty config calls %v", calls)
+	}
+}
@@ -0,0 +1,363 @@
+package cos
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlLine 去掉注释与缩进后的一行 YAML
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseYAML 将 YAML 文档解析为与 encoding/json 相同的通用表示(map[string]interface{}、[]interface{}、string、
+// json.Number、bool 与 nil)。只支持配置文档常用的子集：块状的映射与序列、单行的流式 [] 与 {}、单双引号字符串与注释，
+// 不支持锚点、标签、多行字符串与多文档
+func parseYAML(data []byte) (interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n") {
+		text := strings.TrimRight(stripYAMLComment(raw), " \t")
+		trimmed := strings.TrimLeft(text, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if strings.Contains(text[:len(text)-len(trimmed)], "\t") {
+			return nil, fmt.Errorf("yaml: line %d: tabs are not allowed in indentation", i+1)
+		}
+		if trimmed == "---" && len(text) == 3 {
+			if len(lines) > 0 {
+				return nil, fmt.Errorf("yaml: line %d: multiple documents are not supported", i+1)
+			}
+			continue
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	p := &yamlParser{lines: lines}
+	v, err := p.parseBlock(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, p.errorf(p.lines[p.pos], "unexpected indentation")
+	}
+	return v, nil
+}
+
+func (p *yamlParser) errorf(l yamlLine, format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %v", l.num, fmt.Sprintf(format, args...))
+}
+
+// parseBlock 解析从当前行开始、缩进为 indent 的映射、序列或单独一行的值
+func (p *yamlParser) parseBlock(indent int) (interface{}, error) {
+	l := p.lines[p.pos]
+	if isYAMLSeqItem(l.text) {
+		return p.parseSeq(indent)
+	}
+	if _, _, ok := splitYAMLKey(l.text); ok {
+		return p.parseMap(indent)
+	}
+	p.pos++
+	return parseYAMLValue(l)
+}
+
+func (p *yamlParser) parseSeq(indent int) (interface{}, error) {
+	seq := []interface{}{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent || l.indent == indent && !isYAMLSeqItem(l.text) {
+			break
+		}
+		if l.indent > indent {
+			return nil, p.errorf(l, "unexpected indentation")
+		}
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if rest == "" {
+			p.pos++
+			var v interface{}
+			if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+				var err error
+				if v, err = p.parseBlock(p.lines[p.pos].indent); err != nil {
+					return nil, err
+				}
+			}
+			seq = append(seq, v)
+			continue
+		}
+		// "- key: value" 的内容视为缩进到 "- " 之后的一行，之后同样缩进的行属于同一个元素
+		p.lines[p.pos] = yamlLine{num: l.num, indent: l.indent + len(l.text) - len(rest), text: rest}
+		v, err := p.parseBlock(p.lines[p.pos].indent)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+	}
+	return seq, nil
+}
+
+func (p *yamlParser) parseMap(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, p.errorf(l, "unexpected indentation")
+		}
+		key, value, ok := splitYAMLKey(l.text)
+		if !ok {
+			return nil, p.errorf(l, "expected a mapping key")
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf(l, "duplicate key %q", key)
+		}
+		p.pos++
+		if value != "" {
+			v, err := parseYAMLValue(yamlLine{num: l.num, text: value})
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			continue
+		}
+		var v interface{}
+		if p.pos < len(p.lines) {
+			// 值为下一层缩进的块，序列可以与键缩进相同
+			next := p.lines[p.pos]
+			if next.indent > indent || next.indent == indent && isYAMLSeqItem(next.text) {
+				var err error
+				if v, err = p.parseBlock(next.indent); err != nil {
+					return nil, err
+				}
+			}
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// stripYAMLComment 去掉引号之外、以空白或行首开始的 # 注释
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// splitYAMLKey 将 "key: value" 或 "key:" 拆分为键与值
+func splitYAMLKey(text string) (string, string, bool) {
+	if text == "" || text[0] == '[' || text[0] == '{' || isYAMLSeqItem(text) {
+		return "", "", false
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		f := &yamlFlow{s: text}
+		key, err := f.parseQuoted()
+		if err != nil || f.i >= len(text) || text[f.i] != ':' || f.i+1 < len(text) && text[f.i+1] != ' ' {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(text[f.i+1:]), true
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func parseYAMLValue(l yamlLine) (interface{}, error) {
+	text := l.text
+	switch text[0] {
+	case '|', '>':
+		return nil, fmt.Errorf("yaml: line %d: block scalars are not supported", l.num)
+	case '&', '*', '!':
+		return nil, fmt.Errorf("yaml: line %d: anchors, aliases and tags are not supported", l.num)
+	case '[', '{', '"', '\'':
+		f := &yamlFlow{s: text}
+		v, err := f.parseValue(false)
+		if err == nil && f.skipSpace() < len(text) {
+			err = fmt.Errorf("unexpected %q", text[f.i:])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("yaml: line %d: %v", l.num, err)
+		}
+		return v, nil
+	}
+	return yamlScalar(text), nil
+}
+
+var yamlNumberRegexp = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// yamlScalar 转换不带引号的标量
+func yamlScalar(s string) interface{} {
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if yamlNumberRegexp.MatchString(s) {
+		return json.Number(s)
+	}
+	return s
+}
+
+// yamlFlow 解析单行的流式集合与引号字符串
+type yamlFlow struct {
+	s string
+	i int
+}
+
+func (f *yamlFlow) skipSpace() int {
+	for f.i < len(f.s) && f.s[f.i] == ' ' {
+		f.i++
+	}
+	return f.i
+}
+
+// parseValue inFlow 为 true 时，不带引号的标量在 , ] } 处结束
+func (f *yamlFlow) parseValue(inFlow bool) (interface{}, error) {
+	if f.skipSpace() >= len(f.s) {
+		return nil, nil
+	}
+	switch f.s[f.i] {
+	case '[':
+		f.i++
+		seq := []interface{}{}
+		if f.skipSpace() < len(f.s) && f.s[f.i] == ']' {
+			f.i++
+			return seq, nil
+		}
+		for {
+			v, err := f.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			if err = f.expect(",]"); err != nil {
+				return nil, err
+			}
+			if f.s[f.i-1] == ']' {
+				return seq, nil
+			}
+		}
+	case '{':
+		f.i++
+		m := map[string]interface{}{}
+		if f.skipSpace() < len(f.s) && f.s[f.i] == '}' {
+			f.i++
+			return m, nil
+		}
+		for {
+			f.skipSpace()
+			var key string
+			if f.i < len(f.s) && (f.s[f.i] == '"' || f.s[f.i] == '\'') {
+				k, err := f.parseQuoted()
+				if err != nil {
+					return nil, err
+				}
+				key = k
+			} else {
+				start := f.i
+				for f.i < len(f.s) && f.s[f.i] != ':' && f.s[f.i] != ',' && f.s[f.i] != '}' {
+					f.i++
+				}
+				key = strings.TrimSpace(f.s[start:f.i])
+			}
+			if err := f.expect(":"); err != nil {
+				return nil, err
+			}
+			v, err := f.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := m[key]; dup {
+				return nil, fmt.Errorf("duplicate key %q", key)
+			}
+			m[key] = v
+			if err = f.expect(",}"); err != nil {
+				return nil, err
+			}
+			if f.s[f.i-1] == '}' {
+				return m, nil
+			}
+		}
+	case '"', '\'':
+		return f.parseQuoted()
+	}
+	start := f.i
+	for f.i < len(f.s) && (!inFlow || strings.IndexByte(",]}", f.s[f.i]) < 0) {
+		f.i++
+	}
+	return yamlScalar(strings.TrimSpace(f.s[start:f.i])), nil
+}
+
+// expect 跳过空白后读取 chars 中的一个字符
+func (f *yamlFlow) expect(chars string) error {
+	if f.skipSpace() >= len(f.s) {
+		return fmt.Errorf("unexpected end of line, want one of %q", chars)
+	}
+	if strings.IndexByte(chars, f.s[f.i]) < 0 {
+		return fmt.Errorf("unexpected %q, want one of %q", f.s[f.i], chars)
+	}
+	f.i++
+	return nil
+}
+
+// parseQuoted 解析单引号(连续两个单引号表示一个单引号)或双引号(支持 \ 转义)字符串
+func (f *yamlFlow) parseQuoted() (string, error) {
+	quote := f.s[f.i]
+	for j := f.i + 1; j < len(f.s); j++ {
+		switch {
+		case quote == '"' && f.s[j] == '\\':
+			j++
+		case f.s[j] != quote:
+		case quote == '\'' && j+1 < len(f.s) && f.s[j+1] == '\'':
+			j++
+		case quote == '\'':
+			v := strings.Replace(f.s[f.i+1:j], "''", "'", -1)
+			f.i = j + 1
+			return v, nil
+		default:
+			v, err := strconv.Unquote(f.s[f.i : j+1])
+			if err != nil {
+				return "", fmt.Errorf("invalid string %v: %v", f.s[f.i:j+1], err)
+			}
+			f.i = j + 1
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("unterminated string %v", f.s[f.i:])
+}
@@ -0,0 +1,69 @@
+package cos
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_parseYAML(t *testing.T) {
+	doc := `---
+name: 'it''s' # comment
+url: http://example.com/a#b
+quoted: "a: b # c\n"
+empty:
+nums: [1, -2.5, "3", 0x10]
+flags: {on: true, off: FALSE, none: null}
+list:
+- a
+-
+  - b
+  - c
+- k1: v1
+  k2: [ ]
+"key: x": 1
+nested:
+    deep:
+      - x: {}
+`
+	v, err := parseYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("parseYAML returned error: %v", err)
+	}
+	want := map[string]interface{}{
+		"name":   "it's",
+		"url":    "http://example.com/a#b",
+		"quoted": "a: b # c\n",
+		"empty":  nil,
+		"nums":   []interface{}{json.Number("1"), json.Number("-2.5"), "3", "0x10"},
+		"flags":  map[string]interface{}{"on": true, "off": false, "none": nil},
+		"list": []interface{}{
+			"a",
+			[]interface{}{"b", "c"},
+			map[string]interface{}{"k1": "v1", "k2": []interface{}{}},
+		},
+		"key: x": json.Number("1"),
+		"nested": map[string]interface{}{
+			"deep": []interface{}{map[string]interface{}{"x": map[string]interface{}{}}},
+		},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("parseYAML returned %#v, want %#v", v, want)
+	}
+
+	for _, doc := range []string{
+		"a: 1\n  b: 2\n",
+		"a:\n\tb: 1\n",
+		"a: 1\na: 2\n",
+		"a: [1, 2\n",
+		"a: \"x\n",
+		"a: |\n  text\n",
+		"a: &x 1\n",
+		"- a\nb: 1\n",
+		"a: 1\n---\nb: 2\n",
+	} {
+		if _, err := parseYAML([]byte(doc)); err == nil {
+			t.Errorf("parseYAML(%q) should return error", doc)
+		}
+	}
+}
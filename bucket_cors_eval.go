@@ -0,0 +1,280 @@
+package cos
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSEvaluation 是 EvaluateCORS 的结果
+type CORSEvaluation struct {
+	Allowed bool
+	// 匹配的规则及其在配置中的下标，未匹配时为 nil 与 -1
+	Rule      *BucketCORSRule
+	RuleIndex int
+	// 预请求响应中的 Access-Control-* 与 Vary 头部
+	Header http.Header
+	// 未匹配时的原因
+	Reason string
+}
+
+// EvaluateCORS 在本地按 CORS 配置对预请求(Origin、Access-Control-Request-Method、Access-Control-Request-Headers)求值，
+// 返回第一条匹配的规则与 COS 应答的 Access-Control-* 头部，可用于在不发送 ObjectService.Options 的情况下调试跨域配置
+func EvaluateCORS(cors *BucketGetCORSResult, req *ObjectOptionsOptions) *CORSEvaluation {
+	res := &CORSEvaluation{RuleIndex: -1, Header: http.Header{}}
+	if cors == nil || len(cors.Rules) == 0 {
+		res.Reason = "CORS is not configured"
+		return res
+	}
+	if req == nil || req.Origin == "" || req.AccessControlRequestMethod == "" {
+		res.Reason = "Origin and Access-Control-Request-Method are required"
+		return res
+	}
+	headers := splitCORSHeaders(req.AccessControlRequestHeaders)
+	if strings.EqualFold(cors.ResponseVary, "true") {
+		res.Header.Set("Vary", "Origin,Access-Control-Request-Headers,Access-Control-Request-Method")
+	}
+
+	reason := "no rule allows origin " + req.Origin
+	for i := range cors.Rules {
+		rule := &cors.Rules[i]
+		origin, ok := matchCORSOrigin(rule.AllowedOrigins, req.Origin)
+		if !ok {
+			continue
+		}
+		if !containsCORSMethod(rule.AllowedMethods, req.AccessControlRequestMethod) {
+			reason = fmt.Sprintf("no rule allows method %v from origin %v", req.AccessControlRequestMethod, req.Origin)
+			continue
+		}
+		if h := uncoveredCORSHeader(rule.AllowedHeaders, headers); h != "" {
+			reason = fmt.Sprintf("no rule allows header %v from origin %v", h, req.Origin)
+			continue
+		}
+		res.Allowed, res.Rule, res.RuleIndex, res.Reason = true, rule, i, ""
+		if origin == "*" {
+			res.Header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			res.Header.Set("Access-Control-Allow-Origin", req.Origin)
+		}
+		res.Header.Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethods, ","))
+		if len(headers) > 0 {
+			res.Header.Set("Access-Control-Allow-Headers", strings.Join(headers, ","))
+		}
+		if len(rule.ExposeHeaders) > 0 {
+			res.Header.Set("Access-Control-Expose-Headers", strings.Join(rule.ExposeHeaders, ","))
+		}
+		if rule.MaxAgeSeconds > 0 {
+			res.Header.Set("Access-Control-Max-Age", strconv.Itoa(rule.MaxAgeSeconds))
+		}
+		return res
+	}
+	res.Reason = reason
+	return res
+}
+
+func splitCORSHeaders(v string) []string {
+	var headers []string
+	for _, h := range strings.Split(v, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+// matchCORSOrigin 返回匹配的 AllowedOrigin，AllowedOrigin 可以包含一个 * 通配
+func matchCORSOrigin(origins []string, origin string) (string, bool) {
+	origin = strings.ToLower(origin)
+	for _, o := range origins {
+		if o == "*" || wildcardMatch(strings.ToLower(o), origin) {
+			return o, true
+		}
+	}
+	return "", false
+}
+
+func containsCORSMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// uncoveredCORSHeader 返回第一个不被 AllowedHeader 允许的请求头部，头部不区分大小写
+func uncoveredCORSHeader(allowed, headers []string) string {
+	for _, h := range headers {
+		ok := false
+		for _, a := range allowed {
+			if a == "*" || wildcardMatch(strings.ToLower(a), strings.ToLower(h)) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return h
+		}
+	}
+	return ""
+}
+
+// CORS 规则问题的类型
+const (
+	CORSIssueInvalid = "invalid"
+	// 被前面的规则完全覆盖，永远不会匹配
+	CORSIssueUnreachable = "unreachable"
+	// 与前面的规则部分重叠，重叠的请求按前面的规则应答
+	CORSIssueOverlap = "overlap"
+)
+
+// CORSIssue CORS 配置中的问题
+type CORSIssue struct {
+	Type      string
+	RuleIndex int
+	RuleID    string
+	// 覆盖或重叠的前面的规则，没有时为 -1
+	OtherIndex int
+	Message    string
+}
+
+var corsMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPut:    true,
+	http.MethodPost:   true,
+	http.MethodDelete: true,
+	http.MethodHead:   true,
+}
+
+// ValidateCORS 在 PutCORS 前检查 CORS 规则：缺少 AllowedOrigin 或 AllowedMethod、不支持的方法、
+// 包含多个 * 的 AllowedOrigin，以及被前面的规则完全覆盖(不可达)或部分重叠的规则
+func ValidateCORS(cors *BucketPutCORSOptions) []CORSIssue {
+	var issues []CORSIssue
+	if cors == nil {
+		return issues
+	}
+	for i := range cors.Rules {
+		rule := &cors.Rules[i]
+		add := func(typ string, other int, format string, args ...interface{}) {
+			issues = append(issues, CORSIssue{
+				Type:       typ,
+				RuleIndex:  i,
+				RuleID:     rule.ID,
+				OtherIndex: other,
+				Message:    fmt.Sprintf(format, args...),
+			})
+		}
+		if len(rule.AllowedOrigins) == 0 {
+			add(CORSIssueInvalid, -1, "AllowedOrigin is required")
+		}
+		if len(rule.AllowedMethods) == 0 {
+			add(CORSIssueInvalid, -1, "AllowedMethod is required")
+		}
+		for _, m := range rule.AllowedMethods {
+			if !corsMethods[m] {
+				add(CORSIssueInvalid, -1, "unsupported AllowedMethod %q", m)
+			}
+		}
+		for _, o := range rule.AllowedOrigins {
+			if strings.Count(o, "*") > 1 {
+				add(CORSIssueInvalid, -1, "AllowedOrigin %q contains more than one *", o)
+			}
+		}
+		if rule.MaxAgeSeconds < 0 {
+			add(CORSIssueInvalid, -1, "MaxAgeSeconds must not be negative")
+		}
+
+		for j := 0; j < i; j++ {
+			prev := &cors.Rules[j]
+			if corsRuleCovers(prev, rule) {
+				add(CORSIssueUnreachable, j, "rule %d is unreachable: every request it allows is matched by rule %d first", i, j)
+				break
+			}
+			if corsRulesOverlap(prev, rule) {
+				add(CORSIssueOverlap, j, "rule %d overlaps rule %d, overlapping requests are answered by rule %d", i, j, j)
+				break
+			}
+		}
+	}
+	return issues
+}
+
+// corsPatternCovers a 是否匹配 b 能匹配的所有值
+func corsPatternCovers(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	return a == "*" || a == b || wildcardMatch(a, b)
+}
+
+// corsPatternsOverlap a 与 b 是否可能匹配同一个值，a、b 最多包含一个 *
+func corsPatternsOverlap(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if wildcardMatch(a, b) || wildcardMatch(b, a) {
+		return true
+	}
+	ia, ib := strings.Index(a, "*"), strings.Index(b, "*")
+	if ia < 0 || ib < 0 {
+		return false
+	}
+	pa, sa := a[:ia], a[ia+1:]
+	pb, sb := b[:ib], b[ib+1:]
+	prefixOK := strings.HasPrefix(pa, pb) || strings.HasPrefix(pb, pa)
+	suffixOK := strings.HasSuffix(sa, sb) || strings.HasSuffix(sb, sa)
+	return prefixOK && suffixOK
+}
+
+// corsRuleCovers 预请求匹配 b 时是否一定先匹配 a
+func corsRuleCovers(a, b *BucketCORSRule) bool {
+	for _, ob := range b.AllowedOrigins {
+		covered := false
+		for _, oa := range a.AllowedOrigins {
+			if corsPatternCovers(oa, ob) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	for _, m := range b.AllowedMethods {
+		if !containsCORSMethod(a.AllowedMethods, m) {
+			return false
+		}
+	}
+	for _, hb := range b.AllowedHeaders {
+		covered := false
+		for _, ha := range a.AllowedHeaders {
+			if corsPatternCovers(ha, hb) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return len(b.AllowedOrigins) > 0 && len(b.AllowedMethods) > 0
+}
+
+// corsRulesOverlap a 与 b 是否有同一个 Origin 与方法都匹配的预请求
+func corsRulesOverlap(a, b *BucketCORSRule) bool {
+	method := false
+	for _, m := range b.AllowedMethods {
+		if containsCORSMethod(a.AllowedMethods, m) {
+			method = true
+			break
+		}
+	}
+	if !method {
+		return false
+	}
+	for _, ob := range b.AllowedOrigins {
+		for _, oa := range a.AllowedOrigins {
+			if corsPatternsOverlap(oa, ob) {
+				return true
+			}
+		}
+	}
+	return false
+}
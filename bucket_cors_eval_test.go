@@ -0,0 +1,94 @@
+package cos
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluateCORS(t *testing.T) {
+	cors := &BucketGetCORSResult{
+		Rules: []BucketCORSRule{
+			{
+				ID:             "app",
+				AllowedOrigins: []string{"https://*.example.com"},
+				AllowedMethods: []string{"GET", "PUT"},
+				AllowedHeaders: []string{"x-cos-*", "Content-Type"},
+				ExposeHeaders:  []string{"ETag"},
+				MaxAgeSeconds:  600,
+			},
+			{
+				ID:             "public",
+				AllowedOrigins: []string{"*"},
+				AllowedMethods: []string{"GET"},
+			},
+		},
+		ResponseVary: "true",
+	}
+
+	res := EvaluateCORS(cors, &ObjectOptionsOptions{
+		Origin:                      "https://www.example.com",
+		AccessControlRequestMethod:  "PUT",
+		AccessControlRequestHeaders: "Content-Type, X-Cos-Meta-A",
+	})
+	want := http.Header{
+		"Access-Control-Allow-Origin":   {"https://www.example.com"},
+		"Access-Control-Allow-Methods":  {"GET,PUT"},
+		"Access-Control-Allow-Headers":  {"Content-Type,X-Cos-Meta-A"},
+		"Access-Control-Expose-Headers": {"ETag"},
+		"Access-Control-Max-Age":        {"600"},
+		"Vary":                          {"Origin,Access-Control-Request-Headers,Access-Control-Request-Method"},
+	}
+	if !res.Allowed || res.RuleIndex != 0 || res.Rule.ID != "app" || !reflect.DeepEqual(res.Header, want) {
+		t.Errorf("EvaluateCORS returned %+v, want header %v", res, want)
+	}
+
+	res = EvaluateCORS(cors, &ObjectOptionsOptions{
+		Origin:                     "https://other.com",
+		AccessControlRequestMethod: "GET",
+	})
+	if !res.Allowed || res.RuleIndex != 1 || res.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("EvaluateCORS returned %+v", res)
+	}
+
+	res = EvaluateCORS(cors, &ObjectOptionsOptions{
+		Origin:                     "https://other.com",
+		AccessControlRequestMethod: "DELETE",
+	})
+	if res.Allowed || res.RuleIndex != -1 || res.Reason != "no rule allows method DELETE from origin https://other.com" {
+		t.Errorf("EvaluateCORS returned %+v", res)
+	}
+	if res.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("EvaluateCORS denied request has header %v", res.Header)
+	}
+
+	if res = EvaluateCORS(nil, &ObjectOptionsOptions{Origin: "a", AccessControlRequestMethod: "GET"}); res.Allowed {
+		t.Errorf("EvaluateCORS without configuration returned %+v", res)
+	}
+}
+
+func TestValidateCORS(t *testing.T) {
+	cors := &BucketPutCORSOptions{
+		Rules: []BucketCORSRule{
+			{AllowedOrigins: []string{"https://*.example.com"}, AllowedMethods: []string{"GET", "PUT"}, AllowedHeaders: []string{"*"}},
+			{AllowedOrigins: []string{"https://www.example.com"}, AllowedMethods: []string{"GET"}, AllowedHeaders: []string{"x-cos-meta-*"}},
+			{AllowedOrigins: []string{"https://www.*"}, AllowedMethods: []string{"GET", "HEAD"}},
+			{AllowedOrigins: []string{"https://*.*.com"}, AllowedMethods: []string{"PATCH"}},
+			{AllowedOrigins: []string{"https://other.com"}, AllowedMethods: []string{"GET"}, MaxAgeSeconds: 10},
+		},
+	}
+	issues := ValidateCORS(cors)
+	var got [][3]interface{}
+	for _, issue := range issues {
+		got = append(got, [3]interface{}{issue.Type, issue.RuleIndex, issue.OtherIndex})
+	}
+	want := [][3]interface{}{
+		{CORSIssueUnreachable, 1, 0},
+		{CORSIssueOverlap, 2, 0},
+		{CORSIssueInvalid, 3, -1},
+		{CORSIssueInvalid, 3, -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateCORS returned %v, want %v", issues, want)
+	}
+}
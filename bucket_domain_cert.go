@@ -0,0 +1,271 @@
+package cos
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DomainCertificate 自定义域名的证书链与私钥
+type DomainCertificate struct {
+	CertPEM string
+	KeyPEM  string
+	// 证书链中的第一个证书(域名证书)及其后的中间证书
+	Leaf  *x509.Certificate
+	Chain []*x509.Certificate
+}
+
+// LoadDomainCertificate 从磁盘读取 PEM 格式的证书链与私钥
+func LoadDomainCertificate(certFile, keyFile string) (*DomainCertificate, error) {
+	certPEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDomainCertificate(certPEM, keyPEM)
+}
+
+// ParseDomainCertificate 解析 PEM 格式的证书链与私钥，并检查私钥与域名证书匹配
+func ParseDomainCertificate(certPEM, keyPEM []byte) (*DomainCertificate, error) {
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return nil, err
+	}
+	cert := &DomainCertificate{CertPEM: string(certPEM), KeyPEM: string(keyPEM)}
+	for rest := certPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if cert.Leaf == nil {
+			cert.Leaf = c
+		} else {
+			cert.Chain = append(cert.Chain, c)
+		}
+	}
+	return cert, nil
+}
+
+// Validate 检查证书在 now 时有效、证书链中每个证书由下一个证书签发，且覆盖所有 domains(包括通配符证书)
+func (c *DomainCertificate) Validate(now time.Time, domains ...string) error {
+	if c == nil || c.Leaf == nil {
+		return errors.New("certificate is empty")
+	}
+	if now.Before(c.Leaf.NotBefore) {
+		return fmt.Errorf("certificate is not valid before %v", c.Leaf.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(c.Leaf.NotAfter) {
+		return fmt.Errorf("certificate expired at %v", c.Leaf.NotAfter.Format(time.RFC3339))
+	}
+	chain := append([]*x509.Certificate{c.Leaf}, c.Chain...)
+	for i := 0; i+1 < len(chain); i++ {
+		if err := chain[i].CheckSignatureFrom(chain[i+1]); err != nil {
+			return fmt.Errorf("certificate %q is not signed by %q: %v", chain[i].Subject.CommonName, chain[i+1].Subject.CommonName, err)
+		}
+	}
+	for _, d := range domains {
+		if err := c.Leaf.VerifyHostname(d); err != nil {
+			return fmt.Errorf("certificate does not cover domain %v, SANs: %v", d, strings.Join(c.Leaf.DNSNames, ", "))
+		}
+	}
+	return nil
+}
+
+// CustomCert 转换为 PutDomainCertificate 使用的 BucketDomainCustomCert
+func (c *DomainCertificate) CustomCert() *BucketDomainCustomCert {
+	return &BucketDomainCustomCert{
+		Cert:       c.CertPEM,
+		PrivateKey: c.KeyPEM,
+	}
+}
+
+// BindDomainCertificate 校验证书后将其绑定到 domains
+func (s *BucketService) BindDomainCertificate(ctx context.Context, cert *DomainCertificate, domains ...string) (*Response, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("domains is empty")
+	}
+	if err := cert.Validate(time.Now(), domains...); err != nil {
+		return nil, err
+	}
+	opt := &BucketPutDomainCertificateOptions{
+		CertificateInfo: &BucketDomainCertificateInfo{
+			CertType:   "CustomCert",
+			CustomCert: cert.CustomCert(),
+		},
+		DomainList: domains,
+	}
+	return s.PutDomainCertificate(ctx, opt)
+}
+
+// InspectCertificateOptions is the option of InspectDomainCertificates and InspectTLSCertificate
+type InspectCertificateOptions struct {
+	// 端口，默认为 443
+	Port string
+	// 单个域名的握手超时，默认为 10s
+	Timeout time.Duration
+	// 建立连接的方法，默认使用 net.Dialer，可用于指定代理或测试地址
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+	// 校验证书链的根证书，为 nil 时使用系统根证书
+	RootCAs *x509.CertPool
+	// 计算剩余天数的时间，为零值时使用当前时间
+	Now time.Time
+	// 并发检查的域名数，默认为 1
+	ThreadPoolSize int
+}
+
+// DomainCertificateStatus 域名当前对外提供的证书
+type DomainCertificateStatus struct {
+	Domain string
+	// 域名绑定的状态与类型，来自 GetDomain
+	Status    string
+	Type      string
+	Subject   string
+	Issuer    string
+	DNSNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	// 距离过期的天数，已过期时为负数
+	DaysLeft int
+	// 证书链或域名校验失败的原因，如已过期、不受信任、不覆盖域名
+	VerifyErr error
+	// 无法建立 TLS 连接时的错误
+	Err error
+}
+
+// InspectTLSCertificate 与 domain 建立 TLS 连接并读取对外提供的证书，不因证书无效而失败
+func InspectTLSCertificate(ctx context.Context, domain string, opt *InspectCertificateOptions) *DomainCertificateStatus {
+	if opt == nil {
+		opt = &InspectCertificateOptions{}
+	}
+	port := opt.Port
+	if port == "" {
+		port = "443"
+	}
+	timeout := opt.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	now := opt.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	dial := opt.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	status := &DomainCertificateStatus{Domain: domain}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, err := dial(ctx, "tcp", net.JoinHostPort(domain, port))
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// 只读取证书，校验在握手后单独进行，以便获取已过期或不受信任的证书
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: domain, InsecureSkipVerify: true})
+	if err = tlsConn.Handshake(); err != nil {
+		status.Err = err
+		return status
+	}
+	certs := tlsConn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		status.Err = errors.New("no certificate presented")
+		return status
+	}
+	leaf := certs[0]
+	status.Subject = leaf.Subject.CommonName
+	status.Issuer = leaf.Issuer.CommonName
+	status.DNSNames = leaf.DNSNames
+	status.NotBefore = leaf.NotBefore
+	status.NotAfter = leaf.NotAfter
+	status.DaysLeft = int(leaf.NotAfter.Sub(now).Hours() / 24)
+	if leaf.NotAfter.Before(now) {
+		status.DaysLeft--
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	_, status.VerifyErr = leaf.Verify(x509.VerifyOptions{
+		DNSName:       domain,
+		Roots:         opt.RootCAs,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	return status
+}
+
+// InspectDomainCertificates 列出存储桶绑定的所有自定义域名，并读取每个域名对外提供的证书与过期时间
+func (s *BucketService) InspectDomainCertificates(ctx context.Context, opt *InspectCertificateOptions) ([]DomainCertificateStatus, error) {
+	res, _, err := s.GetDomain(ctx)
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	poolSize := 1
+	if opt != nil && opt.ThreadPoolSize > 0 {
+		poolSize = opt.ThreadPoolSize
+	}
+	statuses := make([]DomainCertificateStatus, len(res.Rules))
+	jobs := make(chan int, len(res.Rules))
+	for i := range res.Rules {
+		jobs <- i
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for i := 0; i < poolSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rule := res.Rules[i]
+				st := InspectTLSCertificate(ctx, rule.Name, opt)
+				st.Status, st.Type = rule.Status, rule.Type
+				statuses[i] = *st
+			}
+		}()
+	}
+	wg.Wait()
+	return statuses, nil
+}
+
+// ExpiringCertificates 返回 days 天内过期(包括已过期)的证书，按过期时间排序；
+// 无法连接或握手失败(Err 不为 nil)的域名无法判断证书状态，在 unreachable 中按原顺序返回
+func ExpiringCertificates(statuses []DomainCertificateStatus, days int) (expiring, unreachable []DomainCertificateStatus) {
+	for _, st := range statuses {
+		if st.Err != nil {
+			unreachable = append(unreachable, st)
+		} else if st.DaysLeft < days {
+			expiring = append(expiring, st)
+		}
+	}
+	sort.SliceStable(expiring, func(i, j int) bool { return expiring[i].NotAfter.Before(expiring[j].NotAfter) })
+	return expiring, unreachable
+}
@@ -0,0 +1,197 @@
+package cos
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, cn string, dnsNames []string, notAfter time.Time, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestBucketService_BindDomainCertificate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ca := newTestCert(t, "Test CA", nil, time.Now().AddDate(1, 0, 0), nil)
+	leaf := newTestCert(t, "example.com", []string{"example.com", "*.example.com"}, time.Now().AddDate(0, 3, 0), ca)
+
+	dir, err := ioutil.TempDir("", "cos-cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	ioutil.WriteFile(certFile, append(append([]byte{}, leaf.certPEM...), ca.certPEM...), 0600)
+	ioutil.WriteFile(keyFile, leaf.keyPEM, 0600)
+
+	cert, err := LoadDomainCertificate(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("LoadDomainCertificate returned error: %v", err)
+	}
+	if cert.Leaf.Subject.CommonName != "example.com" || len(cert.Chain) != 1 {
+		t.Errorf("LoadDomainCertificate returned leaf %v, chain %d", cert.Leaf.Subject, len(cert.Chain))
+	}
+	if err := cert.Validate(time.Now(), "www.example.com", "example.com"); err != nil {
+		t.Errorf("DomainCertificate.Validate returned error: %v", err)
+	}
+	if err := cert.Validate(time.Now(), "www.other.com"); err == nil {
+		t.Errorf("DomainCertificate.Validate should fail for uncovered domain")
+	}
+	if err := cert.Validate(time.Now().AddDate(1, 0, 0), "example.com"); err == nil {
+		t.Errorf("DomainCertificate.Validate should fail for expired certificate")
+	}
+	other := newTestCert(t, "other", []string{"other.com"}, time.Now().AddDate(0, 1, 0), nil)
+	if _, err := ParseDomainCertificate(leaf.certPEM, other.keyPEM); err == nil {
+		t.Errorf("ParseDomainCertificate should fail for mismatched key")
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		testFormValues(t, r, values{"domaincertificate": ""})
+		var opt BucketPutDomainCertificateOptions
+		xml.NewDecoder(r.Body).Decode(&opt)
+		if opt.CertificateInfo.CertType != "CustomCert" || opt.CertificateInfo.CustomCert.Cert != cert.CertPEM ||
+			opt.CertificateInfo.CustomCert.PrivateKey != cert.KeyPEM || len(opt.DomainList) != 1 || opt.DomainList[0] != "www.example.com" {
+			t.Errorf("PutDomainCertificate body is %+v", opt)
+		}
+	})
+	if _, err := client.Bucket.BindDomainCertificate(context.Background(), cert, "www.example.com"); err != nil {
+		t.Fatalf("Bucket.BindDomainCertificate returned error: %v", err)
+	}
+	if _, err := client.Bucket.BindDomainCertificate(context.Background(), cert, "www.other.com"); err == nil {
+		t.Errorf("Bucket.BindDomainCertificate should fail for uncovered domain")
+	}
+}
+
+func TestBucketService_InspectDomainCertificates(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ca := newTestCert(t, "Test CA", nil, time.Now().AddDate(1, 0, 0), nil)
+	leaf := newTestCert(t, "www.example.com", []string{"www.example.com"}, time.Now().Add(10*24*time.Hour+time.Hour), ca)
+	pair, err := tls.X509KeyPair(leaf.certPEM, leaf.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{pair}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, `<DomainConfiguration>
+	<DomainRule><Status>ENABLED</Status><Name>www.example.com</Name><Type>REST</Type></DomainRule>
+	<DomainRule><Status>ENABLED</Status><Name>static.example.com</Name><Type>WEBSITE</Type></DomainRule>
+</DomainConfiguration>`)
+	})
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	opt := &InspectCertificateOptions{
+		RootCAs: roots,
+		Dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if addr != "www.example.com:443" {
+				return nil, fmt.Errorf("dial %v refused", addr)
+			}
+			return (&net.Dialer{}).DialContext(ctx, network, ln.Addr().String())
+		},
+		ThreadPoolSize: 2,
+	}
+	statuses, err := client.Bucket.InspectDomainCertificates(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Bucket.InspectDomainCertificates returned error: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("Bucket.InspectDomainCertificates returned %d statuses, want 2", len(statuses))
+	}
+	www := statuses[0]
+	if www.Err != nil || www.VerifyErr != nil || www.Subject != "www.example.com" || www.Issuer != "Test CA" ||
+		www.DaysLeft != 10 || www.Type != "REST" {
+		t.Errorf("www.example.com status is %+v", www)
+	}
+	if statuses[1].Err == nil {
+		t.Errorf("static.example.com should fail to connect")
+	}
+
+	res, unreachable := ExpiringCertificates(statuses, 30)
+	if len(res) != 1 || res[0].Domain != "www.example.com" {
+		t.Errorf("ExpiringCertificates(30) returned %+v", res)
+	}
+	if len(unreachable) != 1 || unreachable[0].Domain != "static.example.com" {
+		t.Errorf("ExpiringCertificates(30) returned unreachable %+v", unreachable)
+	}
+	if res, unreachable = ExpiringCertificates(statuses, 7); len(res) != 0 || len(unreachable) != 1 {
+		t.Errorf("ExpiringCertificates(7) returned %+v, unreachable %+v", res, unreachable)
+	}
+
+	opt.Now = time.Now().AddDate(0, 1, 0)
+	st := InspectTLSCertificate(context.Background(), "www.example.com", opt)
+	if st.VerifyErr == nil || st.DaysLeft >= 0 {
+		t.Errorf("expired certificate status is %+v", st)
+	}
+}
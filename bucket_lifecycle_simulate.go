@@ -0,0 +1,473 @@
+package cos
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LifecycleObject 参与生命周期模拟的对象，可以由 ListObjects、ListObjectVersions 或清单报告生成
+type LifecycleObject struct {
+	Key          string
+	VersionId    string
+	Size         int64
+	StorageClass string
+	LastModified time.Time
+	// 非当前版本，使用 NoncurrentVersion* 规则
+	Noncurrent bool
+	// 成为非当前版本的时间，为零值时使用 LastModified
+	NoncurrentSince time.Time
+	IsDeleteMarker  bool
+	Tags            map[string]string
+}
+
+// NewLifecycleObjects 由 ListObjects 的结果生成 LifecycleObject
+func NewLifecycleObjects(objects []Object) ([]LifecycleObject, error) {
+	res := make([]LifecycleObject, 0, len(objects))
+	for _, o := range objects {
+		lm, err := time.Parse(time.RFC3339, o.LastModified)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LastModified of %v: %v", o.Key, err)
+		}
+		res = append(res, LifecycleObject{
+			Key:          o.Key,
+			VersionId:    o.VersionId,
+			Size:         o.Size,
+			StorageClass: o.StorageClass,
+			LastModified: lm,
+		})
+	}
+	return res, nil
+}
+
+// 生命周期动作
+const (
+	LifecycleTransition                  = "Transition"
+	LifecycleExpiration                  = "Expiration"
+	LifecycleNoncurrentVersionTransition = "NoncurrentVersionTransition"
+	LifecycleNoncurrentVersionExpiration = "NoncurrentVersionExpiration"
+	LifecycleExpiredObjectDeleteMarker   = "ExpiredObjectDeleteMarker"
+)
+
+// LifecycleAction 对象上预计执行的一个生命周期动作
+type LifecycleAction struct {
+	RuleID string
+	Action string
+	// 沉降的目标存储类型，删除动作为空
+	StorageClass string
+	Date         time.Time
+}
+
+// LifecycleObjectResult 单个对象的模拟结果
+type LifecycleObjectResult struct {
+	Object LifecycleObject
+	// 匹配的已启用规则
+	RuleIDs []string
+	// 按时间排序的生效动作，被更早的删除或更冷的沉降覆盖的动作不包含在内
+	Actions []LifecycleAction
+	// asOf 时的存储类型，已删除时为删除前的存储类型
+	StorageClass string
+	// asOf 时是否已被删除，以及删除时间(不会被删除时为零值)
+	Expired        bool
+	ExpirationDate time.Time
+}
+
+// 规则问题的类型
+const (
+	LifecycleIssueInvalid  = "invalid"
+	LifecycleIssueOverlap  = "overlap"
+	LifecycleIssueConflict = "conflict"
+)
+
+// LifecycleIssue 规则配置中的问题
+type LifecycleIssue struct {
+	Type   string
+	RuleID string
+	// 重叠或冲突的另一条规则
+	OtherRuleID string
+	Message     string
+}
+
+// LifecycleSimulation 是 SimulateLifecycle 的结果
+type LifecycleSimulation struct {
+	Objects []LifecycleObjectResult
+	Issues  []LifecycleIssue
+}
+
+// lifecycleClassOrder 存储类型由热到冷的顺序，沉降只能由热到冷
+var lifecycleClassOrder = map[string]int{
+	"STANDARD":                0,
+	"MAZ_STANDARD":            0,
+	"STANDARD_IA":             1,
+	"MAZ_STANDARD_IA":         1,
+	"INTELLIGENT_TIERING":     2,
+	"MAZ_INTELLIGENT_TIERING": 2,
+	"ARCHIVE":                 3,
+	"DEEP_ARCHIVE":            4,
+}
+
+func lifecycleClassRank(class string) int {
+	if class == "" {
+		return 0
+	}
+	if r, ok := lifecycleClassOrder[strings.ToUpper(class)]; ok {
+		return r
+	}
+	return -1
+}
+
+// SimulateLifecycle 在本地模拟生命周期规则对 objects 的作用，给出每个对象匹配的规则、预计执行的动作与日期，
+// 以及 asOf 时的存储类型和是否已删除；同时检查规则中的天数顺序错误、重叠与冲突。
+// 天数从 LastModified(非当前版本为 NoncurrentSince)起算，并向后取整到 UTC 零点
+func SimulateLifecycle(rules []BucketLifecycleRule, objects []LifecycleObject, asOf time.Time) *LifecycleSimulation {
+	res := &LifecycleSimulation{
+		Issues: validateLifecycleRules(rules),
+	}
+	for _, obj := range objects {
+		res.Objects = append(res.Objects, simulateLifecycleObject(rules, obj, asOf))
+	}
+	return res
+}
+
+func simulateLifecycleObject(rules []BucketLifecycleRule, obj LifecycleObject, asOf time.Time) LifecycleObjectResult {
+	res := LifecycleObjectResult{Object: obj, StorageClass: obj.StorageClass}
+	if res.StorageClass == "" {
+		res.StorageClass = "STANDARD"
+	}
+	base := obj.LastModified
+	if obj.Noncurrent && !obj.NoncurrentSince.IsZero() {
+		base = obj.NoncurrentSince
+	}
+
+	var candidates []LifecycleAction
+	for i := range rules {
+		rule := &rules[i]
+		if !strings.EqualFold(rule.Status, "Enabled") || !matchLifecycleFilter(rule.Filter, &obj) {
+			continue
+		}
+		res.RuleIDs = append(res.RuleIDs, rule.ID)
+		switch {
+		case obj.IsDeleteMarker:
+			if !obj.Noncurrent && rule.Expiration != nil && rule.Expiration.ExpiredObjectDeleteMarker {
+				candidates = append(candidates, LifecycleAction{
+					RuleID: rule.ID,
+					Action: LifecycleExpiredObjectDeleteMarker,
+					Date:   lifecycleDays(base, 0),
+				})
+			}
+		case obj.Noncurrent:
+			for _, t := range rule.NoncurrentVersionTransition {
+				candidates = append(candidates, LifecycleAction{
+					RuleID:       rule.ID,
+					Action:       LifecycleNoncurrentVersionTransition,
+					StorageClass: t.StorageClass,
+					Date:         lifecycleDays(base, t.NoncurrentDays),
+				})
+			}
+			if e := rule.NoncurrentVersionExpiration; e != nil && e.NoncurrentDays > 0 {
+				candidates = append(candidates, LifecycleAction{
+					RuleID: rule.ID,
+					Action: LifecycleNoncurrentVersionExpiration,
+					Date:   lifecycleDays(base, e.NoncurrentDays),
+				})
+			}
+		default:
+			for _, t := range rule.Transition {
+				if date, ok := lifecycleActionDate(base, t.Days, t.Date); ok {
+					candidates = append(candidates, LifecycleAction{
+						RuleID:       rule.ID,
+						Action:       LifecycleTransition,
+						StorageClass: t.StorageClass,
+						Date:         date,
+					})
+				}
+			}
+			if e := rule.Expiration; e != nil {
+				if date, ok := lifecycleActionDate(base, e.Days, e.Date); ok {
+					candidates = append(candidates, LifecycleAction{
+						RuleID: rule.ID,
+						Action: LifecycleExpiration,
+						Date:   date,
+					})
+				}
+			}
+		}
+	}
+
+	// 同一天的动作中删除优先，其次为更冷的存储类型
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if (a.StorageClass == "") != (b.StorageClass == "") {
+			return a.StorageClass == ""
+		}
+		return lifecycleClassRank(a.StorageClass) > lifecycleClassRank(b.StorageClass)
+	})
+	class := res.StorageClass
+	for _, a := range candidates {
+		if a.StorageClass == "" {
+			res.Actions = append(res.Actions, a)
+			res.ExpirationDate = a.Date
+			if !a.Date.After(asOf) {
+				res.Expired = true
+			}
+			break
+		}
+		if lifecycleClassRank(a.StorageClass) <= lifecycleClassRank(class) {
+			continue
+		}
+		class = a.StorageClass
+		res.Actions = append(res.Actions, a)
+		if !a.Date.After(asOf) {
+			res.StorageClass = a.StorageClass
+		}
+	}
+	return res
+}
+
+// lifecycleDays 返回 base 之后第 days 天向后取整到 UTC 零点的时间
+func lifecycleDays(base time.Time, days int) time.Time {
+	t := base.UTC().AddDate(0, 0, days)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(t) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+func lifecycleActionDate(base time.Time, days int, date string) (time.Time, bool) {
+	if date != "" {
+		t, err := parseLifecycleDate(date)
+		return t, err == nil
+	}
+	if days > 0 {
+		return lifecycleDays(base, days), true
+	}
+	return time.Time{}, false
+}
+
+// parseLifecycleDate 规则中的日期为 ISO8601 格式，如 2019-10-20T00:00:00+08:00
+func parseLifecycleDate(date string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", date)
+}
+
+// lifecycleFilterPrefix 返回过滤条件的前缀
+func lifecycleFilterPrefix(f *BucketLifecycleFilter) string {
+	if f == nil {
+		return ""
+	}
+	if f.And != nil && f.And.Prefix != "" {
+		return f.And.Prefix
+	}
+	return f.Prefix
+}
+
+// lifecycleFilterTags 返回过滤条件要求的全部标签
+func lifecycleFilterTags(f *BucketLifecycleFilter) []BucketTaggingTag {
+	if f == nil {
+		return nil
+	}
+	var tags []BucketTaggingTag
+	if f.Tag != nil {
+		tags = append(tags, *f.Tag)
+	}
+	if f.And != nil {
+		tags = append(tags, f.And.Tag...)
+	}
+	return tags
+}
+
+func matchLifecycleFilter(f *BucketLifecycleFilter, obj *LifecycleObject) bool {
+	if !strings.HasPrefix(obj.Key, lifecycleFilterPrefix(f)) {
+		return false
+	}
+	for _, tag := range lifecycleFilterTags(f) {
+		if v, ok := obj.Tags[tag.Key]; !ok || v != tag.Value {
+			return false
+		}
+	}
+	if f != nil && f.And != nil {
+		and := f.And
+		if and.PrefixNotEquals != "" && strings.HasPrefix(obj.Key, and.PrefixNotEquals) {
+			return false
+		}
+		if and.ObjectSizeGreaterThan > 0 && obj.Size <= and.ObjectSizeGreaterThan {
+			return false
+		}
+		if and.ObjectSizeLessThan > 0 && obj.Size >= and.ObjectSizeLessThan {
+			return false
+		}
+	}
+	return true
+}
+
+// lifecycleFiltersOverlap 两个过滤条件是否可能匹配同一个对象
+func lifecycleFiltersOverlap(a, b *BucketLifecycleFilter) bool {
+	pa, pb := lifecycleFilterPrefix(a), lifecycleFilterPrefix(b)
+	if !strings.HasPrefix(pa, pb) && !strings.HasPrefix(pb, pa) {
+		return false
+	}
+	tags := map[string]string{}
+	for _, t := range lifecycleFilterTags(a) {
+		tags[t.Key] = t.Value
+	}
+	for _, t := range lifecycleFilterTags(b) {
+		if v, ok := tags[t.Key]; ok && v != t.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// lifecycleExpirationDays 规则中当前版本的删除天数，未按天数配置时返回 0
+func lifecycleExpirationDays(rule *BucketLifecycleRule) int {
+	if rule.Expiration != nil {
+		return rule.Expiration.Days
+	}
+	return 0
+}
+
+func validateLifecycleRules(rules []BucketLifecycleRule) []LifecycleIssue {
+	var issues []LifecycleIssue
+	invalid := func(id, format string, args ...interface{}) {
+		issues = append(issues, LifecycleIssue{Type: LifecycleIssueInvalid, RuleID: id, Message: fmt.Sprintf(format, args...)})
+	}
+
+	ids := map[string]bool{}
+	for i := range rules {
+		rule := &rules[i]
+		if rule.ID != "" {
+			if ids[rule.ID] {
+				invalid(rule.ID, "duplicate rule id")
+			}
+			ids[rule.ID] = true
+		}
+		if !strings.EqualFold(rule.Status, "Enabled") && !strings.EqualFold(rule.Status, "Disabled") {
+			invalid(rule.ID, "invalid status %q", rule.Status)
+		}
+		if len(rule.Transition) == 0 && rule.Expiration == nil && rule.AbortIncompleteMultipartUpload == nil &&
+			len(rule.NoncurrentVersionTransition) == 0 && rule.NoncurrentVersionExpiration == nil {
+			invalid(rule.ID, "rule has no action")
+		}
+
+		expDays := lifecycleExpirationDays(rule)
+		if e := rule.Expiration; e != nil {
+			if e.Days > 0 && e.Date != "" {
+				invalid(rule.ID, "Expiration has both Days and Date")
+			}
+			if e.Date != "" {
+				if _, err := parseLifecycleDate(e.Date); err != nil {
+					invalid(rule.ID, "invalid Expiration Date %q", e.Date)
+				}
+			}
+			if e.Days < 0 {
+				invalid(rule.ID, "Expiration Days must be positive")
+			}
+		}
+		for _, t := range rule.Transition {
+			if lifecycleClassRank(t.StorageClass) <= 0 {
+				invalid(rule.ID, "invalid Transition StorageClass %q", t.StorageClass)
+			}
+			if t.Days > 0 && t.Date != "" {
+				invalid(rule.ID, "Transition to %v has both Days and Date", t.StorageClass)
+			}
+			if t.Days < 0 {
+				invalid(rule.ID, "Transition Days must be positive")
+			}
+			if expDays > 0 && t.Days >= expDays {
+				invalid(rule.ID, "Transition to %v after %d days is not before Expiration after %d days", t.StorageClass, t.Days, expDays)
+			}
+		}
+		issues = append(issues, checkLifecycleTransitionOrder(rule.ID, rule.Transition)...)
+
+		var ncExpDays int
+		if e := rule.NoncurrentVersionExpiration; e != nil {
+			ncExpDays = e.NoncurrentDays
+			if ncExpDays <= 0 {
+				invalid(rule.ID, "NoncurrentVersionExpiration NoncurrentDays must be positive")
+			}
+		}
+		ncTransitions := make([]BucketLifecycleTransition, 0, len(rule.NoncurrentVersionTransition))
+		for _, t := range rule.NoncurrentVersionTransition {
+			if lifecycleClassRank(t.StorageClass) <= 0 {
+				invalid(rule.ID, "invalid NoncurrentVersionTransition StorageClass %q", t.StorageClass)
+			}
+			if ncExpDays > 0 && t.NoncurrentDays >= ncExpDays {
+				invalid(rule.ID, "NoncurrentVersionTransition to %v after %d days is not before NoncurrentVersionExpiration after %d days", t.StorageClass, t.NoncurrentDays, ncExpDays)
+			}
+			ncTransitions = append(ncTransitions, BucketLifecycleTransition{StorageClass: t.StorageClass, Days: t.NoncurrentDays})
+		}
+		issues = append(issues, checkLifecycleTransitionOrder(rule.ID, ncTransitions)...)
+
+		if a := rule.AbortIncompleteMultipartUpload; a != nil && a.DaysAfterInitiation <= 0 {
+			invalid(rule.ID, "AbortIncompleteMultipartUpload DaysAfterInitiation must be positive")
+		}
+	}
+
+	for i := range rules {
+		for j := i + 1; j < len(rules); j++ {
+			a, b := &rules[i], &rules[j]
+			if !strings.EqualFold(a.Status, "Enabled") || !strings.EqualFold(b.Status, "Enabled") ||
+				!lifecycleFiltersOverlap(a.Filter, b.Filter) {
+				continue
+			}
+			issue := LifecycleIssue{Type: LifecycleIssueOverlap, RuleID: a.ID, OtherRuleID: b.ID}
+			issue.Message = fmt.Sprintf("rules %v and %v may apply to the same objects", a.ID, b.ID)
+			if msg := lifecycleRulesConflict(a, b); msg != "" {
+				issue.Type, issue.Message = LifecycleIssueConflict, msg
+			} else if msg := lifecycleRulesConflict(b, a); msg != "" {
+				issue.Type, issue.Message = LifecycleIssueConflict, msg
+				issue.RuleID, issue.OtherRuleID = b.ID, a.ID
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// checkLifecycleTransitionOrder 更冷的存储类型的沉降天数必须大于更热的存储类型
+func checkLifecycleTransitionOrder(id string, transitions []BucketLifecycleTransition) []LifecycleIssue {
+	var issues []LifecycleIssue
+	for i := range transitions {
+		for j := range transitions {
+			a, b := transitions[i], transitions[j]
+			if lifecycleClassRank(a.StorageClass) > lifecycleClassRank(b.StorageClass) && lifecycleClassRank(b.StorageClass) > 0 &&
+				a.Days > 0 && a.Days <= b.Days {
+				issues = append(issues, LifecycleIssue{
+					Type:   LifecycleIssueInvalid,
+					RuleID: id,
+					Message: fmt.Sprintf("transition to %v after %d days is not after transition to %v after %d days",
+						a.StorageClass, a.Days, b.StorageClass, b.Days),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// lifecycleRulesConflict 检查 a 的沉降是否会被 b 的删除抢先，或两条规则对同一存储类型或删除配置了不同天数
+func lifecycleRulesConflict(a, b *BucketLifecycleRule) string {
+	expDays := lifecycleExpirationDays(b)
+	for _, t := range a.Transition {
+		if expDays > 0 && t.Days >= expDays {
+			return fmt.Sprintf("rule %v transitions to %v after %d days, but rule %v expires the same objects after %d days",
+				a.ID, t.StorageClass, t.Days, b.ID, expDays)
+		}
+		for _, o := range b.Transition {
+			if strings.EqualFold(t.StorageClass, o.StorageClass) && t.Days != o.Days {
+				return fmt.Sprintf("rules %v and %v transition the same objects to %v after %d and %d days",
+					a.ID, b.ID, t.StorageClass, t.Days, o.Days)
+			}
+		}
+	}
+	if d := lifecycleExpirationDays(a); d > 0 && expDays > 0 && d != expDays {
+		return fmt.Sprintf("rules %v and %v expire the same objects after %d and %d days", a.ID, b.ID, d, expDays)
+	}
+	return ""
+}
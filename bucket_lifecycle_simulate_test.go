@@ -0,0 +1,144 @@
+package cos
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulateLifecycle(t *testing.T) {
+	lm := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time {
+		return time.Date(2024, 1, 2+n, 0, 0, 0, 0, time.UTC)
+	}
+	rules := []BucketLifecycleRule{
+		{
+			ID:     "logs",
+			Status: "Enabled",
+			Filter: &BucketLifecycleFilter{Prefix: "logs/"},
+			Transition: []BucketLifecycleTransition{
+				{Days: 30, StorageClass: "STANDARD_IA"},
+				{Days: 90, StorageClass: "ARCHIVE"},
+			},
+			Expiration:                  &BucketLifecycleExpiration{Days: 365},
+			NoncurrentVersionExpiration: &BucketLifecycleNoncurrentVersion{NoncurrentDays: 7},
+		},
+		{
+			ID:     "tmp",
+			Status: "Enabled",
+			Filter: &BucketLifecycleFilter{
+				And: &BucketLifecycleAndOperator{
+					Prefix: "logs/tmp/",
+					Tag:    []BucketTaggingTag{{Key: "temp", Value: "true"}},
+				},
+			},
+			Expiration: &BucketLifecycleExpiration{Days: 10},
+		},
+		{
+			ID:         "disabled",
+			Status:     "Disabled",
+			Expiration: &BucketLifecycleExpiration{Days: 1},
+		},
+	}
+	objects := []LifecycleObject{
+		{Key: "logs/a", LastModified: lm},
+		{Key: "logs/tmp/b", LastModified: lm, Tags: map[string]string{"temp": "true"}},
+		{Key: "logs/c", LastModified: lm, Noncurrent: true, NoncurrentSince: lm.AddDate(0, 0, 10)},
+		{Key: "data/d", LastModified: lm, StorageClass: "STANDARD_IA"},
+	}
+	res := SimulateLifecycle(rules, objects, lm.AddDate(0, 0, 100))
+
+	a := res.Objects[0]
+	wantActions := []LifecycleAction{
+		{RuleID: "logs", Action: LifecycleTransition, StorageClass: "STANDARD_IA", Date: day(30)},
+		{RuleID: "logs", Action: LifecycleTransition, StorageClass: "ARCHIVE", Date: day(90)},
+		{RuleID: "logs", Action: LifecycleExpiration, Date: day(365)},
+	}
+	if !reflect.DeepEqual(a.Actions, wantActions) {
+		t.Errorf("logs/a actions are %+v, want %+v", a.Actions, wantActions)
+	}
+	if a.StorageClass != "ARCHIVE" || a.Expired || !a.ExpirationDate.Equal(day(365)) {
+		t.Errorf("logs/a result is %+v", a)
+	}
+
+	b := res.Objects[1]
+	if !reflect.DeepEqual(b.RuleIDs, []string{"logs", "tmp"}) {
+		t.Errorf("logs/tmp/b rules are %v", b.RuleIDs)
+	}
+	if !b.Expired || len(b.Actions) != 1 || b.Actions[0].RuleID != "tmp" || b.StorageClass != "STANDARD" {
+		t.Errorf("logs/tmp/b result is %+v", b)
+	}
+
+	c := res.Objects[2]
+	if !c.Expired || len(c.Actions) != 1 || c.Actions[0].Action != LifecycleNoncurrentVersionExpiration ||
+		!c.ExpirationDate.Equal(day(17)) {
+		t.Errorf("logs/c result is %+v", c)
+	}
+
+	d := res.Objects[3]
+	if len(d.RuleIDs) != 0 || len(d.Actions) != 0 || d.StorageClass != "STANDARD_IA" {
+		t.Errorf("data/d result is %+v", d)
+	}
+
+	if len(res.Issues) != 1 || res.Issues[0].Type != LifecycleIssueConflict ||
+		res.Issues[0].RuleID != "logs" || res.Issues[0].OtherRuleID != "tmp" {
+		t.Errorf("SimulateLifecycle issues are %+v", res.Issues)
+	}
+}
+
+func TestSimulateLifecycle_InvalidRules(t *testing.T) {
+	rules := []BucketLifecycleRule{
+		{
+			ID:     "r1",
+			Status: "Enabled",
+			Filter: &BucketLifecycleFilter{Prefix: "a/"},
+			Transition: []BucketLifecycleTransition{
+				{Days: 60, StorageClass: "STANDARD_IA"},
+				{Days: 30, StorageClass: "ARCHIVE"},
+				{Days: 120, StorageClass: "DEEP_ARCHIVE"},
+			},
+			Expiration: &BucketLifecycleExpiration{Days: 100},
+		},
+		{
+			ID:     "r1",
+			Status: "enable",
+			Filter: &BucketLifecycleFilter{Prefix: "b/"},
+		},
+		{
+			ID:         "r3",
+			Status:     "Enabled",
+			Filter:     &BucketLifecycleFilter{Prefix: "b/"},
+			Expiration: &BucketLifecycleExpiration{Days: 10},
+		},
+	}
+	res := SimulateLifecycle(rules, nil, time.Now())
+	var messages []string
+	for _, issue := range res.Issues {
+		if issue.Type != LifecycleIssueInvalid {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+		messages = append(messages, issue.Message)
+	}
+	want := []string{
+		"Transition to DEEP_ARCHIVE after 120 days is not before Expiration",
+		"transition to ARCHIVE after 30 days is not after transition to STANDARD_IA after 60 days",
+		"duplicate rule id",
+		"invalid status",
+		"rule has no action",
+	}
+	for _, w := range want {
+		found := false
+		for _, m := range messages {
+			if strings.Contains(m, w) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("issue %q not found in %v", w, messages)
+		}
+	}
+	if len(messages) != len(want) {
+		t.Errorf("SimulateLifecycle returned %d issues, want %d: %v", len(messages), len(want), messages)
+	}
+}
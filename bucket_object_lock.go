@@ -3,7 +3,18 @@ package cos
 import (
 	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
+)
+
+// 对象保留模式与法律保留状态
+const (
+	ObjectLockModeCompliance = "COMPLIANCE"
+	ObjectLockModeGovernance = "GOVERNANCE"
+	ObjectLegalHoldOn        = "ON"
+	ObjectLegalHoldOff       = "OFF"
 )
 
 type ObjectLockRule struct {
@@ -41,8 +52,15 @@ func (s *BucketService) GetObjectLockConfiguration(ctx context.Context) (*Bucket
 	return &res, resp, err
 }
 
+// objectVersionQuery 仅携带 versionId 的请求参数
+type objectVersionQuery struct {
+	VersionId string `url:"versionId,omitempty"`
+}
+
 type ObjectGetRetentionOptions struct {
 	XOptionHeader *http.Header `header:"-,omitempty" url:"-" xml:"-"`
+	// 指定对象版本，为空时为最新版本
+	VersionId string `header:"-" url:"versionId,omitempty" xml:"-"`
 }
 
 type ObjectGetRetentionResult struct {
@@ -58,6 +76,7 @@ func (s *ObjectService) GetRetention(ctx context.Context, key string, opt *Objec
 		uri:       "/" + encodeURIComponent(key) + "?retention",
 		method:    http.MethodGet,
 		optHeader: opt,
+		optQuery:  opt,
 		result:    &res,
 	}
 	resp, err := s.client.doRetry(ctx, &sendOpt)
@@ -68,6 +87,14 @@ type ObjectPutRetentionOptions struct {
 	XMLName         xml.Name `xml:"Retention"`
 	RetainUntilDate string   `xml:"RetainUntilDate,omitempty"`
 	Mode            string   `xml:"Mode,omitempty"`
+	// 指定对象版本，为空时为最新版本
+	VersionId string `xml:"-"`
+	// 缩短或移除 GOVERNANCE 模式的保留期时需要设置
+	BypassGovernanceRetention bool `xml:"-"`
+}
+
+type objectPutRetentionHeader struct {
+	BypassGovernanceRetention bool `header:"x-cos-bypass-governance-retention,omitempty"`
 }
 
 func (s *ObjectService) PutRetention(ctx context.Context, key string, opt *ObjectPutRetentionOptions) (*Response, error) {
@@ -77,6 +104,160 @@ func (s *ObjectService) PutRetention(ctx context.Context, key string, opt *Objec
 		method:  http.MethodPut,
 		body:    opt,
 	}
+	if opt != nil {
+		sendOpt.optQuery = &objectVersionQuery{VersionId: opt.VersionId}
+		sendOpt.optHeader = &objectPutRetentionHeader{BypassGovernanceRetention: opt.BypassGovernanceRetention}
+	}
+	resp, err := s.client.doRetry(ctx, &sendOpt)
+	return resp, err
+}
+
+type ObjectGetLegalHoldOptions struct {
+	XOptionHeader *http.Header `header:"-,omitempty" url:"-" xml:"-"`
+	// 指定对象版本，为空时为最新版本
+	VersionId string `header:"-" url:"versionId,omitempty" xml:"-"`
+}
+
+type ObjectGetLegalHoldResult struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Status  string   `xml:"Status,omitempty"`
+}
+
+// GetLegalHold 查询对象的法律保留状态
+func (s *ObjectService) GetLegalHold(ctx context.Context, key string, opt *ObjectGetLegalHoldOptions) (*ObjectGetLegalHoldResult, *Response, error) {
+	var res ObjectGetLegalHoldResult
+	sendOpt := sendOptions{
+		baseURL:   s.client.BaseURL.BucketURL,
+		uri:       "/" + encodeURIComponent(key) + "?legal-hold",
+		method:    http.MethodGet,
+		optHeader: opt,
+		optQuery:  opt,
+		result:    &res,
+	}
+	resp, err := s.client.doRetry(ctx, &sendOpt)
+	return &res, resp, err
+}
+
+type ObjectPutLegalHoldOptions struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	// 可选值: ON、OFF
+	Status string `xml:"Status"`
+	// 指定对象版本，为空时为最新版本
+	VersionId string `xml:"-"`
+}
+
+// PutLegalHold 设置对象的法律保留状态，处于法律保留的对象在解除前不能被删除或覆盖
+func (s *ObjectService) PutLegalHold(ctx context.Context, key string, opt *ObjectPutLegalHoldOptions) (*Response, error) {
+	sendOpt := sendOptions{
+		baseURL: s.client.BaseURL.BucketURL,
+		uri:     "/" + encodeURIComponent(key) + "?legal-hold",
+		method:  http.MethodPut,
+		body:    opt,
+	}
+	if opt != nil {
+		sendOpt.optQuery = &objectVersionQuery{VersionId: opt.VersionId}
+	}
 	resp, err := s.client.doRetry(ctx, &sendOpt)
 	return resp, err
 }
+
+// ExtendRetention 将对象的保留期延长至 retainUntil，保留模式不变(未设置时为 COMPLIANCE)；
+// 当前保留期已不早于 retainUntil 时不做修改。返回更新后的保留设置
+func (s *ObjectService) ExtendRetention(ctx context.Context, key string, retainUntil time.Time, versionId ...string) (*ObjectGetRetentionResult, *Response, error) {
+	var id string
+	if len(versionId) > 0 {
+		id = versionId[0]
+	}
+	cur, resp, err := s.GetRetention(ctx, key, &ObjectGetRetentionOptions{VersionId: id})
+	if err != nil && !isNoRetentionError(err) {
+		return nil, resp, err
+	}
+	if err != nil {
+		cur = &ObjectGetRetentionResult{}
+	}
+	if cur.RetainUntilDate != "" {
+		until, perr := time.Parse(time.RFC3339, cur.RetainUntilDate)
+		if perr != nil {
+			return nil, resp, fmt.Errorf("invalid RetainUntilDate %v: %v", cur.RetainUntilDate, perr)
+		}
+		if !until.Before(retainUntil) {
+			return cur, resp, nil
+		}
+	}
+	mode := cur.Mode
+	if mode == "" {
+		mode = ObjectLockModeCompliance
+	}
+	res := &ObjectGetRetentionResult{
+		XMLName:         xml.Name{Local: "Retention"},
+		RetainUntilDate: retainUntil.UTC().Format("2006-01-02T15:04:05.000Z"),
+		Mode:            mode,
+	}
+	resp, err = s.PutRetention(ctx, key, &ObjectPutRetentionOptions{
+		RetainUntilDate: res.RetainUntilDate,
+		Mode:            res.Mode,
+		VersionId:       id,
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+	return res, resp, nil
+}
+
+// isNoRetentionError 对象未设置保留期
+func isNoRetentionError(err error) bool {
+	var code ErrorCode
+	if !errors.As(err, &code) {
+		return false
+	}
+	return code == "NoSuchObjectLockConfiguration" || code == "ObjectLockConfigurationNotFoundError"
+}
+
+// ObjectLockedError 删除受对象锁定(WORM)保护的对象时返回的错误，
+// 可以通过 errors.As 获取，errors.Is(err, ErrInvalidRequest) 同样成立
+type ObjectLockedError struct {
+	Key       string
+	VersionId string
+	// 对象当前的保留设置与法律保留状态，查询失败时为空
+	Mode            string
+	RetainUntilDate string
+	LegalHold       string
+	Err             *ErrorResponse
+}
+
+func (e *ObjectLockedError) Error() string {
+	return fmt.Sprintf("object %v is protected by object lock(mode: %v, retain until: %v, legal hold: %v): %v",
+		e.Key, e.Mode, e.RetainUntilDate, e.LegalHold, e.Err)
+}
+
+func (e *ObjectLockedError) Unwrap() error {
+	return e.Err
+}
+
+// DeleteWithLockCheck 与 Delete 相同，但删除失败(InvalidRequest)时查询对象的保留设置与法律保留状态，
+// 对象仍在保留期内或处于法律保留时返回 *ObjectLockedError，否则返回原错误
+func (s *ObjectService) DeleteWithLockCheck(ctx context.Context, name string, opt ...*ObjectDeleteOptions) (*Response, error) {
+	resp, err := s.Delete(ctx, name, opt...)
+	var cosErr *ErrorResponse
+	if err == nil || !errors.Is(err, ErrInvalidRequest) || !errors.As(err, &cosErr) {
+		return resp, err
+	}
+	lerr := &ObjectLockedError{Key: name, Err: cosErr}
+	if len(opt) > 0 && opt[0] != nil {
+		lerr.VersionId = opt[0].VersionId
+	}
+	if res, _, rerr := s.GetRetention(ctx, name, &ObjectGetRetentionOptions{VersionId: lerr.VersionId}); rerr == nil {
+		lerr.Mode, lerr.RetainUntilDate = res.Mode, res.RetainUntilDate
+	}
+	if res, _, herr := s.GetLegalHold(ctx, name, &ObjectGetLegalHoldOptions{VersionId: lerr.VersionId}); herr == nil {
+		lerr.LegalHold = res.Status
+	}
+	locked := lerr.LegalHold == ObjectLegalHoldOn
+	if until, perr := time.Parse(time.RFC3339, lerr.RetainUntilDate); perr == nil && until.After(time.Now()) {
+		locked = true
+	}
+	if !locked {
+		return resp, err
+	}
+	return resp, lerr
+}
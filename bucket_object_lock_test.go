@@ -1,12 +1,16 @@
 package cos
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestBucketService_GetObjectLockConfiguration(t *testing.T) {
@@ -141,3 +145,171 @@ func TestBucketService_PutRetention(t *testing.T) {
 		t.Fatalf("Object.GetRetention returned error: %v", err)
 	}
 }
+
+func TestObjectService_LegalHold(t *testing.T) {
+	setup()
+	defer teardown()
+
+	status := ObjectLegalHoldOff
+	mux.HandleFunc("/example", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{
+			"legal-hold": "",
+			"versionId":  "v1",
+		})
+		if r.Method == http.MethodPut {
+			body := new(ObjectPutLegalHoldOptions)
+			xml.NewDecoder(r.Body).Decode(body)
+			status = body.Status
+			return
+		}
+		fmt.Fprintf(w, "<LegalHold><Status>%v</Status></LegalHold>", status)
+	})
+
+	_, err := client.Object.PutLegalHold(context.Background(), "example", &ObjectPutLegalHoldOptions{
+		Status:    ObjectLegalHoldOn,
+		VersionId: "v1",
+	})
+	if err != nil {
+		t.Fatalf("Object.PutLegalHold returned error: %v", err)
+	}
+	res, _, err := client.Object.GetLegalHold(context.Background(), "example", &ObjectGetLegalHoldOptions{VersionId: "v1"})
+	if err != nil {
+		t.Fatalf("Object.GetLegalHold returned error: %v", err)
+	}
+	if res.Status != ObjectLegalHoldOn {
+		t.Errorf("Object.GetLegalHold returned %v, want %v", res.Status, ObjectLegalHoldOn)
+	}
+}
+
+func TestObjectService_ExtendRetention(t *testing.T) {
+	setup()
+	defer teardown()
+
+	until := "2026-10-19T00:00:00.000Z"
+	puts := 0
+	mux.HandleFunc("/example", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"retention": ""})
+		if r.Method == http.MethodPut {
+			puts++
+			body := new(ObjectPutRetentionOptions)
+			xml.NewDecoder(r.Body).Decode(body)
+			if body.Mode != ObjectLockModeGovernance {
+				t.Errorf("Object.ExtendRetention mode is %v, want %v", body.Mode, ObjectLockModeGovernance)
+			}
+			until = body.RetainUntilDate
+			return
+		}
+		fmt.Fprintf(w, "<Retention><Mode>GOVERNANCE</Mode><RetainUntilDate>%v</RetainUntilDate></Retention>", until)
+	})
+
+	newUntil := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	res, _, err := client.Object.ExtendRetention(context.Background(), "example", newUntil)
+	if err != nil {
+		t.Fatalf("Object.ExtendRetention returned error: %v", err)
+	}
+	if res.RetainUntilDate != "2027-01-01T00:00:00.000Z" || until != res.RetainUntilDate {
+		t.Errorf("Object.ExtendRetention returned %v, server has %v", res.RetainUntilDate, until)
+	}
+	// 不缩短保留期
+	_, _, err = client.Object.ExtendRetention(context.Background(), "example", newUntil.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Object.ExtendRetention returned error: %v", err)
+	}
+	if puts != 1 {
+		t.Errorf("Object.ExtendRetention put %v times, want 1", puts)
+	}
+
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+	})
+	_, _, err = client.Object.ExtendRetention(context.Background(), "missing", newUntil)
+	if !errors.Is(err, ErrNoSuchKey) {
+		t.Errorf("Object.ExtendRetention returned %v, want NoSuchKey", err)
+	}
+}
+
+func TestObjectService_DeleteWithLockCheck(t *testing.T) {
+	setup()
+	defer teardown()
+
+	retainUntil := time.Now().AddDate(1, 0, 0).UTC().Format("2006-01-02T15:04:05.000Z")
+	legalHold := ObjectLegalHoldOn
+	mux.HandleFunc("/example", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `<Error><Code>InvalidRequest</Code><Message>object is locked</Message></Error>`)
+		case r.URL.Query().Get("versionId") != "v1":
+			t.Errorf("versionId is %v, want v1", r.URL.Query().Get("versionId"))
+		case r.URL.Query()["retention"] != nil:
+			fmt.Fprintf(w, `<Retention><Mode>COMPLIANCE</Mode><RetainUntilDate>%v</RetainUntilDate></Retention>`, retainUntil)
+		default:
+			fmt.Fprintf(w, `<LegalHold><Status>%v</Status></LegalHold>`, legalHold)
+		}
+	})
+
+	_, err := client.Object.DeleteWithLockCheck(context.Background(), "example", &ObjectDeleteOptions{VersionId: "v1"})
+	var lerr *ObjectLockedError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("Object.DeleteWithLockCheck returned %v, want ObjectLockedError", err)
+	}
+	want := &ObjectLockedError{
+		Key:             "example",
+		VersionId:       "v1",
+		Mode:            ObjectLockModeCompliance,
+		RetainUntilDate: retainUntil,
+		LegalHold:       ObjectLegalHoldOn,
+		Err:             lerr.Err,
+	}
+	if !reflect.DeepEqual(lerr, want) {
+		t.Errorf("Object.DeleteWithLockCheck returned %+v, want %+v", lerr, want)
+	}
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("Object.DeleteWithLockCheck error should be ErrInvalidRequest")
+	}
+
+	// 保留期已过且未处于法律保留时，InvalidRequest 与对象锁定无关
+	retainUntil = time.Now().AddDate(-1, 0, 0).UTC().Format("2006-01-02T15:04:05.000Z")
+	legalHold = ObjectLegalHoldOff
+	_, err = client.Object.DeleteWithLockCheck(context.Background(), "example", &ObjectDeleteOptions{VersionId: "v1"})
+	if errors.As(err, &lerr) || !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("Object.DeleteWithLockCheck returned %v, want the original error", err)
+	}
+}
+
+func TestObjectService_PutObjectLockHeaders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/example", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "x-cos-object-lock-mode", ObjectLockModeCompliance)
+		testHeader(t, r, "x-cos-object-lock-retain-until-date", "2027-01-01T00:00:00.000Z")
+		testHeader(t, r, "x-cos-object-lock-legal-hold", ObjectLegalHoldOn)
+		w.Write([]byte("<CopyObjectResult><ETag>\"etag\"</ETag></CopyObjectResult>"))
+	})
+
+	client.Conf.EnableCRC = false
+	_, err := client.Object.Put(context.Background(), "example", bytes.NewReader([]byte("test")), &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			XCosObjectLockMode:            ObjectLockModeCompliance,
+			XCosObjectLockRetainUntilDate: "2027-01-01T00:00:00.000Z",
+			XCosObjectLockLegalHold:       ObjectLegalHoldOn,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	sourceURL := strings.TrimPrefix(client.BaseURL.BucketURL.String(), "http://") + "/source"
+	_, _, err = client.Object.Copy(context.Background(), "example", sourceURL, &ObjectCopyOptions{
+		ObjectCopyHeaderOptions: &ObjectCopyHeaderOptions{
+			XCosObjectLockMode:            ObjectLockModeCompliance,
+			XCosObjectLockRetainUntilDate: "2027-01-01T00:00:00.000Z",
+			XCosObjectLockLegalHold:       ObjectLegalHoldOn,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.Copy returned error: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package cos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AbortStaleUploadsOptions is the option of AbortStaleUploads
+type AbortStaleUploadsOptions struct {
+	// 并发终止的分块上传数，默认为 1
+	ThreadPoolSize int
+	// 只列出过期的分块上传，不实际终止
+	DryRun bool
+}
+
+// StaleUpload 过期未完成的分块上传
+type StaleUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+	// 已上传的块数与总大小，查询失败时 Size 为 -1
+	Parts int
+	Size  int64
+	// 查询已上传块(ListParts)失败时的错误，此时仍会终止分块上传
+	ListPartsErr error
+	// 终止失败时的错误
+	Err error
+}
+
+// AbortStaleUploadsResult is the result of AbortStaleUploads
+type AbortStaleUploadsResult struct {
+	Uploads []StaleUpload
+	// 成功终止的分块上传数
+	Aborted int
+	// 成功终止的分块上传中已上传块的总大小，不包括 Size 未知的分块上传
+	ReclaimedBytes int64
+}
+
+// AbortStaleUploads 终止 prefix 下发起时间早于 olderThan 之前的未完成分块上传，并统计释放的已上传块大小。
+// 如需由 COS 自动清理，可以使用 NewAbortIncompleteMultipartUploadRule 生成等价的生命周期规则
+func (s *BucketService) AbortStaleUploads(ctx context.Context, prefix string, olderThan time.Duration, opt ...*AbortStaleUploadsOptions) (*AbortStaleUploadsResult, error) {
+	aopt := &AbortStaleUploadsOptions{}
+	if len(opt) > 0 && opt[0] != nil {
+		aopt = opt[0]
+	}
+	poolSize := aopt.ThreadPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+	deadline := time.Now().Add(-olderThan)
+
+	var uploads []StaleUpload
+	listOpt := &ListMultipartUploadsOptions{
+		Prefix:     prefix,
+		MaxUploads: 1000,
+	}
+	for {
+		res, _, err := s.ListMultipartUploads(ctx, listOpt)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range res.Uploads {
+			initiated, err := time.Parse(time.RFC3339, u.Initiated)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Initiated of upload %v: %v", u.UploadID, err)
+			}
+			if initiated.Before(deadline) {
+				uploads = append(uploads, StaleUpload{
+					Key:       u.Key,
+					UploadID:  u.UploadID,
+					Initiated: initiated,
+				})
+			}
+		}
+		if !res.IsTruncated {
+			break
+		}
+		listOpt.KeyMarker = res.NextKeyMarker
+		listOpt.UploadIDMarker = res.NextUploadIDMarker
+	}
+
+	object := &ObjectService{client: s.client}
+	jobs := make(chan *StaleUpload, len(uploads))
+	for i := range uploads {
+		jobs <- &uploads[i]
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for i := 0; i < poolSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				u.Parts, u.Size, u.ListPartsErr = object.uploadedPartsSize(ctx, u.Key, u.UploadID)
+				if u.ListPartsErr != nil {
+					u.Parts, u.Size = 0, -1
+				}
+				if !aopt.DryRun {
+					_, u.Err = object.AbortMultipartUpload(ctx, u.Key, u.UploadID)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	res := &AbortStaleUploadsResult{Uploads: uploads}
+	if !aopt.DryRun {
+		for _, u := range uploads {
+			if u.Err == nil {
+				res.Aborted++
+				if u.Size > 0 {
+					res.ReclaimedBytes += u.Size
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// uploadedPartsSize 统计分块上传中已上传的块数与总大小
+func (s *ObjectService) uploadedPartsSize(ctx context.Context, name, uploadID string) (int, int64, error) {
+	var parts int
+	var size int64
+	opt := &ObjectListPartsOptions{MaxParts: "1000"}
+	for {
+		res, _, err := s.ListParts(ctx, name, uploadID, opt)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, p := range res.Parts {
+			parts++
+			size += p.Size
+		}
+		if !res.IsTruncated {
+			return parts, size, nil
+		}
+		opt.PartNumberMarker = res.NextPartNumberMarker
+		if opt.PartNumberMarker == "" && len(res.Parts) > 0 {
+			opt.PartNumberMarker = strconv.Itoa(res.Parts[len(res.Parts)-1].PartNumber)
+		}
+	}
+}
+
+// NewAbortIncompleteMultipartUploadRule 生成与 AbortStaleUploads 等价的生命周期规则:
+// 发起超过 olderThan(向上取整到天)仍未完成的分块上传由 COS 自动终止
+func NewAbortIncompleteMultipartUploadRule(id, prefix string, olderThan time.Duration) BucketLifecycleRule {
+	days := int((olderThan + 24*time.Hour - 1) / (24 * time.Hour))
+	if days < 1 {
+		days = 1
+	}
+	return BucketLifecycleRule{
+		ID:     id,
+		Status: "Enabled",
+		Filter: &BucketLifecycleFilter{Prefix: prefix},
+		AbortIncompleteMultipartUpload: &BucketLifecycleAbortIncompleteMultipartUpload{
+			DaysAfterInitiation: days,
+		},
+	}
+}
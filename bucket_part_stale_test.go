@@ -0,0 +1,114 @@
+package cos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBucketService_AbortStaleUploads(t *testing.T) {
+	setup()
+	defer teardown()
+
+	stale := time.Now().Add(-72 * time.Hour).UTC().Format(time.RFC3339)
+	fresh := time.Now().UTC().Format(time.RFC3339)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		if r.URL.Query().Get("key-marker") == "" {
+			fmt.Fprintf(w, `<ListMultipartUploadsResult>
+	<IsTruncated>true</IsTruncated>
+	<NextKeyMarker>a</NextKeyMarker>
+	<NextUploadIdMarker>1</NextUploadIdMarker>
+	<Upload><Key>a</Key><UploadId>1</UploadId><Initiated>%v</Initiated></Upload>
+</ListMultipartUploadsResult>`, stale)
+			return
+		}
+		testFormValues(t, r, values{
+			"uploads":          "",
+			"max-uploads":      "1000",
+			"key-marker":       "a",
+			"upload-id-marker": "1",
+		})
+		fmt.Fprintf(w, `<ListMultipartUploadsResult>
+	<Upload><Key>b</Key><UploadId>2</UploadId><Initiated>%v</Initiated></Upload>
+	<Upload><Key>c</Key><UploadId>3</UploadId><Initiated>%v</Initiated></Upload>
+	<Upload><Key>d</Key><UploadId>4</UploadId><Initiated>%v</Initiated></Upload>
+</ListMultipartUploadsResult>`, fresh, stale, stale)
+	})
+	var mu sync.Mutex
+	aborted := map[string]bool{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if r.URL.Query().Get("part-number-marker") == "" {
+				fmt.Fprint(w, `<ListPartsResult>
+	<IsTruncated>true</IsTruncated>
+	<NextPartNumberMarker>1</NextPartNumberMarker>
+	<Part><PartNumber>1</PartNumber><Size>100</Size></Part>
+</ListPartsResult>`)
+				return
+			}
+			fmt.Fprint(w, `<ListPartsResult><Part><PartNumber>2</PartNumber><Size>50</Size></Part></ListPartsResult>`)
+			return
+		}
+		testMethod(t, r, http.MethodDelete)
+		mu.Lock()
+		aborted[r.URL.Query().Get("uploadId")] = true
+		mu.Unlock()
+	}
+	mux.HandleFunc("/a", handler)
+	mux.HandleFunc("/c", handler)
+	// ListParts 失败时仍终止分块上传
+	mux.HandleFunc("/d", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	})
+
+	res, err := client.Bucket.AbortStaleUploads(context.Background(), "", 24*time.Hour, &AbortStaleUploadsOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Bucket.AbortStaleUploads returned error: %v", err)
+	}
+	if len(res.Uploads) != 3 || res.Aborted != 0 || len(aborted) != 0 {
+		t.Errorf("Bucket.AbortStaleUploads dry run returned %+v", res)
+	}
+
+	res, err = client.Bucket.AbortStaleUploads(context.Background(), "", 24*time.Hour, &AbortStaleUploadsOptions{ThreadPoolSize: 2})
+	if err != nil {
+		t.Fatalf("Bucket.AbortStaleUploads returned error: %v", err)
+	}
+	if res.Aborted != 3 || res.ReclaimedBytes != 300 || !aborted["1"] || !aborted["3"] || !aborted["4"] {
+		t.Errorf("Bucket.AbortStaleUploads returned %+v, aborted %v", res, aborted)
+	}
+	for _, u := range res.Uploads {
+		if u.Key == "d" {
+			if u.ListPartsErr == nil || u.Err != nil || u.Size != -1 {
+				t.Errorf("Bucket.AbortStaleUploads upload %+v", u)
+			}
+			continue
+		}
+		if u.Parts != 2 || u.Size != 150 || u.ListPartsErr != nil || u.Err != nil {
+			t.Errorf("Bucket.AbortStaleUploads upload %+v", u)
+		}
+	}
+}
+
+func TestNewAbortIncompleteMultipartUploadRule(t *testing.T) {
+	rule := NewAbortIncompleteMultipartUploadRule("abort", "tmp/", 36*time.Hour)
+	want := BucketLifecycleRule{
+		ID:     "abort",
+		Status: "Enabled",
+		Filter: &BucketLifecycleFilter{Prefix: "tmp/"},
+		AbortIncompleteMultipartUpload: &BucketLifecycleAbortIncompleteMultipartUpload{
+			DaysAfterInitiation: 2,
+		},
+	}
+	if !reflect.DeepEqual(rule, want) {
+		t.Errorf("NewAbortIncompleteMultipartUploadRule returned %+v, want %+v", rule, want)
+	}
+}
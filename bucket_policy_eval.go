@@ -0,0 +1,366 @@
+package cos
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PolicyDecision 存储桶策略的鉴权结果
+type PolicyDecision string
+
+const (
+	// 没有匹配的 allow 语句，默认拒绝
+	PolicyImplicitDeny PolicyDecision = "ImplicitDeny"
+	PolicyAllow        PolicyDecision = "Allow"
+	// 匹配了 deny 语句，deny 优先于 allow
+	PolicyExplicitDeny PolicyDecision = "ExplicitDeny"
+)
+
+// PolicyRequest 待鉴权的访问请求
+type PolicyRequest struct {
+	// 访问者，如 qcs::cam::uin/100000000001:uin/100000000011，匿名访问为 *
+	Principal string
+	// 操作，如 name/cos:GetObject
+	Action string
+	// 资源，如 qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/folder/file.txt
+	Resource string
+	// 请求上下文，分别对应条件键 qcs:ip、cos:referer(qcs:referer)、cos:<头部名称>、qcs:current_time
+	SourceIP string
+	Referer  string
+	Headers  http.Header
+	// 为零值时使用当前时间
+	Time time.Time
+	// 其它条件键的值，如 cos:prefix，优先于上面的字段
+	Context map[string][]string
+}
+
+// PolicyEvaluation 是 EvaluatePolicy 的结果
+type PolicyEvaluation struct {
+	Decision PolicyDecision
+	// 决定结果的语句，ImplicitDeny 时为空
+	Sid       string
+	Statement *BucketStatement
+}
+
+// EvaluatePolicy 在本地按存储桶策略对请求鉴权：匹配的 deny 语句优先，其次为 allow，都不匹配时为 ImplicitDeny。
+// 支持 Principal、Action、Resource 的 * 与 ? 通配，以及常用的条件操作符(string_*、numeric_*、date_*、ip_*、bool_equal、null_equal)，
+// 操作符可以带 _if_exist 后缀与 for_any_value:、for_all_value: 前缀
+func EvaluatePolicy(policy *BucketPutPolicyOptions, req *PolicyRequest) (*PolicyEvaluation, error) {
+	res := &PolicyEvaluation{Decision: PolicyImplicitDeny}
+	if policy == nil || req == nil {
+		return res, nil
+	}
+	for i := range policy.Statement {
+		st := &policy.Statement[i]
+		principal := st.Principal
+		if len(principal) == 0 {
+			principal = policy.Principal
+		}
+		if !matchPolicyPrincipal(principal, req.Principal) ||
+			!matchPolicyAction(st.Action, req.Action) ||
+			!matchPolicyResource(st.Resource, req.Resource) {
+			continue
+		}
+		ok, err := matchPolicyCondition(st.Condition, req)
+		if err != nil {
+			return nil, fmt.Errorf("statement %v: %v", st.Sid, err)
+		}
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(st.Effect) {
+		case "deny":
+			return &PolicyEvaluation{Decision: PolicyExplicitDeny, Sid: st.Sid, Statement: st}, nil
+		case "allow":
+			if res.Decision != PolicyAllow {
+				res = &PolicyEvaluation{Decision: PolicyAllow, Sid: st.Sid, Statement: st}
+			}
+		default:
+			return nil, fmt.Errorf("statement %v: invalid effect %v", st.Sid, st.Effect)
+		}
+	}
+	return res, nil
+}
+
+// wildcardMatch 大小写敏感的 * 与 ? 通配匹配
+func wildcardMatch(pattern, s string) bool {
+	p, n := 0, 0
+	star, match := -1, 0
+	for n < len(s) {
+		if p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[n]) {
+			p++
+			n++
+		} else if p < len(pattern) && pattern[p] == '*' {
+			star, match = p, n
+			p++
+		} else if star >= 0 {
+			p = star + 1
+			match++
+			n = match
+		} else {
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+func matchPolicyPrincipal(principal map[string][]string, who string) bool {
+	for _, ps := range principal {
+		for _, p := range ps {
+			if p == "*" || p == "qcs::cam::anyone:anyone" || wildcardMatch(p, who) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchPolicyAction(actions []string, action string) bool {
+	action = strings.ToLower(strings.TrimPrefix(action, "name/"))
+	for _, a := range actions {
+		a = strings.ToLower(strings.TrimPrefix(a, "name/"))
+		if a == "*" || wildcardMatch(a, action) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPolicyResource(resources []string, resource string) bool {
+	for _, r := range resources {
+		if wildcardMatch(r, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyContextValues 返回条件键在请求中的值
+func policyContextValues(req *PolicyRequest, key string) ([]string, bool) {
+	key = strings.ToLower(key)
+	for k, v := range req.Context {
+		if strings.ToLower(k) == key {
+			return v, true
+		}
+	}
+	switch key {
+	case "qcs:ip", "cos:ip":
+		if req.SourceIP != "" {
+			return []string{req.SourceIP}, true
+		}
+	case "qcs:referer", "cos:referer":
+		if req.Referer != "" {
+			return []string{req.Referer}, true
+		}
+	case "qcs:current_time":
+		t := req.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		return []string{t.Format(time.RFC3339)}, true
+	default:
+		if strings.HasPrefix(key, "cos:") && req.Headers != nil {
+			if v, ok := req.Headers[http.CanonicalHeaderKey(key[len("cos:"):])]; ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// policyConditionValues 条件值可以是单个值或数组
+func policyConditionValues(v interface{}) []string {
+	switch v := v.(type) {
+	case []interface{}:
+		var res []string
+		for _, e := range v {
+			res = append(res, fmt.Sprint(e))
+		}
+		return res
+	case []string:
+		return v
+	case nil:
+		return nil
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func matchPolicyCondition(cond map[string]map[string]interface{}, req *PolicyRequest) (bool, error) {
+	for op, kvs := range cond {
+		for key, value := range kvs {
+			ok, err := evalPolicyCondition(op, key, policyConditionValues(value), req)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+func evalPolicyCondition(op, key string, want []string, req *PolicyRequest) (bool, error) {
+	op = strings.ToLower(op)
+	set := ""
+	if i := strings.Index(op, ":"); i >= 0 {
+		set, op = op[:i], op[i+1:]
+		if set != "for_any_value" && set != "for_all_value" {
+			return false, fmt.Errorf("unsupported condition set operator: %v", set)
+		}
+	}
+	ifExist := strings.HasSuffix(op, "_if_exist")
+	op = strings.TrimSuffix(op, "_if_exist")
+
+	got, exist := policyContextValues(req, key)
+	if op == "null_equal" {
+		for _, w := range want {
+			if strings.EqualFold(w, "true") != exist {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	negate := strings.Contains(op, "_not_")
+	if !exist {
+		// 与 CAM 一致: 键不存在时，_if_exist 与 for_all_value 成立，for_any_value 不成立，
+		// 其它取反的操作符(如 string_not_equal)成立
+		switch {
+		case ifExist || set == "for_all_value":
+			return true, nil
+		case set == "for_any_value":
+			return false, nil
+		}
+		return negate, nil
+	}
+
+	base := strings.Replace(op, "_not_", "_", 1)
+	cmp, err := policyConditionComparator(base)
+	if err != nil {
+		return false, err
+	}
+	// 请求中的单个值是否满足条件: 匹配任意一个条件值，取反的操作符要求不匹配所有条件值
+	matchOne := func(g string) (bool, error) {
+		for _, w := range want {
+			ok, err := cmp(g, w)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return !negate, nil
+			}
+		}
+		return negate, nil
+	}
+	for _, g := range got {
+		ok, err := matchOne(g)
+		if err != nil {
+			return false, err
+		}
+		if set == "for_all_value" && !ok {
+			return false, nil
+		}
+		if set != "for_all_value" && ok {
+			return true, nil
+		}
+	}
+	return set == "for_all_value", nil
+}
+
+func policyConditionComparator(op string) (func(got, want string) (bool, error), error) {
+	switch op {
+	case "string_equal":
+		return func(g, w string) (bool, error) { return g == w, nil }, nil
+	case "string_equal_ignore_case":
+		return func(g, w string) (bool, error) { return strings.EqualFold(g, w), nil }, nil
+	case "string_like":
+		return func(g, w string) (bool, error) { return wildcardMatch(w, g), nil }, nil
+	case "bool_equal":
+		return func(g, w string) (bool, error) { return strings.EqualFold(g, w), nil }, nil
+	case "ip_equal":
+		return matchPolicyIP, nil
+	}
+	if strings.HasPrefix(op, "numeric_") {
+		rel := strings.TrimPrefix(op, "numeric_")
+		return func(g, w string) (bool, error) {
+			gv, err := strconv.ParseFloat(g, 64)
+			if err != nil {
+				return false, nil
+			}
+			wv, err := strconv.ParseFloat(w, 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid numeric condition value: %v", w)
+			}
+			return compareRelation(rel, gv-wv)
+		}, nil
+	}
+	if strings.HasPrefix(op, "date_") {
+		rel := strings.TrimPrefix(op, "date_")
+		return func(g, w string) (bool, error) {
+			gt, err := parsePolicyTime(g)
+			if err != nil {
+				return false, nil
+			}
+			wt, err := parsePolicyTime(w)
+			if err != nil {
+				return false, fmt.Errorf("invalid date condition value: %v", w)
+			}
+			return compareRelation(rel, float64(gt.Sub(wt)))
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported condition operator: %v", op)
+}
+
+// compareRelation 按 equal、less_than、greater_than 等关系比较差值 d = got - want
+func compareRelation(rel string, d float64) (bool, error) {
+	switch rel {
+	case "equal":
+		return d == 0, nil
+	case "less_than":
+		return d < 0, nil
+	case "less_than_equal":
+		return d <= 0, nil
+	case "greater_than":
+		return d > 0, nil
+	case "greater_than_equal":
+		return d >= 0, nil
+	}
+	return false, fmt.Errorf("unsupported condition relation: %v", rel)
+}
+
+// parsePolicyTime 支持 ISO8601 时间与 Unix 时间戳(秒)
+func parsePolicyTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// matchPolicyIP 条件值可以是 IP 或 CIDR
+func matchPolicyIP(got, want string) (bool, error) {
+	ip := net.ParseIP(got)
+	if ip == nil {
+		return false, nil
+	}
+	if strings.Contains(want, "/") {
+		_, ipnet, err := net.ParseCIDR(want)
+		if err != nil {
+			return false, fmt.Errorf("invalid ip condition value: %v", want)
+		}
+		return ipnet.Contains(ip), nil
+	}
+	wip := net.ParseIP(want)
+	if wip == nil {
+		return false, fmt.Errorf("invalid ip condition value: %v", want)
+	}
+	return wip.Equal(ip), nil
+}
@@ -0,0 +1,184 @@
+package cos
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const testEvalPolicy = `{
+	"version": "2.0",
+	"statement": [
+		{
+			"sid": "allow-read",
+			"principal": {"qcs": ["*"]},
+			"effect": "allow",
+			"action": ["name/cos:GetObject", "name/cos:HeadObject"],
+			"resource": ["qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/public/*"],
+			"condition": {
+				"string_like_if_exist": {"cos:referer": ["*.example.com*"]}
+			}
+		},
+		{
+			"sid": "allow-office-write",
+			"principal": {"qcs": ["qcs::cam::uin/100000000001:uin/*"]},
+			"effect": "allow",
+			"action": ["name/cos:Put*"],
+			"resource": ["qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/*"],
+			"condition": {
+				"ip_equal": {"qcs:ip": ["10.0.0.0/8", "192.168.1.1"]},
+				"date_less_than": {"qcs:current_time": "2026-12-31T00:00:00Z"}
+			}
+		},
+		{
+			"sid": "deny-secret",
+			"principal": {"qcs": ["*"]},
+			"effect": "deny",
+			"action": ["*"],
+			"resource": ["qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/public/secret/*"]
+		},
+		{
+			"sid": "deny-insecure",
+			"principal": {"qcs": ["*"]},
+			"effect": "deny",
+			"action": ["name/cos:PutObject"],
+			"resource": ["*"],
+			"condition": {
+				"string_not_equal": {"cos:x-cos-acl": "private"},
+				"numeric_greater_than": {"cos:content-length": 1048576}
+			}
+		}
+	]
+}`
+
+func TestEvaluatePolicy(t *testing.T) {
+	var policy BucketPutPolicyOptions
+	if err := json.Unmarshal([]byte(testEvalPolicy), &policy); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	resource := "qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/"
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		req      PolicyRequest
+		decision PolicyDecision
+		sid      string
+	}{
+		{PolicyRequest{Principal: "*", Action: "name/cos:GetObject", Resource: resource + "public/a.txt"}, PolicyAllow, "allow-read"},
+		{PolicyRequest{Principal: "*", Action: "name/cos:GetObject", Resource: resource + "public/a.txt", Referer: "https://www.example.com/"}, PolicyAllow, "allow-read"},
+		{PolicyRequest{Principal: "*", Action: "name/cos:GetObject", Resource: resource + "public/a.txt", Referer: "https://evil.com/"}, PolicyImplicitDeny, ""},
+		{PolicyRequest{Principal: "*", Action: "name/cos:GetObject", Resource: resource + "private/a.txt"}, PolicyImplicitDeny, ""},
+		{PolicyRequest{Principal: "*", Action: "name/cos:GetObject", Resource: resource + "public/secret/a.txt"}, PolicyExplicitDeny, "deny-secret"},
+		{PolicyRequest{Principal: "*", Action: "name/cos:DeleteObject", Resource: resource + "public/a.txt"}, PolicyImplicitDeny, ""},
+		{PolicyRequest{
+			Principal: "qcs::cam::uin/100000000001:uin/100000000011",
+			Action:    "name/cos:PutObject",
+			Resource:  resource + "a.txt",
+			SourceIP:  "10.1.2.3",
+			Time:      now,
+		}, PolicyAllow, "allow-office-write"},
+		{PolicyRequest{
+			Principal: "qcs::cam::uin/100000000001:uin/100000000011",
+			Action:    "name/cos:PutObject",
+			Resource:  resource + "a.txt",
+			SourceIP:  "192.168.1.2",
+			Time:      now,
+		}, PolicyImplicitDeny, ""},
+		{PolicyRequest{
+			Principal: "qcs::cam::uin/100000000001:uin/100000000011",
+			Action:    "name/cos:PutObject",
+			Resource:  resource + "a.txt",
+			SourceIP:  "192.168.1.1",
+			Time:      now.AddDate(1, 0, 0),
+		}, PolicyImplicitDeny, ""},
+		{PolicyRequest{
+			Principal: "qcs::cam::uin/100000000001:uin/100000000011",
+			Action:    "name/cos:PutObject",
+			Resource:  resource + "a.txt",
+			SourceIP:  "10.1.2.3",
+			Time:      now,
+			Headers: http.Header{
+				"X-Cos-Acl":      []string{"public-read"},
+				"Content-Length": []string{"2097152"},
+			},
+		}, PolicyExplicitDeny, "deny-insecure"},
+		{PolicyRequest{
+			Principal: "qcs::cam::uin/100000000001:uin/100000000011",
+			Action:    "name/cos:PutObject",
+			Resource:  resource + "a.txt",
+			SourceIP:  "10.1.2.3",
+			Time:      now,
+			Headers: http.Header{
+				"X-Cos-Acl":      []string{"private"},
+				"Content-Length": []string{"2097152"},
+			},
+		}, PolicyAllow, "allow-office-write"},
+		// 请求中没有 x-cos-acl 时 string_not_equal 成立
+		{PolicyRequest{
+			Principal: "qcs::cam::uin/100000000001:uin/100000000011",
+			Action:    "name/cos:PutObject",
+			Resource:  resource + "a.txt",
+			SourceIP:  "10.1.2.3",
+			Time:      now,
+			Headers: http.Header{
+				"Content-Length": []string{"2097152"},
+			},
+		}, PolicyExplicitDeny, "deny-insecure"},
+	}
+	for i, c := range cases {
+		res, err := EvaluatePolicy(&policy, &c.req)
+		if err != nil {
+			t.Fatalf("case %v: EvaluatePolicy returned error: %v", i, err)
+		}
+		if res.Decision != c.decision || res.Sid != c.sid {
+			t.Errorf("case %v: EvaluatePolicy returned %v(%v), want %v(%v)", i, res.Decision, res.Sid, c.decision, c.sid)
+		}
+	}
+}
+
+func TestEvaluatePolicy_Condition(t *testing.T) {
+	req := &PolicyRequest{
+		Context: map[string][]string{
+			"cos:prefix": {"logs/", "data/"},
+		},
+	}
+	cases := []struct {
+		op    string
+		value interface{}
+		want  bool
+		err   bool
+	}{
+		{"for_any_value:string_equal", "logs/", true, false},
+		{"for_all_value:string_equal", []interface{}{"logs/", "data/"}, true, false},
+		{"for_all_value:string_equal", "logs/", false, false},
+		{"string_equal_ignore_case", "LOGS/", true, false},
+		{"string_not_like", "tmp/*", true, false},
+		{"null_equal", "false", true, false},
+		{"null_equal", "true", false, false},
+		{"unknown_equal", "a", false, true},
+		{"bad:string_equal", "a", false, true},
+	}
+	for _, c := range cases {
+		ok, err := evalPolicyCondition(c.op, "cos:prefix", policyConditionValues(c.value), req)
+		if (err != nil) != c.err || ok != c.want {
+			t.Errorf("evalPolicyCondition(%v, %v) returned %v, %v, want %v", c.op, c.value, ok, err, c.want)
+		}
+	}
+	missing := []struct {
+		op   string
+		want bool
+	}{
+		{"string_equal", false},
+		{"string_equal_if_exist", true},
+		{"string_not_like", true},
+		{"ip_not_equal", true},
+		{"for_any_value:string_not_equal", false},
+		{"for_all_value:string_equal", true},
+	}
+	for _, c := range missing {
+		ok, err := evalPolicyCondition(c.op, "cos:missing", []string{"a"}, req)
+		if err != nil || ok != c.want {
+			t.Errorf("evalPolicyCondition(%v) with missing key returned %v, %v, want %v", c.op, ok, err, c.want)
+		}
+	}
+}
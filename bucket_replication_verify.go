@@ -0,0 +1,276 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// 对象的跨地域复制状态，即 x-cos-replication-status 头部的值
+const (
+	ReplicationStatusPending   = "PENDING"
+	ReplicationStatusCompleted = "COMPLETED"
+	ReplicationStatusFailed    = "FAILED"
+	// 目标存储桶中由复制产生的副本
+	ReplicationStatusReplica = "REPLICA"
+)
+
+// GetReplicationStatus 通过 Head 读取对象的复制状态，对象不在复制规则范围内时为空
+func (s *ObjectService) GetReplicationStatus(ctx context.Context, name string, id ...string) (string, *Response, error) {
+	resp, err := s.Head(ctx, name, nil, id...)
+	if err != nil {
+		return "", resp, err
+	}
+	return strings.ToUpper(resp.Header.Get("x-cos-replication-status")), resp, nil
+}
+
+// VerifyReplicationOptions is the option of VerifyReplication
+type VerifyReplicationOptions struct {
+	// 目标存储桶的 Client，必填
+	Destination *Client
+	// 同时比较最新版本的版本号，要求源与目标存储桶都开启了版本控制
+	CompareVersions bool
+	// 对复制失败、缺失或不一致的对象做一次元数据不变的自身复制，重新触发复制，
+	// 大于 5GB 的对象使用分块复制；归档且未恢复的对象与 SSE-KMS 加密的对象无法复制，标记为 NotRetriggerable
+	Retrigger bool
+	// 并发 Head 与自身复制的对象数，默认为 1
+	ThreadPoolSize int
+}
+
+// 复制校验的问题类型
+const (
+	ReplicationPending    = "pending"
+	ReplicationFailed     = "failed"
+	ReplicationMissing    = "missing"
+	ReplicationMismatched = "mismatched"
+)
+
+// ReplicationIssue 未复制或复制不一致的对象
+type ReplicationIssue struct {
+	Key       string
+	VersionId string
+	Reason    string
+	// 源对象的 x-cos-replication-status
+	Status      string
+	Source      Object
+	Destination *Object
+	// 不一致的字段，如 ETag、Size、VersionId
+	Diffs       []string
+	Retriggered bool
+	// 对象处于归档存储类型或归档层且未恢复，或使用 SSE-KMS 加密，无法通过自身复制重新触发
+	NotRetriggerable bool
+	Err              error
+}
+
+// VerifyReplicationResult 是 VerifyReplication 的结果
+type VerifyReplicationResult struct {
+	// 校验的源对象数与一致的对象数
+	Checked    int
+	Replicated int
+	Pending    []ReplicationIssue
+	Failed     []ReplicationIssue
+	Missing    []ReplicationIssue
+	Mismatched []ReplicationIssue
+	// 已重新触发复制的对象数与无法重新触发的对象数
+	Retriggered      int
+	NotRetriggerable int
+}
+
+// Complete 所有对象是否都已复制且一致
+func (r *VerifyReplicationResult) Complete() bool {
+	return len(r.Pending) == 0 && len(r.Failed) == 0 && len(r.Missing) == 0 && len(r.Mismatched) == 0
+}
+
+// replicationListIterator 返回按对象键排序的当前对象，CompareVersions 时包含最新版本的版本号
+func replicationListIterator(ctx context.Context, client *Client, prefix string, versions bool) ObjectIterator {
+	s := &ObjectService{client: client}
+	if !versions {
+		return s.newObjectListIterator(ctx, prefix)
+	}
+	vit := s.newVersionIterator(ctx, prefix)
+	return ObjectIteratorFunc(func() (Object, error) {
+		for {
+			v, err := vit.next()
+			if err != nil {
+				return Object{}, err
+			}
+			if v.IsLatest && !v.IsDeleteMarker {
+				return Object{Key: v.Key, VersionId: v.VersionId, ETag: v.ETag, Size: v.Size, StorageClass: v.StorageClass}, nil
+			}
+		}
+	})
+}
+
+// VerifyReplication 比较源存储桶与 opt.Destination 中 prefix 下的对象的 ETag、大小(及版本号)，
+// 对缺失或不一致的对象读取 x-cos-replication-status 区分复制中、复制失败与缺失，可选重新触发复制。
+// 目标存储桶中多出的对象不作为问题
+func (s *BucketService) VerifyReplication(ctx context.Context, prefix string, opt *VerifyReplicationOptions) (*VerifyReplicationResult, error) {
+	if opt == nil || opt.Destination == nil {
+		return nil, errors.New("destination client is required")
+	}
+	poolSize := opt.ThreadPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+	src := replicationListIterator(ctx, s.client, prefix, opt.CompareVersions)
+	dst := replicationListIterator(ctx, opt.Destination, prefix, opt.CompareVersions)
+
+	res := &VerifyReplicationResult{}
+	var issues []*ReplicationIssue
+	d, derr := dst.Next()
+	for {
+		o, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		res.Checked++
+		for derr == nil && d.Key < o.Key {
+			d, derr = dst.Next()
+		}
+		if derr != nil && derr != io.EOF {
+			return nil, derr
+		}
+		issue := &ReplicationIssue{Key: o.Key, VersionId: o.VersionId, Source: o}
+		if derr == io.EOF || d.Key != o.Key {
+			issue.Reason = ReplicationMissing
+		} else {
+			dcopy := d
+			issue.Destination = &dcopy
+			issue.Diffs = replicationDiffs(&o, &d, opt.CompareVersions)
+			if len(issue.Diffs) == 0 {
+				res.Replicated++
+				continue
+			}
+			issue.Reason = ReplicationMismatched
+		}
+		issues = append(issues, issue)
+	}
+
+	object := &ObjectService{client: s.client}
+	jobs := make(chan *ReplicationIssue, len(issues))
+	for _, issue := range issues {
+		jobs <- issue
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for i := 0; i < poolSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for issue := range jobs {
+				object.checkReplicationIssue(ctx, issue, opt.Retrigger)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, issue := range issues {
+		if issue.Retriggered {
+			res.Retriggered++
+		}
+		if issue.NotRetriggerable {
+			res.NotRetriggerable++
+		}
+		switch issue.Reason {
+		case ReplicationPending:
+			res.Pending = append(res.Pending, *issue)
+		case ReplicationFailed:
+			res.Failed = append(res.Failed, *issue)
+		case ReplicationMissing:
+			res.Missing = append(res.Missing, *issue)
+		default:
+			res.Mismatched = append(res.Mismatched, *issue)
+		}
+	}
+	return res, nil
+}
+
+func replicationDiffs(src, dst *Object, versions bool) []string {
+	var diffs []string
+	if strings.Trim(src.ETag, "\"") != strings.Trim(dst.ETag, "\"") {
+		diffs = append(diffs, "ETag")
+	}
+	if src.Size != dst.Size {
+		diffs = append(diffs, "Size")
+	}
+	if versions && src.VersionId != dst.VersionId {
+		diffs = append(diffs, "VersionId")
+	}
+	return diffs
+}
+
+// checkReplicationIssue 读取源对象的复制状态，复制中的对象不重新触发
+func (s *ObjectService) checkReplicationIssue(ctx context.Context, issue *ReplicationIssue, retrigger bool) {
+	var id []string
+	if issue.VersionId != "" {
+		id = append(id, issue.VersionId)
+	}
+	resp, err := s.Head(ctx, issue.Key, nil, id...)
+	if err != nil {
+		issue.Err = err
+		return
+	}
+	issue.Status = strings.ToUpper(resp.Header.Get("x-cos-replication-status"))
+	switch issue.Status {
+	case ReplicationStatusPending:
+		issue.Reason = ReplicationPending
+		return
+	case ReplicationStatusFailed:
+		issue.Reason = ReplicationFailed
+	}
+	if !retrigger {
+		return
+	}
+	obj := &Object{StorageClass: resp.Header.Get("x-cos-storage-class"), StorageTier: resp.Header.Get("x-cos-storage-tier")}
+	if isArchived(obj) {
+		// 归档对象需要恢复出临时副本后才能复制
+		if status, err := ParseRestoreHeader(resp.Header.Get("x-cos-restore")); err != nil || status == nil || status.OngoingRequest {
+			issue.NotRetriggerable = true
+			return
+		}
+	}
+	if resp.Header.Get("x-cos-server-side-encryption") == "cos/kms" {
+		// Head 不返回 KMS 的加密上下文，自拷贝无法保留对象原有的 SSE-KMS 加密
+		issue.NotRetriggerable = true
+		return
+	}
+	if issue.Err = s.retriggerReplication(ctx, issue.Key, resp.Header); issue.Err == nil {
+		issue.Retriggered = true
+	}
+}
+
+// retriggerReplication 以 Replaced 方式复制对象到自身并保留原有的元数据与 SSE-COS 加密，产生新的写入以触发复制，
+// 大于 5GB 的对象由 MultiCopy 分块复制
+func (s *ObjectService) retriggerReplication(ctx context.Context, name string, header http.Header) error {
+	meta := http.Header{}
+	for k, v := range header {
+		if strings.HasPrefix(strings.ToLower(k), "x-cos-meta-") {
+			meta[k] = v
+		}
+	}
+	opt := &ObjectCopyOptions{
+		ObjectCopyHeaderOptions: &ObjectCopyHeaderOptions{
+			XCosMetadataDirective: "Replaced",
+			CacheControl:          header.Get("Cache-Control"),
+			ContentDisposition:    header.Get("Content-Disposition"),
+			ContentEncoding:       header.Get("Content-Encoding"),
+			ContentLanguage:       header.Get("Content-Language"),
+			ContentType:           header.Get("Content-Type"),
+			Expires:               header.Get("Expires"),
+			XCosStorageClass:      header.Get("x-cos-storage-class"),
+			// 不指定时自拷贝得到的对象不再加密
+			XCosServerSideEncryption: header.Get("x-cos-server-side-encryption"),
+			XCosMetaXXX:              &meta,
+		},
+	}
+	sourceURL := fmt.Sprintf("%s/%s", s.client.BaseURL.BucketURL.Host, name)
+	_, _, err := s.MultiCopy(ctx, name, sourceURL, &MultiCopyOptions{OptCopy: opt})
+	return err
+}
@@ -0,0 +1,164 @@
+package cos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestObjectService_GetReplicationStatus(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodHead)
+		w.Header().Set("x-cos-replication-status", "Completed")
+	})
+	status, _, err := client.Object.GetReplicationStatus(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("Object.GetReplicationStatus returned error: %v", err)
+	}
+	if status != ReplicationStatusCompleted {
+		t.Errorf("Object.GetReplicationStatus returned %v, want %v", status, ReplicationStatusCompleted)
+	}
+}
+
+func TestBucketService_VerifyReplication(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, `<ListBucketResult>
+	<Contents><Key>a</Key><ETag>"e1"</ETag><Size>1</Size></Contents>
+	<Contents><Key>b</Key><ETag>"e2"</ETag><Size>2</Size></Contents>
+	<Contents><Key>c</Key><ETag>"e3"</ETag><Size>3</Size></Contents>
+	<Contents><Key>d</Key><ETag>"e4"</ETag><Size>4</Size></Contents>
+	<Contents><Key>e</Key><ETag>"e5"</ETag><Size>5</Size></Contents>
+	<Contents><Key>f</Key><ETag>"e6"</ETag><Size>6</Size></Contents>
+	<Contents><Key>g</Key><ETag>"e7"</ETag><Size>6442450944</Size></Contents>
+	<Contents><Key>h</Key><ETag>"e8"</ETag><Size>8</Size></Contents>
+</ListBucketResult>`)
+	})
+	status := map[string]string{"b": "PENDING", "c": "FAILED", "d": "COMPLETED", "e": "COMPLETED", "f": "FAILED", "h": "FAILED"}
+	var mu sync.Mutex
+	copied := map[string]http.Header{}
+	for key := range status {
+		key := key
+		mux.HandleFunc("/"+key, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.Header().Set("x-cos-replication-status", status[key])
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("x-cos-meta-owner", "alice")
+				switch key {
+				case "c":
+					w.Header().Set("x-cos-server-side-encryption", "AES256")
+				case "f":
+					w.Header().Set("x-cos-storage-class", "ARCHIVE")
+				case "h":
+					w.Header().Set("x-cos-server-side-encryption", "cos/kms")
+				}
+				return
+			}
+			testMethod(t, r, http.MethodPut)
+			mu.Lock()
+			copied[key] = r.Header
+			mu.Unlock()
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"e"</ETag></CopyObjectResult>`)
+		})
+	}
+
+	// 大于 5GB 的对象分块复制
+	var initHeader http.Header
+	var parts int
+	mux.HandleFunc("/g", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodHead:
+			w.Header().Set("Content-Length", "6442450944")
+			w.Header().Set("x-cos-meta-owner", "alice")
+			w.Header().Set("x-cos-server-side-encryption", "AES256")
+		case r.Method == http.MethodPost && r.URL.RawQuery == "uploads":
+			initHeader = r.Header
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>u1</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut && q.Get("partNumber") != "":
+			mu.Lock()
+			parts++
+			mu.Unlock()
+			fmt.Fprint(w, `<CopyPartResult><ETag>"p"</ETag></CopyPartResult>`)
+		case r.Method == http.MethodPost && q.Get("uploadId") == "u1":
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"g"</ETag></CompleteMultipartUploadResult>`)
+		default:
+			t.Errorf("unexpected request %v %v", r.Method, r.URL)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	dstMux := http.NewServeMux()
+	dstServer := httptest.NewServer(dstMux)
+	defer dstServer.Close()
+	dstMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<ListBucketResult>
+	<Contents><Key>0</Key><ETag>"e0"</ETag><Size>0</Size></Contents>
+	<Contents><Key>a</Key><ETag>"e1"</ETag><Size>1</Size></Contents>
+	<Contents><Key>e</Key><ETag>"e5"</ETag><Size>50</Size></Contents>
+</ListBucketResult>`)
+	})
+	u, _ := url.Parse(dstServer.URL)
+	dst := NewClient(&BaseURL{BucketURL: u}, nil)
+
+	res, err := client.Bucket.VerifyReplication(context.Background(), "", &VerifyReplicationOptions{
+		Destination:    dst,
+		Retrigger:      true,
+		ThreadPoolSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("Bucket.VerifyReplication returned error: %v", err)
+	}
+	keys := func(issues []ReplicationIssue) []string {
+		var res []string
+		for _, i := range issues {
+			res = append(res, i.Key)
+		}
+		return res
+	}
+	if res.Checked != 8 || res.Replicated != 1 || res.Complete() {
+		t.Errorf("Bucket.VerifyReplication returned %+v", res)
+	}
+	if !reflect.DeepEqual(keys(res.Pending), []string{"b"}) ||
+		!reflect.DeepEqual(keys(res.Failed), []string{"c", "f", "h"}) ||
+		!reflect.DeepEqual(keys(res.Missing), []string{"d", "g"}) ||
+		!reflect.DeepEqual(keys(res.Mismatched), []string{"e"}) {
+		t.Errorf("Bucket.VerifyReplication returned pending %v, failed %v, missing %v, mismatched %v",
+			keys(res.Pending), keys(res.Failed), keys(res.Missing), keys(res.Mismatched))
+	}
+	if !reflect.DeepEqual(res.Mismatched[0].Diffs, []string{"Size"}) {
+		t.Errorf("mismatched diffs is %v, want [Size]", res.Mismatched[0].Diffs)
+	}
+	if res.Retriggered != 4 || len(copied) != 3 || copied["b"] != nil || copied["f"] != nil {
+		t.Errorf("Bucket.VerifyReplication retriggered %v, copied %v", res.Retriggered, len(copied))
+	}
+	if res.NotRetriggerable != 2 || !res.Failed[1].NotRetriggerable || res.Failed[1].Err != nil {
+		t.Errorf("archived object should not be retriggerable: %+v", res.Failed[1])
+	}
+	if !res.Failed[2].NotRetriggerable || copied["h"] != nil {
+		t.Errorf("SSE-KMS object should not be retriggerable: %+v", res.Failed[2])
+	}
+	if parts == 0 || initHeader.Get("x-cos-meta-owner") != "alice" || initHeader.Get("x-cos-server-side-encryption") != "AES256" {
+		t.Errorf("large object copied with %v parts, initiate header %v", parts, initHeader)
+	}
+	if h := copied["c"]; h.Get("x-cos-metadata-directive") != "Replaced" ||
+		h.Get("x-cos-meta-owner") != "alice" || h.Get("Content-Type") != "text/plain" ||
+		h.Get("x-cos-server-side-encryption") != "AES256" {
+		t.Errorf("self copy header is %v", h)
+	}
+
+	if _, err := client.Bucket.VerifyReplication(context.Background(), "", nil); err == nil {
+		t.Errorf("Bucket.VerifyReplication should fail without destination")
+	}
+}
@@ -0,0 +1,427 @@
+package cos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 安全检查结果的严重程度
+const (
+	SeverityHigh   = "HIGH"
+	SeverityMedium = "MEDIUM"
+	SeverityLow    = "LOW"
+)
+
+// 安全检查规则 ID，保持稳定以便于告警与豁免
+const (
+	RulePolicyAnonymousWrite        = "COS-POLICY-001"
+	RulePolicyAnonymousRead         = "COS-POLICY-002"
+	RulePolicyUnconditionalDelete   = "COS-POLICY-003"
+	RulePolicyWildcardAll           = "COS-POLICY-004"
+	RuleACLPublicWrite              = "COS-ACL-001"
+	RuleACLPublicRead               = "COS-ACL-002"
+	RuleCORSWildcardCredentials     = "COS-CORS-001"
+	RuleCORSWildcardWrite           = "COS-CORS-002"
+	RuleRefererEmptyWhitelist       = "COS-REFERER-001"
+	RuleRefererAllowEmpty           = "COS-REFERER-002"
+	RuleWebsitePublicWithoutHTTPS   = "COS-WEBSITE-001"
+	RuleWebsiteWithoutErrorDocument = "COS-WEBSITE-002"
+)
+
+// SecurityFinding 一条安全检查结果
+type SecurityFinding struct {
+	RuleID   string `json:"ruleId"`
+	Severity string `json:"severity"`
+	// 问题所在的配置，如 policy、acl、cors、referer、website
+	Resource string `json:"resource"`
+	// 问题所在的策略语句 Sid、CORS 规则 ID 等，可为空
+	Location    string `json:"location,omitempty"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation"`
+}
+
+// BucketSecurityConfig 参与安全检查的存储桶配置，未设置的配置为 nil
+type BucketSecurityConfig struct {
+	Policy  *BucketGetPolicyResult
+	ACL     *BucketGetACLResult
+	Referer *BucketGetRefererResult
+	CORS    *BucketGetCORSResult
+	Website *BucketGetWebsiteResult
+}
+
+// SecurityReport 是 AnalyzeSecurity 的结果
+type SecurityReport struct {
+	Bucket   string            `json:"bucket"`
+	Findings []SecurityFinding `json:"findings"`
+	// 获取失败的配置及原因，这些配置未参与检查
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// JSON 以 JSON 格式输出检查结果
+func (r *SecurityReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+var severityOrder = map[string]int{
+	SeverityHigh:   0,
+	SeverityMedium: 1,
+	SeverityLow:    2,
+}
+
+// AnalyzeSecurity 获取存储桶的 Policy、ACL、Referer、CORS 与 Website 配置并进行安全检查，
+// 未设置的配置不参与检查，获取失败的配置记录在 SecurityReport.Errors 中
+func (s *BucketService) AnalyzeSecurity(ctx context.Context) (*SecurityReport, error) {
+	cfg := &BucketSecurityConfig{}
+	report := &SecurityReport{Bucket: s.client.BaseURL.BucketURL.Host}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	fetch := func(name string, get func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := get(); err != nil && !IsNotFoundError(err) {
+				mu.Lock()
+				if report.Errors == nil {
+					report.Errors = map[string]string{}
+				}
+				report.Errors[name] = err.Error()
+				mu.Unlock()
+			}
+		}()
+	}
+	fetch("policy", func() error {
+		res, _, err := s.GetPolicy(ctx)
+		if err == nil {
+			cfg.Policy = res
+		}
+		return err
+	})
+	fetch("acl", func() error {
+		res, _, err := s.GetACL(ctx)
+		if err == nil {
+			cfg.ACL = res
+		}
+		return err
+	})
+	fetch("referer", func() error {
+		res, _, err := s.GetReferer(ctx)
+		if err == nil {
+			cfg.Referer = res
+		}
+		return err
+	})
+	fetch("cors", func() error {
+		res, _, err := s.GetCORS(ctx)
+		if err == nil {
+			cfg.CORS = res
+		}
+		return err
+	})
+	fetch("website", func() error {
+		res, _, err := s.GetWebsite(ctx)
+		if err == nil {
+			cfg.Website = res
+		}
+		return err
+	})
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	report.Findings = LintBucketSecurity(cfg)
+	return report, nil
+}
+
+// LintBucketSecurity 对存储桶配置进行安全检查，结果按严重程度与规则 ID 排序
+func LintBucketSecurity(cfg *BucketSecurityConfig) []SecurityFinding {
+	findings := []SecurityFinding{}
+	if cfg == nil {
+		return findings
+	}
+	findings = append(findings, lintPolicy(cfg.Policy)...)
+	findings = append(findings, lintACL(cfg.ACL)...)
+	findings = append(findings, lintCORS(cfg.CORS)...)
+	findings = append(findings, lintReferer(cfg.Referer)...)
+	findings = append(findings, lintWebsite(cfg.Website, isPubliclyReadable(cfg))...)
+	sort.SliceStable(findings, func(i, j int) bool {
+		if severityOrder[findings[i].Severity] != severityOrder[findings[j].Severity] {
+			return severityOrder[findings[i].Severity] < severityOrder[findings[j].Severity]
+		}
+		return findings[i].RuleID < findings[j].RuleID
+	})
+	return findings
+}
+
+// 用于判断策略中的操作是否包含写操作、删除操作
+var (
+	writeActions = []string{
+		"cos:putobject", "cos:postobject", "cos:appendobject", "cos:deleteobject", "cos:deletemultipleobjects",
+		"cos:initiatemultipartupload", "cos:uploadpart", "cos:completemultipartupload", "cos:putobjectacl",
+		"cos:putbucketacl", "cos:putbucketpolicy", "cos:deletebucket", "cos:deletebucketpolicy",
+	}
+	deleteActions = []string{
+		"cos:deleteobject", "cos:deletemultipleobjects", "cos:deletebucket", "cos:deletebucketpolicy",
+	}
+)
+
+func statementMatchesAny(st *BucketStatement, samples []string) bool {
+	for _, a := range st.Action {
+		a = strings.ToLower(strings.TrimPrefix(a, "name/"))
+		for _, sample := range samples {
+			if a == "*" || wildcardMatch(a, sample) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isAnonymousPrincipal(principal map[string][]string) bool {
+	for _, ps := range principal {
+		for _, p := range ps {
+			if p == "*" || p == "qcs::cam::anyone:anyone" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func lintPolicy(policy *BucketGetPolicyResult) []SecurityFinding {
+	var findings []SecurityFinding
+	if policy == nil {
+		return findings
+	}
+	for i := range policy.Statement {
+		st := &policy.Statement[i]
+		if !strings.EqualFold(st.Effect, "allow") {
+			continue
+		}
+		principal := st.Principal
+		if len(principal) == 0 {
+			principal = policy.Principal
+		}
+		anonymous := isAnonymousPrincipal(principal)
+		unconditional := len(st.Condition) == 0
+		if anonymous && statementMatchesAny(st, writeActions) {
+			findings = append(findings, SecurityFinding{
+				RuleID:      RulePolicyAnonymousWrite,
+				Severity:    SeverityHigh,
+				Resource:    "policy",
+				Location:    st.Sid,
+				Message:     "bucket policy allows anonymous principal to perform write actions",
+				Remediation: "restrict the principal to specific CAM users or roles, or remove write actions from the statement",
+			})
+		} else if anonymous && unconditional {
+			findings = append(findings, SecurityFinding{
+				RuleID:      RulePolicyAnonymousRead,
+				Severity:    SeverityMedium,
+				Resource:    "policy",
+				Location:    st.Sid,
+				Message:     "bucket policy allows anonymous access without any condition",
+				Remediation: "add conditions such as qcs:ip or cos:referer, or restrict the principal",
+			})
+		}
+		if unconditional && !anonymous && statementMatchesAny(st, deleteActions) {
+			findings = append(findings, SecurityFinding{
+				RuleID:      RulePolicyUnconditionalDelete,
+				Severity:    SeverityMedium,
+				Resource:    "policy",
+				Location:    st.Sid,
+				Message:     "bucket policy allows delete actions without any condition",
+				Remediation: "add a condition (e.g. qcs:ip, qcs:secure_transport) to statements granting delete actions",
+			})
+		}
+		if action := broadWildcardAction(st); action != "" {
+			for _, r := range st.Resource {
+				if r == "*" {
+					findings = append(findings, SecurityFinding{
+						RuleID:      RulePolicyWildcardAll,
+						Severity:    SeverityHigh,
+						Resource:    "policy",
+						Location:    st.Sid,
+						Message:     fmt.Sprintf("bucket policy allows wildcard action %v on all resources", action),
+						Remediation: "grant only the actions and resources required (least privilege)",
+					})
+					break
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// broadWildcardAction 返回语句中匹配所有操作(如 *、cos:*)或匹配写、删除操作(如 name/cos:Delete*)的通配操作
+func broadWildcardAction(st *BucketStatement) string {
+	for _, a := range st.Action {
+		if !strings.Contains(a, "*") {
+			continue
+		}
+		if matchPolicyAction([]string{a}, "cos:*") {
+			return a
+		}
+		for _, w := range writeActions {
+			if matchPolicyAction([]string{a}, w) {
+				return a
+			}
+		}
+	}
+	return ""
+}
+
+func isPublicGrantee(g *ACLGrantee) bool {
+	return g != nil && (g.ID == "qcs::cam::anyone:anyone" || g.URI == "http://cam.qcloud.com/groups/global/AllUsers")
+}
+
+func lintACL(acl *BucketGetACLResult) []SecurityFinding {
+	var findings []SecurityFinding
+	if acl == nil {
+		return findings
+	}
+	var read, write bool
+	for _, grant := range acl.AccessControlList {
+		if !isPublicGrantee(grant.Grantee) {
+			continue
+		}
+		switch grant.Permission {
+		case "WRITE", "WRITE_ACP", "FULL_CONTROL":
+			write = true
+		case "READ", "READ_ACP":
+			read = true
+		}
+	}
+	if write {
+		findings = append(findings, SecurityFinding{
+			RuleID:      RuleACLPublicWrite,
+			Severity:    SeverityHigh,
+			Resource:    "acl",
+			Message:     "bucket ACL grants write permission to all users (public-read-write)",
+			Remediation: "set the bucket ACL to private and grant access through bucket policy",
+		})
+	} else if read {
+		findings = append(findings, SecurityFinding{
+			RuleID:      RuleACLPublicRead,
+			Severity:    SeverityMedium,
+			Resource:    "acl",
+			Message:     "bucket ACL grants read permission to all users (public-read)",
+			Remediation: "set the bucket ACL to private, or enable referer protection if public read is required",
+		})
+	}
+	return findings
+}
+
+func lintCORS(cors *BucketGetCORSResult) []SecurityFinding {
+	var findings []SecurityFinding
+	if cors == nil {
+		return findings
+	}
+	for _, rule := range cors.Rules {
+		if !containsFold(rule.AllowedOrigins, "*") {
+			continue
+		}
+		if containsFold(rule.AllowedHeaders, "*") || containsFold(rule.AllowedHeaders, "Authorization") {
+			findings = append(findings, SecurityFinding{
+				RuleID:      RuleCORSWildcardCredentials,
+				Severity:    SeverityHigh,
+				Resource:    "cors",
+				Location:    rule.ID,
+				Message:     "CORS rule allows any origin to send credentials (Authorization header)",
+				Remediation: "list the trusted origins explicitly in AllowedOrigin",
+			})
+		}
+		for _, m := range rule.AllowedMethods {
+			if m = strings.ToUpper(m); m == "PUT" || m == "POST" || m == "DELETE" {
+				findings = append(findings, SecurityFinding{
+					RuleID:      RuleCORSWildcardWrite,
+					Severity:    SeverityLow,
+					Resource:    "cors",
+					Location:    rule.ID,
+					Message:     fmt.Sprintf("CORS rule allows any origin to use %v", m),
+					Remediation: "list the trusted origins explicitly in AllowedOrigin for write methods",
+				})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+func lintReferer(referer *BucketGetRefererResult) []SecurityFinding {
+	var findings []SecurityFinding
+	if referer == nil || !strings.EqualFold(referer.Status, "Enabled") {
+		return findings
+	}
+	if strings.EqualFold(referer.RefererType, "White-List") && len(referer.DomainList) == 0 {
+		findings = append(findings, SecurityFinding{
+			RuleID:      RuleRefererEmptyWhitelist,
+			Severity:    SeverityMedium,
+			Resource:    "referer",
+			Message:     "referer protection is enabled with an empty whitelist",
+			Remediation: "add the trusted domains to DomainList, or disable referer protection",
+		})
+	}
+	if strings.EqualFold(referer.RefererType, "White-List") && strings.EqualFold(referer.EmptyReferConfiguration, "Allow") {
+		findings = append(findings, SecurityFinding{
+			RuleID:      RuleRefererAllowEmpty,
+			Severity:    SeverityLow,
+			Resource:    "referer",
+			Message:     "referer whitelist allows requests without Referer header",
+			Remediation: "set EmptyReferConfiguration to Deny unless direct access is required",
+		})
+	}
+	return findings
+}
+
+// isPubliclyReadable 存储桶是否可以匿名读取
+func isPubliclyReadable(cfg *BucketSecurityConfig) bool {
+	if cfg.ACL != nil {
+		for _, grant := range cfg.ACL.AccessControlList {
+			if isPublicGrantee(grant.Grantee) {
+				return true
+			}
+		}
+	}
+	if cfg.Policy != nil {
+		for i := range cfg.Policy.Statement {
+			st := &cfg.Policy.Statement[i]
+			principal := st.Principal
+			if len(principal) == 0 {
+				principal = cfg.Policy.Principal
+			}
+			if strings.EqualFold(st.Effect, "allow") && isAnonymousPrincipal(principal) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func lintWebsite(website *BucketGetWebsiteResult, public bool) []SecurityFinding {
+	var findings []SecurityFinding
+	if website == nil || website.Index == "" {
+		return findings
+	}
+	if public && (website.RedirectProtocol == nil || !strings.EqualFold(website.RedirectProtocol.Protocol, "https")) {
+		findings = append(findings, SecurityFinding{
+			RuleID:      RuleWebsitePublicWithoutHTTPS,
+			Severity:    SeverityLow,
+			Resource:    "website",
+			Message:     "public static website does not redirect requests to HTTPS",
+			Remediation: "set RedirectAllRequestsTo.Protocol to https",
+		})
+	}
+	if website.Error == nil || website.Error.Key == "" {
+		findings = append(findings, SecurityFinding{
+			RuleID:      RuleWebsiteWithoutErrorDocument,
+			Severity:    SeverityLow,
+			Resource:    "website",
+			Message:     "static website has no error document, default error pages may leak bucket details",
+			Remediation: "configure ErrorDocument.Key",
+		})
+	}
+	return findings
+}
@@ -0,0 +1,172 @@
+package cos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestLintBucketSecurity(t *testing.T) {
+	cfg := &BucketSecurityConfig{
+		Policy: &BucketGetPolicyResult{
+			Statement: []BucketStatement{
+				{
+					Sid:       "public-write",
+					Principal: map[string][]string{"qcs": {"*"}},
+					Effect:    "allow",
+					Action:    []string{"name/cos:Put*"},
+					Resource:  []string{"qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/*"},
+				},
+				{
+					Sid:       "public-read",
+					Principal: map[string][]string{"qcs": {"qcs::cam::anyone:anyone"}},
+					Effect:    "allow",
+					Action:    []string{"name/cos:GetObject"},
+					Resource:  []string{"qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/*"},
+				},
+				{
+					Sid:       "admin",
+					Principal: map[string][]string{"qcs": {"qcs::cam::uin/100000000001:uin/100000000011"}},
+					Effect:    "allow",
+					Action:    []string{"*"},
+					Resource:  []string{"*"},
+				},
+				{
+					Sid:       "ops",
+					Principal: map[string][]string{"qcs": {"qcs::cam::uin/100000000001:uin/100000000012"}},
+					Effect:    "allow",
+					Action:    []string{"cos:*"},
+					Resource:  []string{"*"},
+					Condition: map[string]map[string]interface{}{"ip_equal": {"qcs:ip": "10.0.0.0/8"}},
+				},
+				{
+					Sid:       "cleanup",
+					Principal: map[string][]string{"qcs": {"qcs::cam::uin/100000000001:uin/100000000013"}},
+					Effect:    "allow",
+					Action:    []string{"name/cos:Get*", "name/cos:Delete*"},
+					Resource:  []string{"*"},
+					Condition: map[string]map[string]interface{}{"ip_equal": {"qcs:ip": "10.0.0.0/8"}},
+				},
+				{
+					Sid:       "reader",
+					Principal: map[string][]string{"qcs": {"qcs::cam::uin/100000000001:uin/100000000014"}},
+					Effect:    "allow",
+					Action:    []string{"name/cos:Get*"},
+					Resource:  []string{"*"},
+					Condition: map[string]map[string]interface{}{"ip_equal": {"qcs:ip": "10.0.0.0/8"}},
+				},
+				{
+					Sid:       "deny",
+					Principal: map[string][]string{"qcs": {"*"}},
+					Effect:    "deny",
+					Action:    []string{"*"},
+					Resource:  []string{"*"},
+				},
+			},
+		},
+		ACL: &BucketGetACLResult{
+			AccessControlList: []ACLGrant{
+				{Grantee: &ACLGrantee{URI: "http://cam.qcloud.com/groups/global/AllUsers"}, Permission: "READ"},
+			},
+		},
+		CORS: &BucketGetCORSResult{
+			Rules: []BucketCORSRule{
+				{ID: "all", AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET", "PUT"}, AllowedHeaders: []string{"*"}},
+				{ID: "site", AllowedOrigins: []string{"https://www.example.com"}, AllowedMethods: []string{"PUT"}, AllowedHeaders: []string{"*"}},
+			},
+		},
+		Referer: &BucketGetRefererResult{
+			Status:                  "Enabled",
+			RefererType:             "White-List",
+			EmptyReferConfiguration: "Allow",
+		},
+		Website: &BucketGetWebsiteResult{Index: "index.html"},
+	}
+	findings := LintBucketSecurity(cfg)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.RuleID+":"+f.Location)
+		if f.Remediation == "" || f.Message == "" {
+			t.Errorf("finding %v has no message or remediation", f.RuleID)
+		}
+	}
+	want := []string{
+		RuleCORSWildcardCredentials + ":all",
+		RulePolicyAnonymousWrite + ":public-write",
+		RulePolicyWildcardAll + ":admin",
+		RulePolicyWildcardAll + ":ops",
+		RulePolicyWildcardAll + ":cleanup",
+		RuleACLPublicRead + ":",
+		RulePolicyAnonymousRead + ":public-read",
+		RulePolicyUnconditionalDelete + ":admin",
+		RuleRefererEmptyWhitelist + ":",
+		RuleCORSWildcardWrite + ":all",
+		RuleRefererAllowEmpty + ":",
+		RuleWebsitePublicWithoutHTTPS + ":",
+		RuleWebsiteWithoutErrorDocument + ":",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LintBucketSecurity returned\n%v\nwant\n%v", got, want)
+	}
+
+	if findings := LintBucketSecurity(&BucketSecurityConfig{}); len(findings) != 0 {
+		t.Errorf("LintBucketSecurity returned %v for empty config", findings)
+	}
+}
+
+func TestBucketService_AnalyzeSecurity(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		q := r.URL.Query()
+		switch {
+		case q["policy"] != nil:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchPolicy</Code></Error>`)
+		case q["acl"] != nil:
+			fmt.Fprint(w, `<AccessControlPolicy>
+	<Owner><ID>qcs::cam::uin/100000000001:uin/100000000001</ID></Owner>
+	<AccessControlList>
+		<Grant>
+			<Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group">
+				<URI>http://cam.qcloud.com/groups/global/AllUsers</URI>
+			</Grantee>
+			<Permission>WRITE</Permission>
+		</Grant>
+	</AccessControlList>
+</AccessControlPolicy>`)
+		case q["referer"] != nil:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code></Error>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	report, err := client.Bucket.AnalyzeSecurity(context.Background())
+	if err != nil {
+		t.Fatalf("Bucket.AnalyzeSecurity returned error: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].RuleID != RuleACLPublicWrite {
+		t.Errorf("Bucket.AnalyzeSecurity returned findings %+v", report.Findings)
+	}
+	if len(report.Errors) != 1 || report.Errors["referer"] == "" {
+		t.Errorf("Bucket.AnalyzeSecurity returned errors %+v", report.Errors)
+	}
+
+	bs, err := report.JSON()
+	if err != nil {
+		t.Fatalf("SecurityReport.JSON returned error: %v", err)
+	}
+	var decoded map[string]interface{}
+	json.Unmarshal(bs, &decoded)
+	findings, _ := decoded["findings"].([]interface{})
+	if len(findings) != 1 || findings[0].(map[string]interface{})["ruleId"] != RuleACLPublicWrite {
+		t.Errorf("SecurityReport.JSON returned %s", bs)
+	}
+}
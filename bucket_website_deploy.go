@@ -0,0 +1,338 @@
+package cos
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DeploySiteRule 按文件路径匹配的上传头部规则
+type DeploySiteRule struct {
+	// path.Match 格式的通配，不含 / 时匹配文件名，否则匹配相对 localDir 的路径，如 *.html、assets/*
+	Pattern         string
+	CacheControl    string
+	ContentEncoding string
+	ContentType     string
+}
+
+// DeploySiteOptions is the option of DeploySite
+type DeploySiteOptions struct {
+	// 站点在存储桶中的前缀，如 site/
+	Prefix string
+	// 非空时先上传到 ReleasePrefix + Release + "/"，上传完成后更新路由规则，将 Prefix 重定向到该版本，实现原子切换。
+	// 此时 Prefix 不能为空
+	Release       string
+	ReleasePrefix string
+	// 按顺序匹配，后面的规则覆盖前面规则中的同名字段
+	Rules []DeploySiteRule
+	// 需要预先 gzip 压缩的文件，格式同 DeploySiteRule.Pattern，压缩后设置 Content-Encoding: gzip
+	Compress []string
+	// 并发上传的文件数，默认为 1
+	ThreadPoolSize int
+}
+
+// DeployedFile 已上传的文件
+type DeployedFile struct {
+	Path            string
+	Key             string
+	Size            int64
+	ContentType     string
+	CacheControl    string
+	ContentEncoding string
+	Err             error
+}
+
+// DeploySiteResult is the result of DeploySite
+type DeploySiteResult struct {
+	// 文件上传到的前缀
+	Prefix string
+	Files  []DeployedFile
+	// 是否已更新路由规则切换到新版本
+	Switched bool
+}
+
+// siteMimeTypes 补充 mime.TypeByExtension 在部分系统上缺少的类型
+var siteMimeTypes = map[string]string{
+	".html":        "text/html; charset=utf-8",
+	".htm":         "text/html; charset=utf-8",
+	".css":         "text/css; charset=utf-8",
+	".js":          "text/javascript; charset=utf-8",
+	".mjs":         "text/javascript; charset=utf-8",
+	".json":        "application/json",
+	".map":         "application/json",
+	".webmanifest": "application/manifest+json",
+	".xml":         "text/xml; charset=utf-8",
+	".txt":         "text/plain; charset=utf-8",
+	".svg":         "image/svg+xml",
+	".png":         "image/png",
+	".jpg":         "image/jpeg",
+	".jpeg":        "image/jpeg",
+	".gif":         "image/gif",
+	".webp":        "image/webp",
+	".avif":        "image/avif",
+	".ico":         "image/x-icon",
+	".woff":        "font/woff",
+	".woff2":       "font/woff2",
+	".ttf":         "font/ttf",
+	".wasm":        "application/wasm",
+	".pdf":         "application/pdf",
+	".mp4":         "video/mp4",
+}
+
+// detectSiteContentType 按扩展名判断 MIME 类型，无法判断时按内容探测
+func detectSiteContentType(name string, data []byte) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if t, ok := siteMimeTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return http.DetectContentType(data)
+}
+
+// matchSitePattern 匹配 DeploySiteRule.Pattern，rel 为使用 / 分隔的相对路径
+func matchSitePattern(pattern, rel string) bool {
+	name := rel
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(rel)
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// DeploySite 将 localDir 下的静态网站上传到存储桶：按扩展名设置 Content-Type，按 Rules 设置 Cache-Control 与
+// Content-Encoding，可选预先 gzip 压缩。设置 Release 时上传到版本前缀后再更新路由规则完成切换。
+// 上传前会读取静态网站配置并检查所有路由规则的重定向目标存在(可以是本次上传的文件)，目标不存在时不上传并返回错误；
+// 有文件上传失败时不切换并返回错误
+func (s *BucketService) DeploySite(ctx context.Context, localDir string, opt *DeploySiteOptions) (*DeploySiteResult, error) {
+	if opt == nil {
+		opt = &DeploySiteOptions{}
+	}
+	poolSize := opt.ThreadPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+	res := &DeploySiteResult{Prefix: opt.Prefix}
+	if opt.Release != "" {
+		if opt.Prefix == "" {
+			return nil, errors.New("Prefix is required when Release is set")
+		}
+		releasePrefix := opt.ReleasePrefix
+		if releasePrefix == "" {
+			releasePrefix = "releases/"
+		}
+		res.Prefix = releasePrefix + strings.Trim(opt.Release, "/") + "/"
+	}
+
+	err := filepath.Walk(localDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		res.Files = append(res.Files, DeployedFile{Path: p, Key: res.Prefix + rel})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(res.Files, func(i, j int) bool { return res.Files[i].Key < res.Files[j].Key })
+	keys := make([]string, len(res.Files))
+	for i, f := range res.Files {
+		keys[i] = f.Key
+	}
+
+	// 未配置静态网站时只有 Release 需要路由规则
+	website, _, err := s.GetWebsite(ctx)
+	if err != nil && (opt.Release != "" || !IsNotFoundError(err)) {
+		return nil, err
+	}
+	var cfg *BucketPutWebsiteOptions
+	if err == nil {
+		c := BucketPutWebsiteOptions(*website)
+		cfg = &c
+		if cfg.RoutingRules == nil {
+			cfg.RoutingRules = &WebsiteRoutingRules{}
+		}
+		if opt.Release != "" {
+			switchRule := WebsiteRoutingRule{
+				ConditionPrefix:          opt.Prefix,
+				RedirectReplaceKeyPrefix: res.Prefix,
+			}
+			replaced := false
+			for i, rule := range cfg.RoutingRules.Rules {
+				if rule.ConditionErrorCode == "" && rule.ConditionPrefix == opt.Prefix {
+					switchRule.RedirectProtocol = rule.RedirectProtocol
+					cfg.RoutingRules.Rules[i] = switchRule
+					replaced = true
+				}
+			}
+			if !replaced {
+				cfg.RoutingRules.Rules = append(cfg.RoutingRules.Rules, switchRule)
+			}
+		}
+		if err = s.validateWebsiteRoutingRules(ctx, cfg.RoutingRules.Rules, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	object := &ObjectService{client: s.client}
+	jobs := make(chan *DeployedFile, len(res.Files))
+	for i := range res.Files {
+		jobs <- &res.Files[i]
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for i := 0; i < poolSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				f.Err = object.deploySiteFile(ctx, f, strings.TrimPrefix(f.Key, res.Prefix), opt)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, f := range res.Files {
+		if f.Err != nil {
+			return res, fmt.Errorf("upload %v failed: %v", f.Path, f.Err)
+		}
+	}
+	if opt.Release == "" {
+		return res, nil
+	}
+	if _, err = s.PutWebsite(ctx, cfg); err != nil {
+		return res, err
+	}
+	res.Switched = true
+	return res, nil
+}
+
+func (s *ObjectService) deploySiteFile(ctx context.Context, f *DeployedFile, rel string, opt *DeploySiteOptions) error {
+	fd, err := os.Open(f.Path)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	stat, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+	// 按内容探测类型只需要前 512 字节
+	head := make([]byte, 512)
+	n, err := io.ReadFull(fd, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if _, err = fd.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	f.ContentType = detectSiteContentType(rel, head[:n])
+	for _, rule := range opt.Rules {
+		if !matchSitePattern(rule.Pattern, rel) {
+			continue
+		}
+		if rule.CacheControl != "" {
+			f.CacheControl = rule.CacheControl
+		}
+		if rule.ContentEncoding != "" {
+			f.ContentEncoding = rule.ContentEncoding
+		}
+		if rule.ContentType != "" {
+			f.ContentType = rule.ContentType
+		}
+	}
+	// 不压缩的文件直接流式上传
+	var body io.Reader = fd
+	f.Size = stat.Size()
+	for _, pattern := range opt.Compress {
+		if matchSitePattern(pattern, rel) {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			if _, err = io.Copy(zw, fd); err != nil {
+				return err
+			}
+			if err = zw.Close(); err != nil {
+				return err
+			}
+			body = bytes.NewReader(buf.Bytes())
+			f.Size = int64(buf.Len())
+			f.ContentEncoding = "gzip"
+			break
+		}
+	}
+	_, err = s.Put(ctx, f.Key, body, &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			ContentType:     f.ContentType,
+			CacheControl:    f.CacheControl,
+			ContentEncoding: f.ContentEncoding,
+			ContentLength:   f.Size,
+		},
+	})
+	return err
+}
+
+// ValidateWebsiteRoutingRules 检查路由规则的重定向目标在存储桶中存在: ReplaceKeyWith 对象存在，
+// ReplaceKeyPrefixWith 前缀下至少有一个对象
+func (s *BucketService) ValidateWebsiteRoutingRules(ctx context.Context, rules []WebsiteRoutingRule) error {
+	return s.validateWebsiteRoutingRules(ctx, rules, nil)
+}
+
+// validateWebsiteRoutingRules uploading 为即将上传的对象，按字典序排列，视为已存在
+func (s *BucketService) validateWebsiteRoutingRules(ctx context.Context, rules []WebsiteRoutingRule, uploading []string) error {
+	object := &ObjectService{client: s.client}
+	var missing []string
+	for _, rule := range rules {
+		if key := rule.RedirectReplaceKey; key != "" && !containsSorted(uploading, key, false) {
+			_, err := object.Head(ctx, key, nil)
+			if IsNotFoundError(err) {
+				missing = append(missing, key)
+			} else if err != nil {
+				return err
+			}
+		}
+		if prefix := rule.RedirectReplaceKeyPrefix; prefix != "" && !containsSorted(uploading, prefix, true) {
+			res, _, err := s.Get(ctx, &BucketGetOptions{Prefix: prefix, MaxKeys: 1})
+			if err != nil {
+				return err
+			}
+			if len(res.Contents) == 0 {
+				missing = append(missing, prefix)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("routing rule targets not found: %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// containsSorted 有序的 keys 中是否有 key，prefix 为 true 时判断是否有以 key 为前缀的元素
+func containsSorted(keys []string, key string, prefix bool) bool {
+	i := sort.SearchStrings(keys, key)
+	if i == len(keys) {
+		return false
+	}
+	if prefix {
+		return strings.HasPrefix(keys[i], key)
+	}
+	return keys[i] == key
+}
@@ -0,0 +1,214 @@
+package cos
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestBucketService_DeploySite(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Conf.EnableCRC = false
+
+	dir, err := ioutil.TempDir("", "cos-site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"index.html":    "<html>hello</html>",
+		"assets/app.js": "console.log(1)",
+		"data.unknown1": "plain text",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	uploaded := map[string]http.Header{}
+	bodies := map[string][]byte{}
+	var website *BucketPutWebsiteOptions
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["website"]; ok {
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `<WebsiteConfiguration>
+	<IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+	<RoutingRules>
+		<RoutingRule><Condition><KeyPrefixEquals>site/</KeyPrefixEquals></Condition><Redirect><ReplaceKeyPrefixWith>releases/v1/</ReplaceKeyPrefixWith></Redirect></RoutingRule>
+		<RoutingRule><Condition><HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals></Condition><Redirect><ReplaceKeyWith>404.html</ReplaceKeyWith></Redirect></RoutingRule>
+	</RoutingRules>
+</WebsiteConfiguration>`)
+				return
+			}
+			testMethod(t, r, http.MethodPut)
+			website = &BucketPutWebsiteOptions{}
+			xml.NewDecoder(r.Body).Decode(website)
+			return
+		}
+		if r.URL.Path == "/" {
+			testMethod(t, r, http.MethodGet)
+			fmt.Fprintf(w, `<ListBucketResult><Contents><Key>%vindex.html</Key></Contents></ListBucketResult>`, r.URL.Query().Get("prefix"))
+			return
+		}
+		if r.URL.Path == "/404.html" {
+			testMethod(t, r, http.MethodHead)
+			return
+		}
+		testMethod(t, r, http.MethodPut)
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		uploaded[r.URL.Path[1:]] = r.Header
+		bodies[r.URL.Path[1:]] = body
+		mu.Unlock()
+	})
+
+	res, err := client.Bucket.DeploySite(context.Background(), dir, &DeploySiteOptions{
+		Prefix:  "site/",
+		Release: "v2",
+		Rules: []DeploySiteRule{
+			{Pattern: "*", CacheControl: "max-age=300"},
+			{Pattern: "assets/*", CacheControl: "max-age=31536000, immutable"},
+		},
+		Compress:       []string{"*.js"},
+		ThreadPoolSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("Bucket.DeploySite returned error: %v", err)
+	}
+	if res.Prefix != "releases/v2/" || !res.Switched || len(res.Files) != 3 {
+		t.Errorf("Bucket.DeploySite returned %+v", res)
+	}
+
+	html := uploaded["releases/v2/index.html"]
+	if html.Get("Content-Type") != "text/html; charset=utf-8" || html.Get("Cache-Control") != "max-age=300" {
+		t.Errorf("index.html header is %v", html)
+	}
+	js := uploaded["releases/v2/assets/app.js"]
+	if js.Get("Content-Encoding") != "gzip" || js.Get("Cache-Control") != "max-age=31536000, immutable" {
+		t.Errorf("app.js header is %v", js)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(bodies["releases/v2/assets/app.js"]))
+	if err != nil {
+		t.Fatalf("app.js is not gzip: %v", err)
+	}
+	if data, _ := ioutil.ReadAll(zr); string(data) != files["assets/app.js"] {
+		t.Errorf("app.js content is %q", data)
+	}
+	if string(bodies["releases/v2/index.html"]) != files["index.html"] {
+		t.Errorf("index.html content is %q", bodies["releases/v2/index.html"])
+	}
+	if ct := uploaded["releases/v2/data.unknown1"].Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("data.unknown1 Content-Type is %v", ct)
+	}
+
+	want := []WebsiteRoutingRule{
+		{ConditionPrefix: "site/", RedirectReplaceKeyPrefix: "releases/v2/"},
+		{ConditionErrorCode: "404", RedirectReplaceKey: "404.html"},
+	}
+	if website == nil || !reflect.DeepEqual(website.RoutingRules.Rules, want) {
+		t.Errorf("Bucket.DeploySite put website %+v, want rules %+v", website, want)
+	}
+}
+
+func TestBucketService_DeploySite_ValidateBeforeUpload(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Conf.EnableCRC = false
+
+	dir, err := ioutil.TempDir("", "cos-site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "404.html"), []byte("not found"), 0644)
+
+	rules := ""
+	var mu sync.Mutex
+	var uploaded []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["website"]; ok {
+			testMethod(t, r, http.MethodGet)
+			if rules == "" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `<Error><Code>NoSuchWebsiteConfiguration</Code></Error>`)
+				return
+			}
+			fmt.Fprintf(w, `<WebsiteConfiguration><RoutingRules>%v</RoutingRules></WebsiteConfiguration>`, rules)
+			return
+		}
+		if r.URL.Path == "/" {
+			testMethod(t, r, http.MethodGet)
+			fmt.Fprint(w, `<ListBucketResult></ListBucketResult>`)
+			return
+		}
+		testMethod(t, r, http.MethodPut)
+		mu.Lock()
+		uploaded = append(uploaded, r.URL.Path[1:])
+		mu.Unlock()
+	})
+
+	// 404 规则的目标是本次上传的文件，old/ 规则的目标不存在
+	rules = `<RoutingRule><Condition><HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals></Condition><Redirect><ReplaceKeyWith>site/404.html</ReplaceKeyWith></Redirect></RoutingRule>
+<RoutingRule><Condition><KeyPrefixEquals>old/</KeyPrefixEquals></Condition><Redirect><ReplaceKeyPrefixWith>new/</ReplaceKeyPrefixWith></Redirect></RoutingRule>`
+	_, err = client.Bucket.DeploySite(context.Background(), dir, &DeploySiteOptions{Prefix: "site/"})
+	if err == nil || err.Error() != "routing rule targets not found: new/" {
+		t.Errorf("Bucket.DeploySite returned %v", err)
+	}
+	if len(uploaded) != 0 {
+		t.Errorf("Bucket.DeploySite uploaded %v before validating", uploaded)
+	}
+
+	rules = `<RoutingRule><Condition><HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals></Condition><Redirect><ReplaceKeyWith>site/404.html</ReplaceKeyWith></Redirect></RoutingRule>`
+	if _, err = client.Bucket.DeploySite(context.Background(), dir, &DeploySiteOptions{Prefix: "site/"}); err != nil {
+		t.Errorf("Bucket.DeploySite returned error: %v", err)
+	}
+	if !reflect.DeepEqual(uploaded, []string{"site/404.html"}) {
+		t.Errorf("Bucket.DeploySite uploaded %v", uploaded)
+	}
+
+	// 未配置静态网站时只能在不切换版本时部署
+	rules, uploaded = "", nil
+	if _, err = client.Bucket.DeploySite(context.Background(), dir, &DeploySiteOptions{Prefix: "site/"}); err != nil {
+		t.Errorf("Bucket.DeploySite returned error: %v", err)
+	}
+	if _, err = client.Bucket.DeploySite(context.Background(), dir, &DeploySiteOptions{Prefix: "site/", Release: "v1"}); err == nil {
+		t.Errorf("Bucket.DeploySite should fail to release without website configuration")
+	}
+	if len(uploaded) != 1 {
+		t.Errorf("Bucket.DeploySite uploaded %v", uploaded)
+	}
+}
+
+func TestBucketService_ValidateWebsiteRoutingRules(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<ListBucketResult></ListBucketResult>`)
+	})
+	mux.HandleFunc("/missing.html", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	err := client.Bucket.ValidateWebsiteRoutingRules(context.Background(), []WebsiteRoutingRule{
+		{ConditionErrorCode: "404", RedirectReplaceKey: "missing.html"},
+		{ConditionPrefix: "old/", RedirectReplaceKeyPrefix: "new/"},
+		{ConditionPrefix: "http/", RedirectProtocol: "https"},
+	})
+	if err == nil || err.Error() != "routing rule targets not found: missing.html, new/" {
+		t.Errorf("Bucket.ValidateWebsiteRoutingRules returned %v", err)
+	}
+}
@@ -49,7 +49,7 @@ func (builder aesCtrCipherBuilder) createCipherData() (CipherData, error) {
 	return cd, nil
 }
 
-func (builder aesCtrCipherBuilder) contentCipherCD(cd CipherData) (ContentCipher, error) {
+func newAesCtrCipher(cd CipherData) (ContentCipher, error) {
 	cipher, err := newAesCtr(cd)
 	if err != nil {
 		return nil, err
@@ -66,37 +66,11 @@ func (builder aesCtrCipherBuilder) ContentCipher() (ContentCipher, error) {
 	if err != nil {
 		return nil, err
 	}
-	return builder.contentCipherCD(cd)
+	return newAesCtrCipher(cd)
 }
 
 func (builder aesCtrCipherBuilder) ContentCipherEnv(envelope Envelope) (ContentCipher, error) {
-	var cd CipherData
-	cd.EncryptedKey = make([]byte, len(envelope.CipherKey))
-	copy(cd.EncryptedKey, []byte(envelope.CipherKey))
-
-	plainKey, err := builder.MasterCipher.Decrypt([]byte(envelope.CipherKey))
-	if err != nil {
-		return nil, err
-	}
-	cd.Key = make([]byte, len(plainKey))
-	copy(cd.Key, plainKey)
-
-	cd.EncryptedIV = make([]byte, len(envelope.IV))
-	copy(cd.EncryptedIV, []byte(envelope.IV))
-
-	plainIV, err := builder.MasterCipher.Decrypt([]byte(envelope.IV))
-	if err != nil {
-		return nil, err
-	}
-
-	cd.IV = make([]byte, len(plainIV))
-	copy(cd.IV, plainIV)
-
-	cd.MatDesc = envelope.MatDesc
-	cd.WrapAlgorithm = envelope.WrapAlg
-	cd.CEKAlgorithm = envelope.CEKAlg
-
-	return builder.contentCipherCD(cd)
+	return contentCipherEnv(builder.MasterCipher, envelope)
 }
 
 func (builder aesCtrCipherBuilder) GetMatDesc() string {
@@ -126,13 +100,5 @@ func (cc *aesCtrCipher) GetAlignLen() int {
 }
 
 func (cc *aesCtrCipher) Clone(cd CipherData) (ContentCipher, error) {
-	cipher, err := newAesCtr(cd)
-	if err != nil {
-		return nil, err
-	}
-
-	return &aesCtrCipher{
-		CipherData: cd,
-		Cipher:     cipher,
-	}, nil
+	return newAesCtrCipher(cd)
 }
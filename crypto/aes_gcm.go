@@ -0,0 +1,222 @@
+package coscrypto
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	aesGcmIvSize    = 12
+	aesGcmTagSize   = 16
+	aesGcmChunkSize = 64 * 1024
+)
+
+// ErrAuthenticationFailed 解密时分块的认证标签校验失败，数据可能被篡改
+var ErrAuthenticationFailed = errors.New("coscrypto: message authentication failed")
+
+// ErrContentLengthMismatch 解密出的明文长度与元数据记录的长度不一致，密文可能在分块边界被截断
+var ErrContentLengthMismatch = errors.New("coscrypto: decrypted content length mismatch")
+
+// ErrTruncated 读取到密文末尾时没有遇到带结束标记的分块，密文在分块边界被截断
+var ErrTruncated = errors.New("coscrypto: ciphertext is truncated")
+
+// 分块的附加认证数据(AAD)，最后一个分块使用 gcmFinalChunkAAD，使密文的结束位置也受认证保护
+var (
+	gcmChunkAAD      = []byte{0}
+	gcmFinalChunkAAD = []byte{1}
+)
+
+// aesGcm 将明文按 aesGcmChunkSize 分块，每块独立使用 AES-GCM 加密并追加认证标签，
+// 第 i 块的 nonce 为 IV 的计数部分加 i，以支持按分块对齐的 Range 读取和分块上传。
+// 最后一个分块以 gcmFinalChunkAAD 认证，空明文加密为一个只有认证标签的结束分块
+type aesGcm struct {
+	aead cipher.AEAD
+	iv   []byte
+}
+
+func newAesGcm(cd CipherData) (Cipher, error) {
+	block, err := aes.NewCipher(cd.Key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCMWithTagSize(block, aesGcmTagSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(cd.IV) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid iv length:%v, want:%v", len(cd.IV), aead.NonceSize())
+	}
+	iv := make([]byte, len(cd.IV))
+	copy(iv, cd.IV)
+	return &aesGcm{aead: aead, iv: iv}, nil
+}
+
+func (c *aesGcm) counter() uint64 {
+	return binary.BigEndian.Uint64(c.iv[len(c.iv)-8:])
+}
+
+func (c *aesGcm) nonce(counter uint64) []byte {
+	nonce := make([]byte, len(c.iv))
+	copy(nonce, c.iv)
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], counter)
+	return nonce
+}
+
+func (c *aesGcm) Encrypt(src io.Reader) io.Reader {
+	return c.encrypt(src, true)
+}
+
+// encrypt final 为 false 时 src 不是明文的结尾(如非最后一个分块上传的分块)，最后一个分块不带结束标记
+func (c *aesGcm) encrypt(src io.Reader, final bool) io.Reader {
+	return &gcmEncryptReader{
+		cipher:  c,
+		src:     bufio.NewReaderSize(src, aesGcmChunkSize),
+		counter: c.counter(),
+		final:   final,
+		in:      make([]byte, aesGcmChunkSize),
+	}
+}
+
+type gcmEncryptReader struct {
+	cipher  *aesGcm
+	src     *bufio.Reader
+	counter uint64
+	final   bool
+	sealed  bool
+	in      []byte
+	out     []byte
+	buf     []byte
+	err     error
+}
+
+func (reader *gcmEncryptReader) Read(data []byte) (int, error) {
+	for len(reader.buf) == 0 {
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		n, err := io.ReadFull(reader.src, reader.in)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		if err == nil {
+			// 预读一个字节，判断当前分块是否为最后一个分块
+			if _, e := reader.src.Peek(1); e != nil {
+				err = e
+			}
+		}
+		if err != nil && err != io.EOF {
+			reader.err = err
+			continue
+		}
+		if n > 0 || (err == io.EOF && reader.final && !reader.sealed) {
+			aad := gcmChunkAAD
+			if err == io.EOF && reader.final {
+				aad = gcmFinalChunkAAD
+			}
+			reader.out = reader.cipher.aead.Seal(reader.out[:0], reader.cipher.nonce(reader.counter), reader.in[:n], aad)
+			reader.buf = reader.out
+			reader.counter++
+			reader.sealed = true
+		}
+		reader.err = err
+	}
+	n := copy(data, reader.buf)
+	reader.buf = reader.buf[n:]
+	return n, nil
+}
+
+func (c *aesGcm) Decrypt(src io.Reader) io.Reader {
+	return c.decryptRange(src, math.MaxInt64, -1)
+}
+
+// decryptRange 解密 src。读取结束时，解密出的明文不足 minLen 且没有遇到结束分块时返回 ErrTruncated，
+// 读取到对象末尾的请求 minLen 为 math.MaxInt64；plainLen 不为负数时同时校验明文长度为 plainLen
+func (c *aesGcm) decryptRange(src io.Reader, minLen, plainLen int64) io.Reader {
+	return &gcmDecryptReader{
+		cipher:  c,
+		src:     src,
+		counter: c.counter(),
+		in:      make([]byte, aesGcmChunkSize+aesGcmTagSize),
+		minLen:  minLen,
+		remain:  plainLen,
+	}
+}
+
+type gcmDecryptReader struct {
+	cipher  *aesGcm
+	src     io.Reader
+	counter uint64
+	in      []byte
+	out     []byte
+	buf     []byte
+	minLen  int64
+	// 已解密的明文长度，以及是否已解密结束分块
+	decrypted int64
+	final     bool
+	// 尚未解密的明文长度，为负数时不校验
+	remain int64
+	err    error
+}
+
+func (reader *gcmDecryptReader) Read(data []byte) (int, error) {
+	for len(reader.buf) == 0 {
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		n, err := io.ReadFull(reader.src, reader.in)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		if n > 0 && (err == nil || err == io.EOF) {
+			// 认证通过之前不返回任何明文，结束分块之后不能再有数据
+			plain, e := reader.open(reader.in[:n])
+			if e != nil {
+				err = e
+			} else {
+				reader.out = plain
+				reader.buf = plain
+				reader.counter++
+				reader.decrypted += int64(len(plain))
+				if reader.remain >= 0 {
+					if reader.remain -= int64(len(plain)); reader.remain < 0 {
+						err = ErrContentLengthMismatch
+					}
+				}
+			}
+		}
+		if err == io.EOF {
+			if reader.remain > 0 {
+				err = ErrContentLengthMismatch
+			} else if !reader.final && reader.decrypted < reader.minLen {
+				err = ErrTruncated
+			}
+		}
+		reader.err = err
+	}
+	n := copy(data, reader.buf)
+	reader.buf = reader.buf[n:]
+	return n, nil
+}
+
+// open 依次按普通分块与结束分块认证并解密一个分块
+func (reader *gcmDecryptReader) open(chunk []byte) ([]byte, error) {
+	if reader.final {
+		return nil, ErrAuthenticationFailed
+	}
+	nonce := reader.cipher.nonce(reader.counter)
+	if plain, err := reader.cipher.aead.Open(reader.out[:0], nonce, chunk, gcmChunkAAD); err == nil {
+		return plain, nil
+	}
+	plain, err := reader.cipher.aead.Open(reader.out[:0], nonce, chunk, gcmFinalChunkAAD)
+	if err != nil {
+		return nil, ErrAuthenticationFailed
+	}
+	reader.final = true
+	return plain, nil
+}
@@ -0,0 +1,120 @@
+package coscrypto
+
+import (
+	"io"
+)
+
+type aesGcmCipherBuilder struct {
+	MasterCipher MasterCipher
+}
+
+type aesGcmCipher struct {
+	CipherData CipherData
+	Cipher     Cipher
+}
+
+// CreateAesGcmBuilder 创建 AES-256-GCM 的 ContentCipherBuilder，数据按 64KB 分块认证加密，每块追加 16 字节认证标签，
+// 最后一个分块带有结束标记。使用该 Builder 分块上传时 CryptoContext.DataSize 必须设置
+func CreateAesGcmBuilder(cipher MasterCipher) ContentCipherBuilder {
+	return aesGcmCipherBuilder{MasterCipher: cipher}
+}
+
+func (builder aesGcmCipherBuilder) createCipherData() (CipherData, error) {
+	var cd CipherData
+	var err error
+	err = cd.RandomKeyIv(aesKeySize, aesGcmIvSize)
+	if err != nil {
+		return cd, err
+	}
+
+	cd.WrapAlgorithm = builder.MasterCipher.GetWrapAlgorithm()
+	cd.CEKAlgorithm = AesGcmAlgorithm
+	cd.MatDesc = builder.MasterCipher.GetMatDesc()
+
+	// EncryptedKey
+	cd.EncryptedKey, err = builder.MasterCipher.Encrypt(cd.Key)
+	if err != nil {
+		return cd, err
+	}
+
+	// EncryptedIV
+	cd.EncryptedIV, err = builder.MasterCipher.Encrypt(cd.IV)
+	if err != nil {
+		return cd, err
+	}
+
+	return cd, nil
+}
+
+func newAesGcmCipher(cd CipherData) (ContentCipher, error) {
+	cipher, err := newAesGcm(cd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesGcmCipher{
+		CipherData: cd,
+		Cipher:     cipher,
+	}, nil
+}
+
+func (builder aesGcmCipherBuilder) ContentCipher() (ContentCipher, error) {
+	cd, err := builder.createCipherData()
+	if err != nil {
+		return nil, err
+	}
+	return newAesGcmCipher(cd)
+}
+
+// ContentCipherEnv 根据 Envelope 中的 CEKAlg 选择对应的 ContentCipher，兼容 AES/CTR 加密的对象
+func (builder aesGcmCipherBuilder) ContentCipherEnv(envelope Envelope) (ContentCipher, error) {
+	return contentCipherEnv(builder.MasterCipher, envelope)
+}
+
+func (builder aesGcmCipherBuilder) GetMatDesc() string {
+	return builder.MasterCipher.GetMatDesc()
+}
+
+func (cc *aesGcmCipher) EncryptContent(src io.Reader) (io.ReadCloser, error) {
+	reader := cc.Cipher.Encrypt(src)
+	return &CryptoEncrypter{Body: src, Encrypter: reader}, nil
+}
+
+func (cc *aesGcmCipher) DecryptContent(src io.Reader) (io.ReadCloser, error) {
+	reader := cc.Cipher.Decrypt(src)
+	return &CryptoDecrypter{Body: src, Decrypter: reader}, nil
+}
+
+// encryptContentPart 加密分块上传的一个分块，final 表示是否为最后一个分块
+func (cc *aesGcmCipher) encryptContentPart(src io.Reader, final bool) io.ReadCloser {
+	reader := cc.Cipher.(*aesGcm).encrypt(src, final)
+	return &CryptoEncrypter{Body: src, Encrypter: reader}
+}
+
+// decryptContentRange 解密 Range 读取的密文，minLen 与 plainLen 的含义见 aesGcm.decryptRange
+func (cc *aesGcmCipher) decryptContentRange(src io.Reader, minLen, plainLen int64) io.ReadCloser {
+	reader := cc.Cipher.(*aesGcm).decryptRange(src, minLen, plainLen)
+	return &CryptoDecrypter{Body: src, Decrypter: reader}
+}
+
+func (cc *aesGcmCipher) GetCipherData() *CipherData {
+	return &(cc.CipherData)
+}
+
+func (cc *aesGcmCipher) GetEncryptedLen(plainTextLen int64) int64 {
+	chunks := (plainTextLen + aesGcmChunkSize - 1) / aesGcmChunkSize
+	return plainTextLen + chunks*aesGcmTagSize
+}
+
+func (cc *aesGcmCipher) getPlainTextLen(encryptedLen int64) int64 {
+	chunks := (encryptedLen + aesGcmChunkSize + aesGcmTagSize - 1) / (aesGcmChunkSize + aesGcmTagSize)
+	return encryptedLen - chunks*aesGcmTagSize
+}
+
+func (cc *aesGcmCipher) GetAlignLen() int {
+	return aesGcmChunkSize
+}
+
+func (cc *aesGcmCipher) Clone(cd CipherData) (ContentCipher, error) {
+	return newAesGcmCipher(cd)
+}
@@ -0,0 +1,251 @@
+package coscrypto_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"github.com/tencentyun/cos-go-sdk-v5/crypto"
+	"io/ioutil"
+	math_rand "math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func (s *CosTestSuite) TestCryptoObjectService_GcmEncryptAndDecrypt() {
+	var masterCipher EmptyMasterCipher
+	builder := coscrypto.CreateAesGcmBuilder(masterCipher)
+
+	contentCipher, err := builder.ContentCipher()
+	assert.Nil(s.T(), err, "CryptoObject.CreateAesGcmBuilder Failed")
+
+	dataSize := math_rand.Int63n(1024 * 1024 * 4)
+	originData := make([]byte, dataSize)
+	rand.Read(originData)
+	// 加密
+	reader1, err := contentCipher.EncryptContent(bytes.NewReader(originData))
+	assert.Nil(s.T(), err, "CryptoObject.contentCipher.Encrypt Failed")
+	encryptedData, err := ioutil.ReadAll(reader1)
+	assert.Nil(s.T(), err, "CryptoObject.Read Failed")
+	assert.Equal(s.T(), contentCipher.GetEncryptedLen(dataSize), int64(len(encryptedData)), "encrypted length is wrong")
+
+	// 解密
+	reader2, err := contentCipher.DecryptContent(bytes.NewReader(encryptedData))
+	assert.Nil(s.T(), err, "CryptoObject.contentCipher.Decrypt Failed")
+	decryptedData, err := ioutil.ReadAll(reader2)
+	assert.Nil(s.T(), err, "CryptoObject.Read Failed")
+	assert.Equal(s.T(), bytes.Compare(originData, decryptedData), 0, "decryptData != originData")
+
+	// 篡改密文
+	if len(encryptedData) > 0 {
+		encryptedData[math_rand.Intn(len(encryptedData))] ^= 0x01
+		reader3, _ := contentCipher.DecryptContent(bytes.NewReader(encryptedData))
+		_, err = ioutil.ReadAll(reader3)
+		assert.Equal(s.T(), coscrypto.ErrAuthenticationFailed, err, "tampered data should fail")
+	}
+}
+
+func (s *CosTestSuite) TestCryptoObjectService_GcmDecryptCtrEnvelope() {
+	var masterCipher EmptyMasterCipher
+	ctrCipher, err := coscrypto.CreateAesCtrBuilder(masterCipher).ContentCipher()
+	assert.Nil(s.T(), err, "CryptoObject.CreateAesCtrBuilder Failed")
+	originData := make([]byte, 1024*1024+1)
+	rand.Read(originData)
+	reader, _ := ctrCipher.EncryptContent(bytes.NewReader(originData))
+	encryptedData, _ := ioutil.ReadAll(reader)
+
+	cd := ctrCipher.GetCipherData()
+	envelope := coscrypto.Envelope{
+		IV:        string(cd.EncryptedIV),
+		CipherKey: string(cd.EncryptedKey),
+		MatDesc:   cd.MatDesc,
+		WrapAlg:   cd.WrapAlgorithm,
+		CEKAlg:    cd.CEKAlgorithm,
+	}
+	// GCM Builder 仍可解密 CTR 加密的数据
+	cc, err := coscrypto.CreateAesGcmBuilder(masterCipher).ContentCipherEnv(envelope)
+	assert.Nil(s.T(), err, "ContentCipherEnv Failed")
+	reader, _ = cc.DecryptContent(bytes.NewReader(encryptedData))
+	decryptedData, err := ioutil.ReadAll(reader)
+	assert.Nil(s.T(), err, "CryptoObject.Read Failed")
+	assert.Equal(s.T(), bytes.Compare(originData, decryptedData), 0, "decryptData != originData")
+}
+
+func (s *CosTestSuite) TestCryptoObjectService_GcmGetRange() {
+	var masterCipher EmptyMasterCipher
+	s.testGetRange(coscrypto.CreateAesGcmBuilder(masterCipher))
+	// CTR 加密的对象
+	s.testGetRange(coscrypto.CreateAesCtrBuilder(masterCipher))
+}
+
+func (s *CosTestSuite) testGetRange(builder coscrypto.ContentCipherBuilder) {
+	var masterCipher EmptyMasterCipher
+	contentCipher, err := builder.ContentCipher()
+	assert.Nil(s.T(), err, "CryptoObject.ContentCipher Failed")
+	contentLength := 1024*512 + 100
+	originData := make([]byte, contentLength)
+	rand.Read(originData)
+	reader, _ := contentCipher.EncryptContent(bytes.NewReader(originData))
+	encryptedData, _ := ioutil.ReadAll(reader)
+	cd := contentCipher.GetCipherData()
+
+	server := newEncryptedObjectServer(encryptedData, cd, int64(contentLength))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := cos.NewClient(&cos.BaseURL{BucketURL: u}, nil)
+	client.Conf.EnableCRC = false
+	cclient := coscrypto.NewCryptoClient(client, masterCipher)
+	cclient.ContentCipherBuilder = coscrypto.CreateAesGcmBuilder(masterCipher)
+
+	ranges := [][2]int{{0, 1}, {1, 100}, {65535, 65536}, {65536, 65536 + 16}, {100, contentLength - 1}, {contentLength - 10, contentLength - 1}}
+	for i := 0; i < 10; i++ {
+		start := math_rand.Intn(contentLength - 1)
+		ranges = append(ranges, [2]int{start, start + 1 + math_rand.Intn(contentLength-start-1)})
+	}
+	for _, r := range ranges {
+		opt := &cos.ObjectGetOptions{
+			Range: fmt.Sprintf("bytes=%v-%v", r[0], r[1]),
+		}
+		resp, err := cclient.Object.Get(context.Background(), "test", opt)
+		assert.Nil(s.T(), err, "GetObject Failed")
+		decryptedData, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Nil(s.T(), err, "GetObject Read Failed")
+		assert.Equal(s.T(), bytes.Compare(originData[r[0]:r[1]+1], decryptedData), 0, "decryptData != originData, range: %v", r)
+	}
+	// bytes=-N
+	resp, err := cclient.Object.Get(context.Background(), "test", &cos.ObjectGetOptions{Range: "bytes=-70000"})
+	assert.Nil(s.T(), err, "GetObject Failed")
+	decryptedData, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(s.T(), bytes.Compare(originData[contentLength-70000:], decryptedData), 0, "decryptData != originData")
+
+	resp, err = cclient.Object.Get(context.Background(), "test", nil)
+	assert.Nil(s.T(), err, "GetObject Failed")
+	decryptedData, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(s.T(), bytes.Compare(originData, decryptedData), 0, "decryptData != originData")
+}
+
+// newEncryptedObjectServer 返回一个提供加密对象下载(支持 Range)的测试服务，contentLength 为明文长度
+func newEncryptedObjectServer(encryptedData []byte, cd *coscrypto.CipherData, contentLength int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(coscrypto.COSClientSideEncryptionKey, base64.StdEncoding.EncodeToString(cd.EncryptedKey))
+		w.Header().Set(coscrypto.COSClientSideEncryptionStart, base64.StdEncoding.EncodeToString(cd.EncryptedIV))
+		w.Header().Set(coscrypto.COSClientSideEncryptionMatDesc, cd.MatDesc)
+		w.Header().Set(coscrypto.COSClientSideEncryptionWrapAlg, cd.WrapAlgorithm)
+		w.Header().Set(coscrypto.COSClientSideEncryptionCekAlg, cd.CEKAlgorithm)
+		if cd.CEKAlgorithm == coscrypto.AesGcmAlgorithm {
+			w.Header().Set(coscrypto.COSClientSideEncryptionTagLen, "128")
+		}
+		// contentLength 为负数时不记录明文长度
+		if contentLength >= 0 {
+			w.Header().Set(coscrypto.COSClientSideEncryptionUnencryptedContentLength, strconv.FormatInt(contentLength, 10))
+		}
+		http.ServeContent(w, r, "", time.Now(), bytes.NewReader(encryptedData))
+	}))
+}
+
+func (s *CosTestSuite) TestCryptoObjectService_GcmGetTruncated() {
+	var masterCipher EmptyMasterCipher
+	contentCipher, err := coscrypto.CreateAesGcmBuilder(masterCipher).ContentCipher()
+	assert.Nil(s.T(), err, "CryptoObject.ContentCipher Failed")
+	contentLength := 1024*128 + 100
+	originData := make([]byte, contentLength)
+	rand.Read(originData)
+	reader, _ := contentCipher.EncryptContent(bytes.NewReader(originData))
+	encryptedData, _ := ioutil.ReadAll(reader)
+	cd := contentCipher.GetCipherData()
+
+	// 在分块边界截断密文，或截断为空，每个剩余分块仍能通过认证
+	chunk := 64*1024 + 16
+	for _, n := range []int{chunk, 2 * chunk, 0} {
+		server := newEncryptedObjectServer(encryptedData[:n], cd, int64(contentLength))
+		u, _ := url.Parse(server.URL)
+		client := cos.NewClient(&cos.BaseURL{BucketURL: u}, nil)
+		client.Conf.EnableCRC = false
+		cclient := coscrypto.NewCryptoClient(client, masterCipher)
+
+		resp, err := cclient.Object.Get(context.Background(), "test", nil)
+		assert.Nil(s.T(), err, "GetObject Failed")
+		_, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(s.T(), coscrypto.ErrContentLengthMismatch, err, "truncated data should fail, length: %v", n)
+
+		resp, err = cclient.Object.Get(context.Background(), "test", &cos.ObjectGetOptions{Range: "bytes=100-"})
+		if err == nil {
+			_, err = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		assert.NotNil(s.T(), err, "truncated data should fail, length: %v", n)
+		server.Close()
+	}
+}
+
+func (s *CosTestSuite) TestCryptoObjectService_GcmGetTruncatedWithoutLength() {
+	var masterCipher EmptyMasterCipher
+	contentCipher, err := coscrypto.CreateAesGcmBuilder(masterCipher).ContentCipher()
+	assert.Nil(s.T(), err, "CryptoObject.ContentCipher Failed")
+	contentLength := 1024 * 128
+	originData := make([]byte, contentLength)
+	rand.Read(originData)
+	reader, _ := contentCipher.EncryptContent(bytes.NewReader(originData))
+	encryptedData, _ := ioutil.ReadAll(reader)
+	cd := contentCipher.GetCipherData()
+
+	// 没有明文长度的元数据时，由结束分块发现在分块边界的截断
+	chunk := 64*1024 + 16
+	for _, n := range []int{chunk, 0} {
+		server := newEncryptedObjectServer(encryptedData[:n], cd, -1)
+		u, _ := url.Parse(server.URL)
+		client := cos.NewClient(&cos.BaseURL{BucketURL: u}, nil)
+		client.Conf.EnableCRC = false
+		cclient := coscrypto.NewCryptoClient(client, masterCipher)
+
+		resp, err := cclient.Object.Get(context.Background(), "test", nil)
+		assert.Nil(s.T(), err, "GetObject Failed")
+		_, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(s.T(), coscrypto.ErrTruncated, err, "truncated data should fail, length: %v", n)
+
+		// 请求的范围超出了截断后的密文
+		resp, err = cclient.Object.Get(context.Background(), "test", &cos.ObjectGetOptions{Range: "bytes=0-100000"})
+		if err == nil {
+			_, err = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		assert.NotNil(s.T(), err, "truncated data should fail, length: %v", n)
+		server.Close()
+	}
+
+	// 范围在对象中间时不要求结束分块
+	server := newEncryptedObjectServer(encryptedData, cd, -1)
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := cos.NewClient(&cos.BaseURL{BucketURL: u}, nil)
+	client.Conf.EnableCRC = false
+	cclient := coscrypto.NewCryptoClient(client, masterCipher)
+	resp, err := cclient.Object.Get(context.Background(), "test", &cos.ObjectGetOptions{Range: "bytes=0-100"})
+	assert.Nil(s.T(), err, "GetObject Failed")
+	decryptedData, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Nil(s.T(), err, "GetObject Read Failed")
+	assert.Equal(s.T(), originData[:101], decryptedData, "decryptData != originData")
+
+	// 空明文加密为一个结束分块，整个删除后同样被发现
+	reader, _ = contentCipher.EncryptContent(bytes.NewReader(nil))
+	encryptedData, _ = ioutil.ReadAll(reader)
+	assert.Equal(s.T(), 16, len(encryptedData), "empty data should be encrypted to a final chunk")
+	reader2, _ := contentCipher.DecryptContent(bytes.NewReader(encryptedData))
+	decryptedData, err = ioutil.ReadAll(reader2)
+	assert.True(s.T(), err == nil && len(decryptedData) == 0, "decrypt empty data failed: %v", err)
+	reader2, _ = contentCipher.DecryptContent(bytes.NewReader(nil))
+	_, err = ioutil.ReadAll(reader2)
+	assert.Equal(s.T(), coscrypto.ErrTruncated, err, "removed data should fail")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/tencentyun/cos-go-sdk-v5"
 	"io"
+	"math"
 	"net/http"
 	"os"
 	"strconv"
@@ -21,7 +22,8 @@ type CryptoClient struct {
 	Object               *CryptoObjectService
 	ContentCipherBuilder ContentCipherBuilder
 
-	userAgent string
+	masterCipher MasterCipher
+	userAgent    string
 }
 
 func NewCryptoClient(client *cos.Client, masterCipher MasterCipher) *CryptoClient {
@@ -32,6 +34,7 @@ func NewCryptoClient(client *cos.Client, masterCipher MasterCipher) *CryptoClien
 			nil,
 		},
 		ContentCipherBuilder: CreateAesCtrBuilder(masterCipher),
+		masterCipher:         masterCipher,
 	}
 	cc.userAgent = cc.Client.UserAgent + "/" + EncryptionUaSuffix
 	cc.Object.cryptoClient = cc
@@ -108,23 +111,16 @@ func (s *CryptoObjectService) Get(ctx context.Context, name string, opt *cos.Obj
 		return s.ObjectService.Get(ctx, name, opt, id...)
 	}
 
-	envelope, err := getEnvelopeFromHeader(&meta.Header)
+	cc, err := s.cryptoClient.contentCipherFromHeader(&meta.Header)
 	if err != nil {
-		return nil, err
-	}
-	if !envelope.IsValid() {
-		return nil, fmt.Errorf("get envelope from header failed, object:%v", name)
-	}
-	encryptMatDesc := s.cryptoClient.ContentCipherBuilder.GetMatDesc()
-	if envelope.MatDesc != encryptMatDesc {
-		return nil, fmt.Errorf("provided master cipher error, want:%v, return:%v, object:%v", encryptMatDesc, envelope.MatDesc, name)
-	}
-
-	cc, err := s.cryptoClient.ContentCipherBuilder.ContentCipherEnv(envelope)
-	if err != nil {
-		return nil, fmt.Errorf("get content cipher from envelope failed: %v, object:%v", err, name)
+		return nil, fmt.Errorf("%v, object:%v", err, name)
 	}
+	return s.getWithCipher(ctx, name, opt, cc, meta, id...)
+}
 
+// getWithCipher 使用已解析的 ContentCipher 下载并解密对象，meta 为对象的 Head 结果
+func (s *CryptoObjectService) getWithCipher(ctx context.Context, name string, opt *cos.ObjectGetOptions, cc ContentCipher, meta *cos.Response, id ...string) (*cos.Response, error) {
+	var err error
 	opt = cos.CloneObjectGetOptions(opt)
 	if opt.XOptionHeader == nil {
 		opt.XOptionHeader = &http.Header{}
@@ -134,14 +130,51 @@ func (s *CryptoObjectService) Get(ctx context.Context, name string, opt *cos.Obj
 		return nil, err
 	}
 	discardAlignLen := int64(0)
+	limitLen := int64(-1)
+	// GCM 密文的最后一个分块带有结束标记，读取到对象末尾时要求遇到该分块，以发现在分块边界被截断的密文；
+	// 元数据记录了明文长度时同时校验解密出的明文长度
+	minLen := int64(math.MaxInt64)
+	plainLen, plainLenOK := knownPlainTextLen(&meta.Header)
+	decryptLen := int64(-1)
+	if plainLenOK {
+		decryptLen = plainLen
+	}
 	// Range请求
-	if optRange != nil && optRange.HasStart {
+	if optRange != nil && (optRange.HasStart || optRange.HasEnd) {
+		if !optRange.HasStart {
+			// bytes=-N, 转换为明文的起始位置
+			start := getPlainTextLen(cc, &meta.Header, meta.ContentLength) - optRange.End
+			if start < 0 {
+				start = 0
+			}
+			optRange = &cos.RangeOptions{HasStart: true, Start: start}
+		}
 		// 加密block对齐
-		adjustStart := adjustRangeStart(optRange.Start, int64(cc.GetAlignLen()))
+		alignLen := int64(cc.GetAlignLen())
+		adjustStart := adjustRangeStart(optRange.Start, alignLen)
 		discardAlignLen = optRange.Start - adjustStart
-		if discardAlignLen > 0 {
-			optRange.Start = adjustStart
-			opt.Range = cos.FormatRangeOptions(optRange)
+		// 明文范围映射为密文范围
+		cipherRange := &cos.RangeOptions{
+			HasStart: true,
+			Start:    cc.GetEncryptedLen(adjustStart),
+		}
+		if optRange.HasEnd {
+			limitLen = optRange.End - optRange.Start + 1
+			cipherRange.HasEnd = true
+			cipherRange.End = cc.GetEncryptedLen(adjustRangeStart(optRange.End, alignLen)+alignLen) - 1
+			// 返回的密文不足请求的范围时才是读取到了对象末尾
+			minLen = adjustRangeStart(optRange.End, alignLen) + alignLen - adjustStart
+		}
+		opt.Range = cos.FormatRangeOptions(cipherRange)
+		if plainLenOK {
+			end := plainLen
+			if optRange.HasEnd && adjustRangeStart(optRange.End, alignLen)+alignLen < end {
+				end = adjustRangeStart(optRange.End, alignLen) + alignLen
+			}
+			decryptLen = end - adjustStart
+			if decryptLen < 0 {
+				decryptLen = -1
+			}
 		}
 
 		cd := cc.GetCipherData().Clone()
@@ -156,9 +189,13 @@ func (s *CryptoObjectService) Get(ctx context.Context, name string, opt *cos.Obj
 	if err != nil {
 		return resp, err
 	}
-	resp.Body, err = cc.DecryptContent(resp.Body)
-	if err != nil {
-		return resp, err
+	if c, ok := cc.(*aesGcmCipher); ok {
+		resp.Body = c.decryptContentRange(resp.Body, minLen, decryptLen)
+	} else {
+		resp.Body, err = cc.DecryptContent(resp.Body)
+		if err != nil {
+			return resp, err
+		}
 	}
 	// 抛弃多读取的数据
 	if discardAlignLen > 0 {
@@ -167,6 +204,14 @@ func (s *CryptoObjectService) Get(ctx context.Context, name string, opt *cos.Obj
 			Discard: int(discardAlignLen),
 		}
 	}
+	if limitLen >= 0 {
+		resp.Body = &cos.LimitedReadCloser{
+			LimitedReader: io.LimitedReader{
+				R: resp.Body,
+				N: limitLen,
+			},
+		}
+	}
 	return resp, err
 }
 
@@ -192,16 +237,40 @@ func (s *CryptoObjectService) GetToFile(ctx context.Context, name, localpath str
 	return resp, nil
 }
 
-func (s *CryptoObjectService) MultiUpload(ctx context.Context, name string, filepath string, opt *cos.MultiUploadOptions) (*cos.CompleteMultipartUploadResult, *cos.Response, error) {
-	return s.Upload(ctx, name, filepath, opt)
-}
-
-func (s *CryptoObjectService) Upload(ctx context.Context, name string, filepath string, opt *cos.MultiUploadOptions) (*cos.CompleteMultipartUploadResult, *cos.Response, error) {
-	return nil, nil, fmt.Errorf("CryptoObjectService doesn't support Upload Now")
+// contentCipherFromHeader 从对象的元数据中获取 Envelope 并创建解密使用的 ContentCipher
+func (c *CryptoClient) contentCipherFromHeader(header *http.Header) (ContentCipher, error) {
+	envelope, err := getEnvelopeFromHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	if !envelope.IsValid() {
+		return nil, fmt.Errorf("get envelope from header failed")
+	}
+	return c.contentCipherEnv(envelope)
 }
 
-func (s *CryptoObjectService) Download(ctx context.Context, name string, filepath string, opt *cos.MultiDownloadOptions) (*cos.Response, error) {
-	return nil, fmt.Errorf("CryptoObjectService doesn't support Download Now")
+// contentCipherEnv 根据 Envelope 获取解密使用的 ContentCipher，MasterCipher 实现了 MasterCipherResolver 时按 MatDesc 选择主密钥
+func (c *CryptoClient) contentCipherEnv(envelope Envelope) (ContentCipher, error) {
+	if resolver, ok := c.masterCipher.(MasterCipherResolver); ok {
+		master, err := resolver.ResolveMasterCipher(envelope.MatDesc, envelope.WrapAlg)
+		if err != nil {
+			return nil, err
+		}
+		cc, err := contentCipherEnv(master, envelope)
+		if err != nil {
+			return nil, fmt.Errorf("get content cipher from envelope failed: %v", err)
+		}
+		return cc, nil
+	}
+	encryptMatDesc := c.ContentCipherBuilder.GetMatDesc()
+	if envelope.MatDesc != encryptMatDesc {
+		return nil, fmt.Errorf("provided master cipher error, want:%v, return:%v", encryptMatDesc, envelope.MatDesc)
+	}
+	cc, err := c.ContentCipherBuilder.ContentCipherEnv(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("get content cipher from envelope failed: %v", err)
+	}
+	return cc, nil
 }
 
 func adjustRangeStart(start int64, alignLen int64) int64 {
@@ -222,6 +291,9 @@ func addCryptoHeaders(header *http.Header, cd *CipherData) {
 
 	header.Add(COSClientSideEncryptionWrapAlg, cd.WrapAlgorithm)
 	header.Add(COSClientSideEncryptionCekAlg, cd.CEKAlgorithm)
+	if cd.CEKAlgorithm == AesGcmAlgorithm {
+		header.Add(COSClientSideEncryptionTagLen, strconv.Itoa(aesGcmTagSize*8))
+	}
 }
 
 func getEnvelopeFromHeader(header *http.Header) (Envelope, error) {
@@ -244,9 +316,32 @@ func getEnvelopeFromHeader(header *http.Header) (Envelope, error) {
 	envelope.MatDesc = header.Get(COSClientSideEncryptionMatDesc)
 	envelope.WrapAlg = header.Get(COSClientSideEncryptionWrapAlg)
 	envelope.CEKAlg = header.Get(COSClientSideEncryptionCekAlg)
+	envelope.TagLen = header.Get(COSClientSideEncryptionTagLen)
+	envelope.UnencryptedContentLen = header.Get(COSClientSideEncryptionUnencryptedContentLength)
 	return envelope, nil
 }
 
+// getPlainTextLen 获取对象的明文长度，优先使用元数据中记录的长度
+func getPlainTextLen(cc ContentCipher, header *http.Header, encryptedLen int64) int64 {
+	if l, ok := knownPlainTextLen(header); ok {
+		return l
+	}
+	if c, ok := cc.(*aesGcmCipher); ok {
+		return c.getPlainTextLen(encryptedLen)
+	}
+	return encryptedLen
+}
+
+// knownPlainTextLen 返回元数据中记录的明文长度
+func knownPlainTextLen(header *http.Header) (int64, bool) {
+	for _, key := range []string{COSClientSideEncryptionUnencryptedContentLength, COSClientSideEncryptionDataSize} {
+		if l, err := strconv.ParseInt(header.Get(key), 10, 64); err == nil {
+			return l, true
+		}
+	}
+	return 0, false
+}
+
 func isEncrypted(header *http.Header) bool {
 	encryptedKey := header.Get(COSClientSideEncryptionKey)
 	if len(encryptedKey) > 0 {
@@ -0,0 +1,745 @@
+package coscrypto
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// cryptoUploadCPInfo 加密分块上传的断点信息，只记录加密后的数据密钥与 IV
+type cryptoUploadCPInfo struct {
+	Key          string               `json:"key,omitempty"`
+	UploadID     string               `json:"uploadId,omitempty"`
+	Size         int64                `json:"size,omitempty"`
+	ModTime      int64                `json:"modTime,omitempty"`
+	PartSize     int64                `json:"partSize,omitempty"`
+	EncryptedKey string               `json:"encryptedKey,omitempty"`
+	EncryptedIV  string               `json:"encryptedIV,omitempty"`
+	MatDesc      string               `json:"matDesc,omitempty"`
+	WrapAlg      string               `json:"wrapAlg,omitempty"`
+	CEKAlg       string               `json:"cekAlg,omitempty"`
+	TagLen       string               `json:"tagLen,omitempty"`
+	Parts        []cryptoUploadedPart `json:"parts,omitempty"`
+}
+
+type cryptoUploadedPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"eTag"`
+}
+
+type cryptoJob struct {
+	Name       string
+	UploadId   string
+	FilePath   string
+	RetryTimes int
+	VersionId  []string
+	Chunk      cos.Chunk
+	Opt        *cos.ObjectUploadPartOptions
+	DownOpt    *cos.ObjectGetOptions
+}
+
+type cryptoResult struct {
+	PartNumber int
+	Resp       *cos.Response
+	err        error
+}
+
+func newCryptoUploadCPInfo(name, uploadID string, fi os.FileInfo, partSize int64, cd *CipherData) *cryptoUploadCPInfo {
+	info := &cryptoUploadCPInfo{
+		Key:          name,
+		UploadID:     uploadID,
+		Size:         fi.Size(),
+		ModTime:      fi.ModTime().UnixNano(),
+		PartSize:     partSize,
+		EncryptedKey: base64.StdEncoding.EncodeToString(cd.EncryptedKey),
+		EncryptedIV:  base64.StdEncoding.EncodeToString(cd.EncryptedIV),
+		MatDesc:      cd.MatDesc,
+		WrapAlg:      cd.WrapAlgorithm,
+		CEKAlg:       cd.CEKAlgorithm,
+	}
+	if cd.CEKAlgorithm == AesGcmAlgorithm {
+		info.TagLen = strconv.Itoa(aesGcmTagSize * 8)
+	}
+	return info
+}
+
+func (info *cryptoUploadCPInfo) envelope() (Envelope, error) {
+	var envelope Envelope
+	key, err := base64.StdEncoding.DecodeString(info.EncryptedKey)
+	if err != nil {
+		return envelope, err
+	}
+	iv, err := base64.StdEncoding.DecodeString(info.EncryptedIV)
+	if err != nil {
+		return envelope, err
+	}
+	envelope.CipherKey = string(key)
+	envelope.IV = string(iv)
+	envelope.MatDesc = info.MatDesc
+	envelope.WrapAlg = info.WrapAlg
+	envelope.CEKAlg = info.CEKAlg
+	envelope.TagLen = info.TagLen
+	return envelope, nil
+}
+
+func (info *cryptoUploadCPInfo) dump(cpfile string) error {
+	bs, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cpfile, bs, 0660)
+}
+
+// checkCryptoUploadedParts 读取断点文件并与 COS 上已上传的分块比较，成功时返回断点信息与恢复的 ContentCipher
+func (s *CryptoObjectService) checkCryptoUploadedParts(ctx context.Context, name, cpfile string, fi os.FileInfo, chunks []cos.Chunk) (*cryptoUploadCPInfo, ContentCipher, error) {
+	bs, err := ioutil.ReadFile(cpfile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var info cryptoUploadCPInfo
+	if err = json.Unmarshal(bs, &info); err != nil {
+		return nil, nil, err
+	}
+	if info.Key != name || info.UploadID == "" || info.Size != fi.Size() || info.ModTime != fi.ModTime().UnixNano() || info.PartSize != chunks[0].Size {
+		return nil, nil, fmt.Errorf("CheckPoint File[%v] doesn't match the local file", cpfile)
+	}
+	envelope, err := info.envelope()
+	if err != nil {
+		return nil, nil, err
+	}
+	cc, err := s.cryptoClient.contentCipherEnv(envelope)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !partSizeIsValid(info.PartSize, int64(cc.GetAlignLen())) {
+		return nil, nil, fmt.Errorf("PartSize is invalid, it should be %v aligned", cc.GetAlignLen())
+	}
+
+	// 以 COS 上的分块为准，ETag 与大小一致的分块不再上传
+	uploaded := map[int]cos.Object{}
+	opt := &cos.ObjectListPartsOptions{MaxParts: "1000"}
+	for {
+		res, _, err := s.ObjectService.ListParts(ctx, name, info.UploadID, opt)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, part := range res.Parts {
+			uploaded[part.PartNumber] = part
+		}
+		if !res.IsTruncated {
+			break
+		}
+		opt.PartNumberMarker = res.NextPartNumberMarker
+	}
+	var parts []cryptoUploadedPart
+	for _, part := range info.Parts {
+		if part.PartNumber < 1 || part.PartNumber > len(chunks) {
+			continue
+		}
+		remote, ok := uploaded[part.PartNumber]
+		chunk := &chunks[part.PartNumber-1]
+		if !ok || remote.ETag != part.ETag || remote.Size != cc.GetEncryptedLen(chunk.Size) {
+			continue
+		}
+		chunk.Done = true
+		chunk.ETag = part.ETag
+		parts = append(parts, part)
+	}
+	info.Parts = parts
+	return &info, cc, nil
+}
+
+func cryptoUploadWorker(ctx context.Context, s *CryptoObjectService, jobs <-chan *cryptoJob, results chan<- *cryptoResult, cryptoCtx *CryptoContext) {
+	for j := range jobs {
+		rt := j.RetryTimes
+		for {
+			var res cryptoResult
+			res.PartNumber = j.Chunk.Number
+			// http.Request.Body can be Closed in request
+			fd, err := os.Open(j.FilePath)
+			if err != nil {
+				res.err = err
+				results <- &res
+				break
+			}
+			opt := cos.CloneObjectUploadPartOptions(j.Opt)
+			opt.ContentLength = j.Chunk.Size
+			resp, err := s.UploadPart(ctx, j.Name, j.UploadId, j.Chunk.Number,
+				io.NewSectionReader(fd, j.Chunk.OffSet, j.Chunk.Size), opt, cryptoCtx)
+			fd.Close()
+			res.Resp = resp
+			res.err = err
+			if err != nil {
+				rt--
+				if rt == 0 {
+					results <- &res
+					break
+				}
+				time.Sleep(time.Millisecond)
+				continue
+			}
+			results <- &res
+			break
+		}
+	}
+}
+
+// encryptedCRC64 使用 ContentCipher 重新加密本地明文文件并计算密文的 CRC64，用于与 COS 返回的 x-cos-hash-crc64ecma 比较
+func encryptedCRC64(cc ContentCipher, filepath string) (uint64, error) {
+	fd, err := os.Open(filepath)
+	if err != nil {
+		return 0, err
+	}
+	defer fd.Close()
+	cd := cc.GetCipherData().Clone()
+	cc, err = cc.Clone(cd)
+	if err != nil {
+		return 0, err
+	}
+	reader, err := cc.EncryptContent(fd)
+	if err != nil {
+		return 0, err
+	}
+	hash := crc64.New(crc64.MakeTable(crc64.ECMA))
+	if _, err = io.Copy(hash, reader); err != nil {
+		return 0, err
+	}
+	return hash.Sum64(), nil
+}
+
+func cryptoProgressCallback(listener cos.ProgressListener, eventType cos.ProgressEventType, rwBytes, consumed, total int64, err ...error) {
+	if listener == nil {
+		return
+	}
+	event := &cos.ProgressEvent{
+		EventType:     eventType,
+		RWBytes:       rwBytes,
+		ConsumedBytes: consumed,
+		TotalBytes:    total,
+	}
+	if len(err) > 0 {
+		event.Err = err[0]
+	}
+	listener.ProgressChangedCallback(event)
+}
+
+func (s *CryptoObjectService) MultiUpload(ctx context.Context, name string, filepath string, opt *cos.MultiUploadOptions) (*cos.CompleteMultipartUploadResult, *cos.Response, error) {
+	return s.Upload(ctx, name, filepath, opt)
+}
+
+// Upload 并发分块加密上传本地文件，分块大小需按 ContentCipher 的 AlignLen 对齐，每个分块的 IV 按偏移量计算。
+// 开启 CheckPoint 时，UploadID 与加密后的数据密钥保存在 CheckPointFile(默认为 <filepath>.cryptouploadtask)中，用于断点续传。
+func (s *CryptoObjectService) Upload(ctx context.Context, name string, filepath string, opt *cos.MultiUploadOptions) (*cos.CompleteMultipartUploadResult, *cos.Response, error) {
+	if opt == nil {
+		opt = &cos.MultiUploadOptions{}
+	}
+	// 1.Get the file chunk
+	totalBytes, chunks, partNum, err := cos.SplitFileIntoChunks(filepath, opt.PartSize*1024*1024)
+	if err != nil {
+		return nil, nil, err
+	}
+	// filesize=0 , use simple upload
+	if partNum == 0 || partNum == 1 {
+		var opt0 *cos.ObjectPutOptions
+		if opt.OptIni != nil {
+			opt0 = &cos.ObjectPutOptions{
+				ACLHeaderOptions:       opt.OptIni.ACLHeaderOptions,
+				ObjectPutHeaderOptions: opt.OptIni.ObjectPutHeaderOptions,
+			}
+		}
+		// 简单上传时由 Put 校验密文的 CRC64
+		rsp, err := s.PutFromFile(ctx, name, filepath, opt0)
+		if err != nil {
+			return nil, rsp, err
+		}
+		result := &cos.CompleteMultipartUploadResult{
+			Location: fmt.Sprintf("%s/%s", s.cryptoClient.BaseURL.BucketURL, name),
+			Key:      name,
+			ETag:     rsp.Header.Get("ETag"),
+		}
+		return result, rsp, nil
+	}
+	fi, err := os.Stat(filepath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cryptoCtx := &CryptoContext{
+		DataSize: totalBytes,
+		PartSize: chunks[0].Size,
+	}
+	var cpInfo *cryptoUploadCPInfo
+	cpfile := opt.CheckPointFile
+	if cpfile == "" {
+		cpfile = fmt.Sprintf("%s.cryptouploadtask", filepath)
+	}
+	if opt.CheckPoint {
+		info, cc, err := s.checkCryptoUploadedParts(ctx, name, cpfile, fi, chunks)
+		if err == nil {
+			cpInfo = info
+			cryptoCtx.ContentCipher = cc
+		} else {
+			for i := range chunks {
+				chunks[i].Done = false
+				chunks[i].ETag = ""
+			}
+		}
+	}
+
+	// 2.Init
+	optini := opt.OptIni
+	optcom := &cos.CompleteMultipartUploadOptions{}
+	if optini != nil && optini.ObjectPutHeaderOptions != nil && (optini.IfMatch != "" || optini.IfNoneMatch != "") {
+		// 条件头部只作用于 CompleteMultipartUpload
+		optcom.IfMatch = optini.IfMatch
+		optcom.IfNoneMatch = optini.IfNoneMatch
+		putHeader := *optini.ObjectPutHeaderOptions
+		putHeader.IfMatch, putHeader.IfNoneMatch = "", ""
+		optini = &cos.InitiateMultipartUploadOptions{
+			ACLHeaderOptions:       optini.ACLHeaderOptions,
+			ObjectPutHeaderOptions: &putHeader,
+		}
+	}
+	if cpInfo == nil {
+		res, _, err := s.InitiateMultipartUpload(ctx, name, optini, cryptoCtx)
+		if err != nil {
+			return nil, nil, err
+		}
+		cpInfo = newCryptoUploadCPInfo(name, res.UploadID, fi, cryptoCtx.PartSize, cryptoCtx.ContentCipher.GetCipherData())
+		if opt.CheckPoint {
+			if err = cpInfo.dump(cpfile); err != nil {
+				return nil, nil, fmt.Errorf("Dump CheckPoint File[%v] Failed:%v", cpfile, err)
+			}
+		}
+	}
+	uploadID := cpInfo.UploadID
+
+	var poolSize int
+	if opt.ThreadPoolSize > 0 {
+		poolSize = opt.ThreadPoolSize
+	} else {
+		// Default is one
+		poolSize = 1
+	}
+
+	chjobs := make(chan *cryptoJob, 100)
+	chresults := make(chan *cryptoResult, 10000)
+
+	// 3.Start worker
+	for w := 1; w <= poolSize; w++ {
+		go cryptoUploadWorker(ctx, s, chjobs, chresults, cryptoCtx)
+	}
+
+	// progress started event
+	var listener cos.ProgressListener
+	var consumedBytes int64
+	if optini != nil && optini.ObjectPutHeaderOptions != nil {
+		listener = optini.Listener
+	}
+	cryptoProgressCallback(listener, cos.ProgressStartedEvent, 0, 0, totalBytes)
+
+	// 4.Push jobs
+	go func() {
+		for _, chunk := range chunks {
+			if chunk.Done {
+				continue
+			}
+			partOpt := &cos.ObjectUploadPartOptions{}
+			if optini != nil && optini.ObjectPutHeaderOptions != nil {
+				partOpt.XCosSSECustomerAglo = optini.XCosSSECustomerAglo
+				partOpt.XCosSSECustomerKey = optini.XCosSSECustomerKey
+				partOpt.XCosSSECustomerKeyMD5 = optini.XCosSSECustomerKeyMD5
+				partOpt.XCosTrafficLimit = optini.XCosTrafficLimit
+				partOpt.SSE = optini.SSE
+			}
+			job := &cryptoJob{
+				Name:       name,
+				RetryTimes: 3,
+				FilePath:   filepath,
+				UploadId:   uploadID,
+				Chunk:      chunk,
+				Opt:        partOpt,
+			}
+			chjobs <- job
+		}
+		close(chjobs)
+	}()
+
+	// 5.Recv the resp etag to complete
+	err = nil
+	for i := 0; i < partNum; i++ {
+		if chunks[i].Done {
+			optcom.Parts = append(optcom.Parts, cos.Object{
+				PartNumber: chunks[i].Number, ETag: chunks[i].ETag},
+			)
+			if err == nil {
+				consumedBytes += chunks[i].Size
+				cryptoProgressCallback(listener, cos.ProgressDataEvent, chunks[i].Size, consumedBytes, totalBytes)
+			}
+			continue
+		}
+		res := <-chresults
+		if res.Resp == nil || res.err != nil {
+			err = fmt.Errorf("UploadID %s, part %d failed to get resp content. error: %v", uploadID, res.PartNumber, res.err)
+			continue
+		}
+		etag := res.Resp.Header.Get("ETag")
+		optcom.Parts = append(optcom.Parts, cos.Object{
+			PartNumber: res.PartNumber, ETag: etag},
+		)
+		// Dump CheckPoint Info
+		if opt.CheckPoint {
+			cpInfo.Parts = append(cpInfo.Parts, cryptoUploadedPart{PartNumber: res.PartNumber, ETag: etag})
+			// 继续接收剩余分块的结果，结束后返回错误
+			if derr := cpInfo.dump(cpfile); derr != nil && err == nil {
+				err = fmt.Errorf("Dump CheckPoint File[%v] Failed:%v", cpfile, derr)
+			}
+		}
+		if err == nil {
+			consumedBytes += chunks[res.PartNumber-1].Size
+			cryptoProgressCallback(listener, cos.ProgressDataEvent, chunks[res.PartNumber-1].Size, consumedBytes, totalBytes)
+		}
+	}
+	close(chresults)
+	if err != nil {
+		cryptoProgressCallback(listener, cos.ProgressFailedEvent, 0, consumedBytes, totalBytes, err)
+		return nil, nil, err
+	}
+	sort.Sort(cos.ObjectList(optcom.Parts))
+
+	cryptoProgressCallback(listener, cos.ProgressCompletedEvent, 0, consumedBytes, totalBytes)
+
+	v, resp, err := s.CompleteMultipartUpload(ctx, name, uploadID, optcom)
+	if err != nil {
+		return v, resp, err
+	}
+	// 上传成功，删除checkpoint文件
+	if opt.CheckPoint {
+		os.Remove(cpfile)
+	}
+
+	// COS 返回的是密文的 CRC64
+	if resp != nil && s.cryptoClient.Conf.EnableCRC && !opt.DisableChecksum {
+		scoscrc := resp.Header.Get("x-cos-hash-crc64ecma")
+		icoscrc, err := strconv.ParseUint(scoscrc, 10, 64)
+		localcrc, lerr := encryptedCRC64(cryptoCtx.ContentCipher, filepath)
+		if lerr != nil {
+			return v, resp, lerr
+		}
+		if icoscrc != localcrc {
+			return v, resp, &cos.ChecksumMismatchError{
+				Algorithm: "crc64ecma",
+				Want:      strconv.FormatUint(localcrc, 10),
+				Return:    scoscrc,
+				Header:    resp.Header,
+				Err:       err,
+			}
+		}
+	}
+	return v, resp, nil
+}
+
+func cryptoDownloadWorker(ctx context.Context, s *CryptoObjectService, jobs <-chan *cryptoJob, results chan<- *cryptoResult, cc ContentCipher, meta *cos.Response) {
+	for j := range jobs {
+		opt := &cos.RangeOptions{
+			HasStart: true,
+			HasEnd:   true,
+			Start:    j.Chunk.OffSet,
+			End:      j.Chunk.OffSet + j.Chunk.Size - 1,
+		}
+		j.DownOpt.Range = cos.FormatRangeOptions(opt)
+		rt := j.RetryTimes
+		for {
+			var res cryptoResult
+			res.PartNumber = j.Chunk.Number
+			resp, err := s.getWithCipher(ctx, j.Name, j.DownOpt, cc, meta, j.VersionId...)
+			res.err = err
+			res.Resp = resp
+			if err != nil {
+				results <- &res
+				break
+			}
+			fd, err := os.OpenFile(j.FilePath, os.O_WRONLY, 0660)
+			if err != nil {
+				resp.Body.Close()
+				res.err = err
+				results <- &res
+				break
+			}
+			fd.Seek(j.Chunk.OffSet, os.SEEK_SET)
+			n, err := io.Copy(fd, resp.Body)
+			if n != j.Chunk.Size || err != nil {
+				fd.Close()
+				resp.Body.Close()
+				rt--
+				if rt == 0 {
+					res.err = fmt.Errorf("io.Copy Failed, nread:%v, want:%v, err:%v", n, j.Chunk.Size, err)
+					results <- &res
+					break
+				}
+				time.Sleep(time.Millisecond)
+				continue
+			}
+			fd.Close()
+			resp.Body.Close()
+			results <- &res
+			break
+		}
+	}
+}
+
+// checkCryptoDownloadedParts 与 ObjectService 的断点续载逻辑一致，DownloadedBlocks 记录的是明文的范围
+func checkCryptoDownloadedParts(opt *cos.MultiDownloadCPInfo, chfile string, chunks []cos.Chunk) (*cos.MultiDownloadCPInfo, bool) {
+	defaultRes := *opt
+
+	fd, err := os.Open(chfile)
+	// checkpoint 文件不存在
+	if err != nil && os.IsNotExist(err) {
+		// 创建 checkpoint 文件
+		fd, _ = os.OpenFile(chfile, os.O_RDONLY|os.O_CREATE|os.O_TRUNC, 0660)
+		fd.Close()
+		return &defaultRes, false
+	}
+	if err != nil {
+		return &defaultRes, false
+	}
+	defer fd.Close()
+
+	var res cos.MultiDownloadCPInfo
+	err = json.NewDecoder(fd).Decode(&res)
+	if err != nil {
+		return &defaultRes, false
+	}
+	// 与COS的文件比较
+	if res.CRC64 != opt.CRC64 || res.ETag != opt.ETag || res.Size != opt.Size || res.LastModified != opt.LastModified || len(res.DownloadedBlocks) == 0 {
+		return &defaultRes, false
+	}
+	partSize := chunks[0].Size
+	for _, v := range res.DownloadedBlocks {
+		index := v.From / partSize
+		if index >= int64(len(chunks)) || chunks[index].OffSet != v.From || chunks[index].OffSet+chunks[index].Size-1 != v.To {
+			// 重置chunks
+			for i := range chunks {
+				chunks[i].Done = false
+			}
+			return &defaultRes, false
+		}
+		chunks[index].Done = true
+	}
+	return &res, true
+}
+
+// Download 并发分块下载并解密对象，分块按 ContentCipher 的 AlignLen 对齐，数据密钥只解析一次。
+// CheckPointFile 中记录的是明文的下载范围；CRC64 校验通过重新加密本地文件与 COS 返回的密文 CRC64 比较。
+func (s *CryptoObjectService) Download(ctx context.Context, name string, filepath string, opt *cos.MultiDownloadOptions, id ...string) (*cos.Response, error) {
+	if opt == nil {
+		opt = &cos.MultiDownloadOptions{}
+	}
+	if opt.Opt != nil && opt.Opt.Range != "" {
+		return nil, fmt.Errorf("Download doesn't support Range Options")
+	}
+	headOpt := &cos.ObjectHeadOptions{}
+	if opt.Opt != nil {
+		headOpt.XCosSSECustomerAglo = opt.Opt.XCosSSECustomerAglo
+		headOpt.XCosSSECustomerKey = opt.Opt.XCosSSECustomerKey
+		headOpt.XCosSSECustomerKeyMD5 = opt.Opt.XCosSSECustomerKeyMD5
+		headOpt.SSE = opt.Opt.SSE
+		headOpt.XOptionHeader = opt.Opt.XOptionHeader
+	}
+	meta, err := s.ObjectService.Head(ctx, name, headOpt, id...)
+	if err != nil {
+		return meta, err
+	}
+	if !isEncrypted(&meta.Header) {
+		return s.ObjectService.Download(ctx, name, filepath, opt, id...)
+	}
+	cc, err := s.cryptoClient.contentCipherFromHeader(&meta.Header)
+	if err != nil {
+		return meta, fmt.Errorf("%v, object:%v", err, name)
+	}
+	// 如果对象不存在x-cos-hash-crc64ecma，则跳过不做校验
+	coscrc := meta.Header.Get("x-cos-hash-crc64ecma")
+	totalBytes := getPlainTextLen(cc, &meta.Header, meta.ContentLength)
+
+	// 切分
+	chunks, partNum, err := cos.SplitSizeIntoChunksToDownload(totalBytes, opt.PartSize*1024*1024)
+	if err != nil {
+		return meta, err
+	}
+	if partNum > 1 && !partSizeIsValid(chunks[0].Size, int64(cc.GetAlignLen())) {
+		return meta, fmt.Errorf("PartSize is invalid, it should be %v aligned", cc.GetAlignLen())
+	}
+	checkCRC := func(resp *cos.Response) (*cos.Response, error) {
+		if coscrc == "" || !s.cryptoClient.Conf.EnableCRC || opt.DisableChecksum {
+			return resp, nil
+		}
+		icoscrc, _ := strconv.ParseUint(coscrc, 10, 64)
+		localcrc, err := encryptedCRC64(cc, filepath)
+		if err != nil {
+			return resp, err
+		}
+		if localcrc != icoscrc {
+			return resp, &cos.ChecksumMismatchError{
+				Algorithm: "crc64ecma",
+				Want:      strconv.FormatUint(localcrc, 10),
+				Return:    coscrc,
+				Header:    meta.Header,
+			}
+		}
+		return resp, nil
+	}
+	// 直接下载到文件
+	if partNum == 0 || partNum == 1 {
+		var getOpt cos.ObjectGetOptions
+		if opt.Opt != nil {
+			getOpt = *opt.Opt
+		}
+		rsp, err := s.getWithCipher(ctx, name, &getOpt, cc, meta, id...)
+		if err != nil {
+			return rsp, err
+		}
+		defer rsp.Body.Close()
+		fd, err := os.OpenFile(filepath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0660)
+		if err != nil {
+			return rsp, err
+		}
+		_, err = io.Copy(fd, rsp.Body)
+		fd.Close()
+		if err != nil {
+			return rsp, err
+		}
+		return checkCRC(rsp)
+	}
+	// 断点续载
+	var resumableFlag bool
+	var resumableInfo *cos.MultiDownloadCPInfo
+	var cpfd *os.File
+	var cpfile string
+	if opt.CheckPoint {
+		cpInfo := &cos.MultiDownloadCPInfo{
+			LastModified: meta.Header.Get("Last-Modified"),
+			ETag:         meta.Header.Get("ETag"),
+			CRC64:        coscrc,
+			Size:         totalBytes,
+		}
+		cpfile = opt.CheckPointFile
+		if cpfile == "" {
+			cpfile = fmt.Sprintf("%s.cryptoresumabletask", filepath)
+		}
+		resumableInfo, resumableFlag = checkCryptoDownloadedParts(cpInfo, cpfile, chunks)
+		cpfd, err = os.OpenFile(cpfile, os.O_RDWR, 0660)
+		if err != nil {
+			return nil, fmt.Errorf("Open CheckPoint File[%v] Failed:%v", cpfile, err)
+		}
+	}
+	if !resumableFlag {
+		// 创建文件
+		nfile, err := os.OpenFile(filepath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0660)
+		if err != nil {
+			if cpfd != nil {
+				cpfd.Close()
+			}
+			return meta, err
+		}
+		nfile.Close()
+	}
+
+	var poolSize int
+	if opt.ThreadPoolSize > 0 {
+		poolSize = opt.ThreadPoolSize
+	} else {
+		poolSize = 1
+	}
+	chjobs := make(chan *cryptoJob, 100)
+	chresults := make(chan *cryptoResult, 10000)
+	for w := 1; w <= poolSize; w++ {
+		go cryptoDownloadWorker(ctx, s, chjobs, chresults, cc, meta)
+	}
+
+	var listener cos.ProgressListener
+	var consumedBytes int64
+	if opt.Opt != nil && opt.Opt.Listener != nil {
+		listener = opt.Opt.Listener
+	}
+	cryptoProgressCallback(listener, cos.ProgressStartedEvent, 0, 0, totalBytes)
+
+	go func() {
+		for _, chunk := range chunks {
+			if chunk.Done {
+				continue
+			}
+			var downOpt cos.ObjectGetOptions
+			if opt.Opt != nil {
+				downOpt = *opt.Opt
+				downOpt.Listener = nil // listener need to set nil
+			}
+			job := &cryptoJob{
+				Name:       name,
+				RetryTimes: 3,
+				FilePath:   filepath,
+				Chunk:      chunk,
+				DownOpt:    &downOpt,
+			}
+			if len(id) > 0 {
+				job.VersionId = append(job.VersionId, id...)
+			}
+			chjobs <- job
+		}
+		close(chjobs)
+	}()
+	err = nil
+	for i := 0; i < partNum; i++ {
+		if chunks[i].Done {
+			if err == nil {
+				consumedBytes += chunks[i].Size
+				cryptoProgressCallback(listener, cos.ProgressDataEvent, chunks[i].Size, consumedBytes, totalBytes)
+			}
+			continue
+		}
+		res := <-chresults
+		if res.Resp == nil || res.err != nil {
+			err = fmt.Errorf("part %d get resp Content. error: %v", res.PartNumber, res.err)
+			continue
+		}
+		// Dump CheckPoint Info
+		if opt.CheckPoint {
+			cpfd.Truncate(0)
+			cpfd.Seek(0, os.SEEK_SET)
+			resumableInfo.DownloadedBlocks = append(resumableInfo.DownloadedBlocks, cos.DownloadedBlock{
+				From: chunks[res.PartNumber-1].OffSet,
+				To:   chunks[res.PartNumber-1].OffSet + chunks[res.PartNumber-1].Size - 1,
+			})
+			json.NewEncoder(cpfd).Encode(resumableInfo)
+		}
+
+		// 更新进度
+		consumedBytes += chunks[res.PartNumber-1].Size
+		cryptoProgressCallback(listener, cos.ProgressDataEvent, chunks[res.PartNumber-1].Size, consumedBytes, totalBytes)
+	}
+	close(chresults)
+	if cpfd != nil {
+		cpfd.Close()
+	}
+	if err != nil {
+		cryptoProgressCallback(listener, cos.ProgressFailedEvent, 0, consumedBytes, totalBytes, err)
+		return nil, err
+	}
+	// 下载成功，删除checkpoint文件
+	if opt.CheckPoint {
+		os.Remove(cpfile)
+	}
+	if _, err = checkCRC(meta); err != nil {
+		return meta, err
+	}
+	cryptoProgressCallback(listener, cos.ProgressCompletedEvent, 0, consumedBytes, totalBytes)
+	return meta, nil
+}
@@ -0,0 +1,252 @@
+package coscrypto_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"github.com/tencentyun/cos-go-sdk-v5/crypto"
+	"hash/crc64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// multipartServer 模拟 COS 的分块上传与下载接口
+type multipartServer struct {
+	*httptest.Server
+	mu         sync.Mutex
+	initiated  int
+	uploaded   int
+	failPart   int
+	header     http.Header
+	headHeader http.Header
+	parts      map[int][]byte
+	objectData []byte
+}
+
+func newMultipartServer() *multipartServer {
+	ms := &multipartServer{parts: map[int][]byte{}}
+	ms.Server = httptest.NewServer(http.HandlerFunc(ms.handle))
+	return ms
+}
+
+func crc64String(data []byte) string {
+	return strconv.FormatUint(crc64.Checksum(data, crc64.MakeTable(crc64.ECMA)), 10)
+}
+
+func (ms *multipartServer) handle(w http.ResponseWriter, r *http.Request) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	query := r.URL.Query()
+	_, initiate := query["uploads"]
+	switch {
+	case r.Method == http.MethodPost && initiate:
+		ms.initiated++
+		ms.header = http.Header{}
+		for k, v := range r.Header {
+			if strings.HasPrefix(strings.ToLower(k), "x-cos-meta-") {
+				ms.header[k] = v
+			}
+		}
+		ms.parts = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>upload-%d</UploadId></InitiateMultipartUploadResult>", ms.initiated)
+	case r.Method == http.MethodPut && query.Get("partNumber") != "":
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		data, _ := ioutil.ReadAll(r.Body)
+		if number == ms.failPart {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		ms.uploaded++
+		ms.parts[number] = data
+		w.Header().Set("ETag", fmt.Sprintf("\"%x\"", md5.Sum(data)))
+		w.Header().Set("x-cos-hash-crc64ecma", crc64String(data))
+	case r.Method == http.MethodGet && query.Get("uploadId") != "":
+		res := cos.ObjectListPartsResult{UploadID: query.Get("uploadId")}
+		for number, data := range ms.parts {
+			res.Parts = append(res.Parts, cos.Object{
+				PartNumber: number,
+				ETag:       fmt.Sprintf("\"%x\"", md5.Sum(data)),
+				Size:       int64(len(data)),
+			})
+		}
+		xml.NewEncoder(w).Encode(res)
+	case r.Method == http.MethodPost && query.Get("uploadId") != "":
+		var numbers []int
+		for number := range ms.parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		ms.objectData = nil
+		for _, number := range numbers {
+			ms.objectData = append(ms.objectData, ms.parts[number]...)
+		}
+		w.Header().Set("x-cos-hash-crc64ecma", crc64String(ms.objectData))
+		fmt.Fprint(w, "<CompleteMultipartUploadResult><Key>test</Key><ETag>\"etag-3\"</ETag></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		if r.Method == http.MethodHead {
+			ms.headHeader = r.Header
+		}
+		for k, v := range ms.header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("x-cos-hash-crc64ecma", crc64String(ms.objectData))
+		http.ServeContent(w, r, "", time.Now(), bytes.NewReader(ms.objectData))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newMultipartCryptoClient(ms *multipartServer, builder coscrypto.ContentCipherBuilder) *coscrypto.CryptoClient {
+	var masterCipher EmptyMasterCipher
+	u, _ := url.Parse(ms.URL)
+	client := cos.NewClient(&cos.BaseURL{BucketURL: u}, nil)
+	client.Conf.RetryOpt.Count = 1
+	cclient := coscrypto.NewCryptoClient(client, masterCipher)
+	cclient.ContentCipherBuilder = builder
+	return cclient
+}
+
+func (s *CosTestSuite) TestCryptoObjectService_UploadAndDownload() {
+	var masterCipher EmptyMasterCipher
+	builders := []coscrypto.ContentCipherBuilder{
+		coscrypto.CreateAesCtrBuilder(masterCipher),
+		coscrypto.CreateAesGcmBuilder(masterCipher),
+	}
+	originData := make([]byte, 1024*1024*3+100)
+	rand.Read(originData)
+	filePath := "multi.upload." + time.Now().Format(time.RFC3339)
+	ioutil.WriteFile(filePath, originData, 0644)
+	defer os.Remove(filePath)
+
+	for _, builder := range builders {
+		ms := newMultipartServer()
+		cclient := newMultipartCryptoClient(ms, builder)
+		_, _, err := cclient.Object.Upload(context.Background(), "test", filePath, &cos.MultiUploadOptions{
+			PartSize:       1,
+			ThreadPoolSize: 3,
+		})
+		assert.Nil(s.T(), err, "Upload Failed")
+		assert.Equal(s.T(), 4, len(ms.parts), "part count is wrong")
+
+		downPath := filePath + ".down"
+		_, err = cclient.Object.Download(context.Background(), "test", downPath, &cos.MultiDownloadOptions{
+			PartSize:       1,
+			ThreadPoolSize: 3,
+		})
+		assert.Nil(s.T(), err, "Download Failed")
+		downData, _ := ioutil.ReadFile(downPath)
+		os.Remove(downPath)
+		assert.Equal(s.T(), bytes.Compare(originData, downData), 0, "decryptData != originData")
+
+		// 简单下载
+		_, err = cclient.Object.Download(context.Background(), "test", downPath, nil)
+		assert.Nil(s.T(), err, "Download Failed")
+		downData, _ = ioutil.ReadFile(downPath)
+		os.Remove(downPath)
+		assert.Equal(s.T(), bytes.Compare(originData, downData), 0, "decryptData != originData")
+		ms.Close()
+	}
+}
+
+func (s *CosTestSuite) TestCryptoObjectService_DownloadWithSSEC() {
+	var masterCipher EmptyMasterCipher
+	ms := newMultipartServer()
+	defer ms.Close()
+	cclient := newMultipartCryptoClient(ms, coscrypto.CreateAesCtrBuilder(masterCipher))
+
+	// Head 同样需要 SSE-C 的密钥
+	key := []byte("01234567890123456789012345678901")
+	downPath := "multi.ssec." + time.Now().Format(time.RFC3339)
+	_, err := cclient.Object.Download(context.Background(), "test", downPath, &cos.MultiDownloadOptions{
+		Opt: &cos.ObjectGetOptions{SSE: cos.NewSSEC(key)},
+	})
+	os.Remove(downPath)
+	assert.Nil(s.T(), err, "Download Failed")
+	assert.Equal(s.T(), "AES256", ms.headHeader.Get("x-cos-server-side-encryption-customer-algorithm"), "Head should carry SSE-C headers")
+	assert.NotEqual(s.T(), "", ms.headHeader.Get("x-cos-server-side-encryption-customer-key"), "Head should carry SSE-C headers")
+}
+
+func (s *CosTestSuite) TestCryptoObjectService_UploadCheckPoint() {
+	var masterCipher EmptyMasterCipher
+	originData := make([]byte, 1024*1024*3+100)
+	rand.Read(originData)
+	filePath := "multi.checkpoint." + time.Now().Format(time.RFC3339)
+	ioutil.WriteFile(filePath, originData, 0644)
+	defer os.Remove(filePath)
+
+	ms := newMultipartServer()
+	defer ms.Close()
+	cclient := newMultipartCryptoClient(ms, coscrypto.CreateAesGcmBuilder(masterCipher))
+	opt := &cos.MultiUploadOptions{
+		PartSize:   1,
+		CheckPoint: true,
+	}
+	ms.failPart = 3
+	_, _, err := cclient.Object.Upload(context.Background(), "test", filePath, opt)
+	assert.NotNil(s.T(), err, "Upload should fail")
+	_, err = os.Stat(filePath + ".cryptouploadtask")
+	assert.Nil(s.T(), err, "CheckPoint File should exist")
+
+	// 断点续传只上传失败的分块，并沿用原有的数据密钥
+	ms.failPart = 0
+	ms.uploaded = 0
+	_, _, err = cclient.Object.Upload(context.Background(), "test", filePath, opt)
+	assert.Nil(s.T(), err, "Upload Failed")
+	assert.Equal(s.T(), 1, ms.initiated, "InitiateMultipartUpload should be called once")
+	assert.Equal(s.T(), 1, ms.uploaded, "only the failed part should be uploaded")
+	_, err = os.Stat(filePath + ".cryptouploadtask")
+	assert.True(s.T(), os.IsNotExist(err), "CheckPoint File should be removed")
+
+	resp, err := cclient.Object.Get(context.Background(), "test", nil)
+	assert.Nil(s.T(), err, "GetObject Failed")
+	downData, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(s.T(), bytes.Compare(originData, downData), 0, "decryptData != originData")
+}
+
+func (s *CosTestSuite) TestCryptoObjectService_UploadCheckPointFile() {
+	var masterCipher EmptyMasterCipher
+	originData := make([]byte, 1024*1024*2+100)
+	rand.Read(originData)
+	filePath := "multi.checkpointfile." + time.Now().Format(time.RFC3339)
+	ioutil.WriteFile(filePath, originData, 0644)
+	defer os.Remove(filePath)
+	cpfile := filePath + ".custom"
+	defer os.Remove(cpfile)
+
+	ms := newMultipartServer()
+	defer ms.Close()
+	cclient := newMultipartCryptoClient(ms, coscrypto.CreateAesGcmBuilder(masterCipher))
+	opt := &cos.MultiUploadOptions{
+		PartSize:       1,
+		CheckPoint:     true,
+		CheckPointFile: cpfile,
+	}
+	ms.failPart = 2
+	_, _, err := cclient.Object.Upload(context.Background(), "test", filePath, opt)
+	assert.NotNil(s.T(), err, "Upload should fail")
+	_, err = os.Stat(cpfile)
+	assert.Nil(s.T(), err, "CheckPoint File should exist")
+	_, err = os.Stat(filePath + ".cryptouploadtask")
+	assert.True(s.T(), os.IsNotExist(err), "default CheckPoint File should not be used")
+
+	ms.failPart = 0
+	_, _, err = cclient.Object.Upload(context.Background(), "test", filePath, opt)
+	assert.Nil(s.T(), err, "Upload Failed")
+	assert.Equal(s.T(), 1, ms.initiated, "InitiateMultipartUpload should be called once")
+	_, err = os.Stat(cpfile)
+	assert.True(s.T(), os.IsNotExist(err), "CheckPoint File should be removed")
+}
@@ -30,6 +30,10 @@ func (s *CryptoObjectService) InitiateMultipartUpload(ctx context.Context, name
 	if !partSizeIsValid(cryptoCtx.PartSize, int64(contentCipher.GetAlignLen())) {
 		return nil, nil, fmt.Errorf("PartSize is invalid, it should be %v aligned", contentCipher.GetAlignLen())
 	}
+	// GCM 需要根据 DataSize 判断最后一个分块，为其加上结束标记
+	if _, ok := contentCipher.(*aesGcmCipher); ok && cryptoCtx.DataSize <= 0 {
+		return nil, nil, fmt.Errorf("CryptoContext's DataSize is required for %v", AesGcmAlgorithm)
+	}
 	// 添加自定义头部
 	cryptoCtx.ContentCipher = contentCipher
 	opt = cos.CloneInitiateMultipartUploadOptions(opt)
@@ -76,8 +80,14 @@ func (s *CryptoObjectService) UploadPart(ctx context.Context, name, uploadID str
 	if err != nil {
 		return nil, err
 	}
-	reader, err := cc.EncryptContent(r)
-	if err != nil {
+	var reader io.ReadCloser
+	if c, ok := cc.(*aesGcmCipher); ok {
+		if cryptoCtx.DataSize <= 0 {
+			return nil, fmt.Errorf("CryptoContext's DataSize is required for %v", AesGcmAlgorithm)
+		}
+		final := int64(partNumber)*cryptoCtx.PartSize >= cryptoCtx.DataSize
+		reader = c.encryptContentPart(r, final)
+	} else if reader, err = cc.EncryptContent(r); err != nil {
 		return nil, err
 	}
 	return s.ObjectService.UploadPart(ctx, name, uploadID, partNumber, reader, opt)
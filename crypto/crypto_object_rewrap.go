@@ -0,0 +1,206 @@
+package coscrypto
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RewrapPrefixOptions is the option of RewrapPrefix
+type RewrapPrefixOptions struct {
+	ThreadPoolSize int
+}
+
+// RewrapPrefixResult is the result of RewrapPrefix
+type RewrapPrefixResult struct {
+	// 重新封装数据密钥的对象数
+	Rewrapped int
+	// 未加密或已由 newMaster 封装的对象数
+	Skipped int
+	// 失败的对象及其错误
+	Failed map[string]error
+}
+
+// Rewrap 使用 newMaster 重新封装对象的数据密钥(CEK)与 IV，只通过自拷贝(x-cos-metadata-directive: Replaced)改写元数据，不重新上传对象数据，大于 5GB 的对象使用分块复制。
+// 旧的 MasterCipher 由 CryptoClient 提供，对象的其它元数据保持不变。
+// 只改写最新版本：开启版本控制时会生成新的版本，历史版本仍由旧的 MasterCipher 封装。
+// 自拷贝不会保留 ACL 与标签，Rewrap 在拷贝前读取、拷贝后重新写入，需要对象 ACL 与标签的读写权限。
+func (s *CryptoObjectService) Rewrap(ctx context.Context, name string, newMaster MasterCipher) (*cos.ObjectCopyResult, *cos.Response, error) {
+	if newMaster == nil {
+		return nil, nil, fmt.Errorf("newMaster is nil")
+	}
+	meta, err := s.ObjectService.Head(ctx, name, nil)
+	if err != nil {
+		return nil, meta, err
+	}
+	if !isEncrypted(&meta.Header) {
+		return nil, meta, fmt.Errorf("object:%v is not client-side encrypted", name)
+	}
+	return s.rewrap(ctx, name, newMaster, meta)
+}
+
+func (s *CryptoObjectService) rewrap(ctx context.Context, name string, newMaster MasterCipher, meta *cos.Response) (*cos.ObjectCopyResult, *cos.Response, error) {
+	cc, err := s.cryptoClient.contentCipherFromHeader(&meta.Header)
+	if err != nil {
+		return nil, meta, fmt.Errorf("%v, object:%v", err, name)
+	}
+	cd := cc.GetCipherData()
+	encryptedKey, err := newMaster.Encrypt(cd.Key)
+	if err != nil {
+		return nil, meta, err
+	}
+	encryptedIV, err := newMaster.Encrypt(cd.IV)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	metaXXX := &http.Header{}
+	for k, v := range meta.Header {
+		if strings.HasPrefix(strings.ToLower(k), "x-cos-meta-") {
+			(*metaXXX)[k] = v
+		}
+	}
+	metaXXX.Del(COSClientSideEncryptionMatDesc)
+	if desc := newMaster.GetMatDesc(); desc != "" {
+		metaXXX.Set(COSClientSideEncryptionMatDesc, desc)
+	}
+	metaXXX.Set(COSClientSideEncryptionKey, base64.StdEncoding.EncodeToString(encryptedKey))
+	metaXXX.Set(COSClientSideEncryptionStart, base64.StdEncoding.EncodeToString(encryptedIV))
+	metaXXX.Set(COSClientSideEncryptionWrapAlg, newMaster.GetWrapAlgorithm())
+
+	opt := &cos.ObjectCopyOptions{
+		ObjectCopyHeaderOptions: &cos.ObjectCopyHeaderOptions{
+			CacheControl:       meta.Header.Get("Cache-Control"),
+			ContentDisposition: meta.Header.Get("Content-Disposition"),
+			ContentEncoding:    meta.Header.Get("Content-Encoding"),
+			ContentLanguage:    meta.Header.Get("Content-Language"),
+			ContentType:        meta.Header.Get("Content-Type"),
+			Expires:            meta.Header.Get("Expires"),
+			// 拷贝期间对象被修改时失败
+			XCosCopySourceIfMatch:    meta.Header.Get("ETag"),
+			XCosMetadataDirective:    "Replaced",
+			XCosStorageClass:         meta.Header.Get("x-cos-storage-class"),
+			XCosServerSideEncryption: meta.Header.Get("x-cos-server-side-encryption"),
+			XCosMetaXXX:              metaXXX,
+			XOptionHeader:            &http.Header{},
+		},
+	}
+	opt.XOptionHeader.Add(UserAgent, s.cryptoClient.userAgent)
+
+	acl, _, err := s.ObjectService.GetACL(ctx, name)
+	if err != nil {
+		return nil, meta, err
+	}
+	tagging, _, err := s.ObjectService.GetTagging(ctx, name)
+	if err != nil && !cos.IsNotFoundError(err) {
+		return nil, meta, err
+	}
+	sourceURL := fmt.Sprintf("%s/%s", s.cryptoClient.BaseURL.BucketURL.Host, name)
+	res, resp, err := s.ObjectService.MultiCopy(ctx, name, sourceURL, &cos.MultiCopyOptions{OptCopy: opt})
+	if err != nil {
+		return res, resp, err
+	}
+	// 只有所有者权限的 ACL 不重新写入，保持继承存储桶的权限
+	if !isOwnerOnlyACL(acl) {
+		if _, err = s.ObjectService.PutACL(ctx, name, &cos.ObjectPutACLOptions{Body: acl}); err != nil {
+			return res, resp, fmt.Errorf("object:%v is rewrapped but failed to restore ACL: %v", name, err)
+		}
+	}
+	if tagging != nil && len(tagging.TagSet) > 0 {
+		if _, err = s.ObjectService.PutTagging(ctx, name, &cos.ObjectPutTaggingOptions{TagSet: tagging.TagSet}); err != nil {
+			return res, resp, fmt.Errorf("object:%v is rewrapped but failed to restore tagging: %v", name, err)
+		}
+	}
+	return res, resp, nil
+}
+
+func isOwnerOnlyACL(acl *cos.ObjectGetACLResult) bool {
+	if acl == nil {
+		return true
+	}
+	for _, grant := range acl.AccessControlList {
+		if grant.Grantee == nil || acl.Owner == nil || grant.Grantee.ID != acl.Owner.ID || grant.Permission != "FULL_CONTROL" {
+			return false
+		}
+	}
+	return true
+}
+
+// RewrapPrefix 对 prefix 下的所有对象执行 Rewrap，未加密的对象以及 MatDesc、WrapAlg 与 newMaster 一致的对象会被跳过。
+// 单个对象失败不会中断处理，失败的对象记录在 RewrapPrefixResult.Failed 中。
+func (s *CryptoObjectService) RewrapPrefix(ctx context.Context, prefix string, newMaster MasterCipher, opt *RewrapPrefixOptions) (*RewrapPrefixResult, error) {
+	if newMaster == nil {
+		return nil, fmt.Errorf("newMaster is nil")
+	}
+	poolSize := 1
+	if opt != nil && opt.ThreadPoolSize > 0 {
+		poolSize = opt.ThreadPoolSize
+	}
+	result := &RewrapPrefixResult{
+		Failed: map[string]error{},
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	keys := make(chan string, 100)
+	for w := 0; w < poolSize; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				rewrapped, err := s.rewrapIfNeeded(ctx, key, newMaster)
+				mu.Lock()
+				if err != nil {
+					result.Failed[key] = err
+				} else if rewrapped {
+					result.Rewrapped++
+				} else {
+					result.Skipped++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+	listOpt := &cos.BucketGetOptions{
+		Prefix:  prefix,
+		MaxKeys: 1000,
+	}
+	for {
+		res, _, lerr := s.cryptoClient.Bucket.Get(ctx, listOpt)
+		if lerr != nil {
+			err = lerr
+			break
+		}
+		for _, object := range res.Contents {
+			keys <- object.Key
+		}
+		if !res.IsTruncated {
+			break
+		}
+		listOpt.Marker = res.NextMarker
+	}
+	close(keys)
+	wg.Wait()
+	return result, err
+}
+
+func (s *CryptoObjectService) rewrapIfNeeded(ctx context.Context, name string, newMaster MasterCipher) (bool, error) {
+	meta, err := s.ObjectService.Head(ctx, name, nil)
+	if err != nil {
+		return false, err
+	}
+	if !isEncrypted(&meta.Header) {
+		return false, nil
+	}
+	if meta.Header.Get(COSClientSideEncryptionMatDesc) == newMaster.GetMatDesc() &&
+		meta.Header.Get(COSClientSideEncryptionWrapAlg) == newMaster.GetWrapAlgorithm() {
+		return false, nil
+	}
+	_, _, err = s.rewrap(ctx, name, newMaster, meta)
+	return err == nil, err
+}
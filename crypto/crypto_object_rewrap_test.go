@@ -0,0 +1,190 @@
+package coscrypto_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"github.com/tencentyun/cos-go-sdk-v5/crypto"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type storedObject struct {
+	data    []byte
+	header  http.Header
+	acl     []byte
+	tagging []byte
+}
+
+// newObjectStoreServer 模拟 COS 的 Put/Get/Head/自拷贝/List 以及对象 ACL、标签接口
+func newObjectStoreServer(objects map[string]*storedObject) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if name == "" && r.Method == http.MethodGet {
+			res := cos.BucketGetResult{Prefix: r.URL.Query().Get("prefix")}
+			for key := range objects {
+				if strings.HasPrefix(key, res.Prefix) {
+					res.Contents = append(res.Contents, cos.Object{Key: key})
+				}
+			}
+			xml.NewEncoder(w).Encode(res)
+			return
+		}
+		meta := func(h http.Header) http.Header {
+			res := http.Header{}
+			for k, v := range h {
+				if strings.HasPrefix(strings.ToLower(k), "x-cos-meta-") || k == "Content-Type" {
+					res[k] = v
+				}
+			}
+			return res
+		}
+		query := r.URL.Query()
+		_, acl := query["acl"]
+		_, tagging := query["tagging"]
+		switch {
+		case acl || tagging:
+			obj, ok := objects[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Method == http.MethodPut {
+				data, _ := ioutil.ReadAll(r.Body)
+				if acl {
+					obj.acl = data
+				} else {
+					obj.tagging = data
+				}
+				return
+			}
+			if acl && obj.acl == nil {
+				fmt.Fprint(w, "<AccessControlPolicy><Owner><ID>owner</ID></Owner><AccessControlList><Grant><Grantee><ID>owner</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant></AccessControlList></AccessControlPolicy>")
+				return
+			}
+			if acl {
+				w.Write(obj.acl)
+			} else if obj.tagging != nil {
+				w.Write(obj.tagging)
+			} else {
+				fmt.Fprint(w, "<Tagging><TagSet></TagSet></Tagging>")
+			}
+		case r.Method == http.MethodPut:
+			if r.Header.Get("x-cos-copy-source") != "" {
+				obj := objects[name]
+				if r.Header.Get("x-cos-metadata-directive") == "Replaced" {
+					// 与 COS 一致，Replaced 自拷贝不保留 ACL 与标签
+					obj.header = meta(r.Header)
+					obj.acl = nil
+					obj.tagging = nil
+				}
+				fmt.Fprint(w, "<CopyObjectResult><ETag>\"copy\"</ETag></CopyObjectResult>")
+				return
+			}
+			data, _ := ioutil.ReadAll(r.Body)
+			objects[name] = &storedObject{data: data, header: meta(r.Header)}
+		case r.Method == http.MethodGet || r.Method == http.MethodHead:
+			obj, ok := objects[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			for k, v := range obj.header {
+				w.Header()[k] = v
+			}
+			http.ServeContent(w, r, "", time.Now(), bytes.NewReader(obj.data))
+		}
+	}))
+}
+
+func (s *CosTestSuite) TestCryptoObjectService_Rewrap() {
+	oldKey := make([]byte, 32)
+	newKey := make([]byte, 32)
+	rand.Read(oldKey)
+	rand.Read(newKey)
+	oldMaster, _ := coscrypto.CreateMasterAESKeyWrap(oldKey, map[string]string{"key": "old"})
+	newMaster, _ := coscrypto.CreateMasterAESKeyWrap(newKey, map[string]string{"key": "new"})
+
+	objects := map[string]*storedObject{}
+	server := newObjectStoreServer(objects)
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := cos.NewClient(&cos.BaseURL{BucketURL: u}, nil)
+	client.Conf.EnableCRC = false
+	oldClient := coscrypto.NewCryptoClient(client, oldMaster)
+
+	originData := make([]byte, 1024*100)
+	rand.Read(originData)
+	for _, name := range []string{"rewrap/a", "rewrap/b"} {
+		_, err := oldClient.Object.Put(context.Background(), name, bytes.NewReader(originData), &cos.ObjectPutOptions{
+			ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+				ContentType: "text/plain",
+				XCosMetaXXX: &http.Header{"X-Cos-Meta-Test": []string{"test"}},
+			},
+		})
+		assert.Nil(s.T(), err, "PutObject Failed")
+	}
+	objects["rewrap/plain"] = &storedObject{data: []byte("plain")}
+
+	_, err := client.Object.PutACL(context.Background(), "rewrap/a", &cos.ObjectPutACLOptions{
+		Body: &cos.ACLXml{
+			Owner: &cos.Owner{ID: "owner"},
+			AccessControlList: []cos.ACLGrant{
+				{Grantee: &cos.ACLGrantee{ID: "owner"}, Permission: "FULL_CONTROL"},
+				{Grantee: &cos.ACLGrantee{URI: "http://cam.qcloud.com/groups/global/AllUsers"}, Permission: "READ"},
+			},
+		},
+	})
+	assert.Nil(s.T(), err, "PutObjectACL Failed")
+	_, err = client.Object.PutTagging(context.Background(), "rewrap/a", &cos.ObjectPutTaggingOptions{
+		TagSet: []cos.ObjectTaggingTag{{Key: "k", Value: "v"}},
+	})
+	assert.Nil(s.T(), err, "PutObjectTagging Failed")
+
+	_, _, err = oldClient.Object.Rewrap(context.Background(), "rewrap/a", newMaster)
+	assert.Nil(s.T(), err, "Rewrap Failed")
+	assert.Equal(s.T(), "test", objects["rewrap/a"].header.Get("x-cos-meta-test"), "metadata should be kept")
+	assert.Equal(s.T(), "text/plain", objects["rewrap/a"].header.Get("Content-Type"), "metadata should be kept")
+	acl, _, err := client.Object.GetACL(context.Background(), "rewrap/a")
+	assert.Nil(s.T(), err, "GetObjectACL Failed")
+	assert.Equal(s.T(), 2, len(acl.AccessControlList), "ACL should be kept")
+	tagging, _, err := client.Object.GetTagging(context.Background(), "rewrap/a")
+	assert.Nil(s.T(), err, "GetObjectTagging Failed")
+	assert.Equal(s.T(), []cos.ObjectTaggingTag{{Key: "k", Value: "v"}}, tagging.TagSet, "tagging should be kept")
+
+	_, _, err = oldClient.Object.Rewrap(context.Background(), "rewrap/plain", newMaster)
+	assert.NotNil(s.T(), err, "Rewrap should fail for unencrypted object")
+
+	// 旧的主密钥无法再解密
+	_, err = oldClient.Object.Get(context.Background(), "rewrap/a", nil)
+	assert.NotNil(s.T(), err, "GetObject should fail with the old master")
+
+	res, err := oldClient.Object.RewrapPrefix(context.Background(), "rewrap/", newMaster, &coscrypto.RewrapPrefixOptions{ThreadPoolSize: 2})
+	assert.Nil(s.T(), err, "RewrapPrefix Failed")
+	assert.Equal(s.T(), 1, res.Rewrapped, "rewrap/b should be rewrapped")
+	assert.Equal(s.T(), 2, res.Skipped, "rewrap/a and rewrap/plain should be skipped")
+	assert.Equal(s.T(), 0, len(res.Failed), "no object should fail")
+	// 只有所有者权限的 ACL 不会被写入
+	assert.Nil(s.T(), objects["rewrap/b"].acl, "owner-only ACL should not be put")
+
+	newClient := coscrypto.NewCryptoClient(client, newMaster)
+	for _, name := range []string{"rewrap/a", "rewrap/b"} {
+		resp, err := newClient.Object.Get(context.Background(), name, nil)
+		assert.Nil(s.T(), err, "GetObject Failed")
+		data, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(s.T(), bytes.Compare(originData, data), 0, "decryptData != originData")
+	}
+}
@@ -190,10 +190,10 @@ func (s *CosTestSuite) TestPutGetDeleteObject_WithMetaData() {
 	m.Write(originData)
 	contentMD5 := m.Sum(nil)
 	opt := &cos.ObjectPutOptions{
-		&cos.ACLHeaderOptions{
+		ACLHeaderOptions: &cos.ACLHeaderOptions{
 			XCosACL: "private",
 		},
-		&cos.ObjectPutHeaderOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
 			ContentLength: 1024*1024*10 + 1,
 			ContentMD5:    base64.StdEncoding.EncodeToString(contentMD5),
 			XCosMetaXXX:   &http.Header{},
@@ -234,10 +234,10 @@ func (s *CosTestSuite) TestPutGetDeleteObject_ByFile() {
 	m.Write(originData)
 	contentMD5 := m.Sum(nil)
 	opt := &cos.ObjectPutOptions{
-		&cos.ACLHeaderOptions{
+		ACLHeaderOptions: &cos.ACLHeaderOptions{
 			XCosACL: "private",
 		},
-		&cos.ObjectPutHeaderOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
 			ContentLength: 1024*1024*10 + 1,
 			ContentMD5:    base64.StdEncoding.EncodeToString(contentMD5),
 			XCosMetaXXX:   &http.Header{},
@@ -380,8 +380,7 @@ func (s *CosTestSuite) TestPutGetDeleteObject_WithListenerAndRange() {
 
 	// 加密存储
 	popt := &cos.ObjectPutOptions{
-		nil,
-		&cos.ObjectPutHeaderOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
 			Listener: &cos.DefaultProgressListener{},
 		},
 	}
@@ -429,8 +428,7 @@ func (s *CosTestSuite) TestPutGetDeleteObject_Copy() {
 
 	// 加密存储
 	popt := &cos.ObjectPutOptions{
-		nil,
-		&cos.ObjectPutHeaderOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
 			Listener: &cos.DefaultProgressListener{},
 		},
 	}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	math_rand "math/rand"
+	"strconv"
 	"time"
 )
 
@@ -19,13 +20,17 @@ const (
 	COSClientSideEncryptionUnencryptedContentMD5           = "x-cos-meta-client-side-encryption-unencrypted-content-md5"
 	COSClientSideEncryptionDataSize                        = "x-cos-meta-client-side-encryption-data-size"
 	COSClientSideEncryptionPartSize                        = "x-cos-meta-client-side-encryption-part-size"
+	COSClientSideEncryptionTagLen                          = "x-cos-meta-client-side-encryption-tag-len"
 	UserAgent                                              = "User-Agent"
 )
 
 const (
-	CosKmsCryptoWrap   = "KMS/TencentCloud"
-	AesCtrAlgorithm    = "AES/CTR/NoPadding"
-	EncryptionUaSuffix = "COSEncryptionClient"
+	CosKmsCryptoWrap        = "KMS/TencentCloud"
+	CosRsaCryptoWrap        = "RSA/ECB/OAEPWithSHA-256AndMGF1Padding"
+	CosAesKeyWrapCryptoWrap = "AESWrapPad"
+	AesCtrAlgorithm         = "AES/CTR/NoPadding"
+	AesGcmAlgorithm         = "AES/GCM/NoPadding"
+	EncryptionUaSuffix      = "COSEncryptionClient"
 )
 
 type MasterCipher interface {
@@ -58,6 +63,8 @@ type Envelope struct {
 	CEKAlg                string
 	UnencryptedMD5        string
 	UnencryptedContentLen string
+	// 认证标签长度(bit)，仅 AES/GCM 使用
+	TagLen string
 }
 
 func (el Envelope) IsValid() bool {
@@ -118,6 +125,11 @@ func (cd *CipherData) GetIV() uint64 {
 }
 
 func (cd *CipherData) SeekIV(startPos uint64) {
+	// GCM 以分块为单位计数
+	if cd.CEKAlgorithm == AesGcmAlgorithm {
+		cd.SetIV(cd.GetIV() + startPos/aesGcmChunkSize)
+		return
+	}
 	cd.SetIV(cd.GetIV() + startPos/uint64(len(cd.IV)))
 }
 
@@ -139,3 +151,44 @@ func (cd *CipherData) Clone() CipherData {
 
 	return cloneCd
 }
+
+// contentCipherEnv 解密 Envelope 中的数据密钥，并根据 CEKAlg 创建对应的 ContentCipher
+func contentCipherEnv(masterCipher MasterCipher, envelope Envelope) (ContentCipher, error) {
+	var cd CipherData
+	cd.EncryptedKey = make([]byte, len(envelope.CipherKey))
+	copy(cd.EncryptedKey, []byte(envelope.CipherKey))
+
+	plainKey, err := masterCipher.Decrypt([]byte(envelope.CipherKey))
+	if err != nil {
+		return nil, err
+	}
+	cd.Key = make([]byte, len(plainKey))
+	copy(cd.Key, plainKey)
+
+	cd.EncryptedIV = make([]byte, len(envelope.IV))
+	copy(cd.EncryptedIV, []byte(envelope.IV))
+
+	plainIV, err := masterCipher.Decrypt([]byte(envelope.IV))
+	if err != nil {
+		return nil, err
+	}
+
+	cd.IV = make([]byte, len(plainIV))
+	copy(cd.IV, plainIV)
+
+	cd.MatDesc = envelope.MatDesc
+	cd.WrapAlgorithm = envelope.WrapAlg
+	cd.CEKAlgorithm = envelope.CEKAlg
+
+	switch envelope.CEKAlg {
+	case AesCtrAlgorithm:
+		return newAesCtrCipher(cd)
+	case AesGcmAlgorithm:
+		if envelope.TagLen != "" && envelope.TagLen != strconv.Itoa(aesGcmTagSize*8) {
+			return nil, fmt.Errorf("unsupported tag length:%v", envelope.TagLen)
+		}
+		return newAesGcmCipher(cd)
+	default:
+		return nil, fmt.Errorf("unsupported content encryption algorithm:%v", envelope.CEKAlg)
+	}
+}
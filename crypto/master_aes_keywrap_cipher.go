@@ -0,0 +1,117 @@
+package coscrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+)
+
+// RFC 5649 Alternative Initial Value 的固定部分
+var keyWrapPadIV = []byte{0xA6, 0x59, 0x59, 0xA6}
+
+// MasterAESKeyWrapCipher 使用本地对称密钥，按 RFC 5649(AES Key Wrap with Padding)加密数据密钥
+type MasterAESKeyWrapCipher struct {
+	block   cipher.Block
+	MatDesc string
+}
+
+// CreateMasterAESKeyWrap 使用 16/24/32 字节的对称密钥创建 MasterCipher
+func CreateMasterAESKeyWrap(key []byte, desc map[string]string) (MasterCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	jdesc, err := marshalMatDesc(desc)
+	if err != nil {
+		return nil, err
+	}
+	return &MasterAESKeyWrapCipher{
+		block:   block,
+		MatDesc: jdesc,
+	}, nil
+}
+
+func (kc *MasterAESKeyWrapCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	if len(plaintext) == 0 {
+		return nil, fmt.Errorf("plaintext is empty")
+	}
+	padded := make([]byte, 8+(len(plaintext)+7)/8*8)
+	copy(padded[0:4], keyWrapPadIV)
+	binary.BigEndian.PutUint32(padded[4:8], uint32(len(plaintext)))
+	copy(padded[8:], plaintext)
+
+	// 只有一个分组时直接使用 AES 加密
+	if len(padded) == 16 {
+		kc.block.Encrypt(padded, padded)
+		return padded, nil
+	}
+
+	// RFC 3394 wrap
+	n := len(padded)/8 - 1
+	var a, b [16]byte
+	copy(a[:8], padded[:8])
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(a[8:], padded[i*8:i*8+8])
+			kc.block.Encrypt(b[:], a[:])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(a[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(padded[i*8:], b[8:])
+		}
+	}
+	copy(padded[:8], a[:8])
+	return padded, nil
+}
+
+func (kc *MasterAESKeyWrapCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 16 || len(ciphertext)%8 != 0 {
+		return nil, fmt.Errorf("invalid wrapped key length:%v", len(ciphertext))
+	}
+	out := make([]byte, len(ciphertext))
+	copy(out, ciphertext)
+
+	if len(out) == 16 {
+		kc.block.Decrypt(out, out)
+	} else {
+		// RFC 3394 unwrap
+		n := len(out)/8 - 1
+		var a, b [16]byte
+		copy(a[:8], out[:8])
+		for j := 5; j >= 0; j-- {
+			for i := n; i >= 1; i-- {
+				t := uint64(n*j + i)
+				binary.BigEndian.PutUint64(a[:8], binary.BigEndian.Uint64(a[:8])^t)
+				copy(a[8:], out[i*8:i*8+8])
+				kc.block.Decrypt(b[:], a[:])
+				copy(a[:8], b[:8])
+				copy(out[i*8:], b[8:])
+			}
+		}
+		copy(out[:8], a[:8])
+	}
+
+	// 校验 AIV 以及填充
+	mli := int(binary.BigEndian.Uint32(out[4:8]))
+	dataLen := len(out) - 8
+	valid := subtle.ConstantTimeCompare(out[0:4], keyWrapPadIV) == 1 &&
+		mli > dataLen-8 && mli <= dataLen
+	if valid {
+		for _, c := range out[8+mli:] {
+			valid = valid && c == 0
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("unwrap key failed, integrity check failed")
+	}
+	return out[8 : 8+mli], nil
+}
+
+func (kc *MasterAESKeyWrapCipher) GetWrapAlgorithm() string {
+	return CosAesKeyWrapCryptoWrap
+}
+
+func (kc *MasterAESKeyWrapCipher) GetMatDesc() string {
+	return kc.MatDesc
+}
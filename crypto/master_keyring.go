@@ -0,0 +1,80 @@
+package coscrypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// MasterCipherResolver 根据对象的 MatDesc 与 WrapAlg 选择解密数据密钥所用的 MasterCipher
+type MasterCipherResolver interface {
+	ResolveMasterCipher(matDesc, wrapAlg string) (MasterCipher, error)
+}
+
+// MasterKeyring 管理多个主密钥，加密时使用 Primary，解密时按对象的 MatDesc 选择主密钥，便于主密钥轮换
+type MasterKeyring struct {
+	primary MasterCipher
+	ciphers []MasterCipher
+	mu      sync.RWMutex
+}
+
+// NewMasterKeyring 创建 MasterKeyring，primary 用于加密，others 仅用于解密历史对象
+func NewMasterKeyring(primary MasterCipher, others ...MasterCipher) *MasterKeyring {
+	kr := &MasterKeyring{primary: primary}
+	kr.Add(primary)
+	for _, c := range others {
+		kr.Add(c)
+	}
+	return kr
+}
+
+// Add 添加用于解密的主密钥
+func (kr *MasterKeyring) Add(c MasterCipher) {
+	if c == nil {
+		return
+	}
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	kr.ciphers = append(kr.ciphers, c)
+}
+
+// ResolveMasterCipher 返回与 matDesc、wrapAlg 匹配的主密钥
+func (kr *MasterKeyring) ResolveMasterCipher(matDesc, wrapAlg string) (MasterCipher, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	for _, c := range kr.ciphers {
+		if c.GetWrapAlgorithm() == wrapAlg && matDescEqual(c.GetMatDesc(), matDesc) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no master cipher matches, matdesc:%v, wrap algorithm:%v", matDesc, wrapAlg)
+}
+
+func (kr *MasterKeyring) Encrypt(plaintext []byte) ([]byte, error) {
+	return kr.primary.Encrypt(plaintext)
+}
+
+func (kr *MasterKeyring) Decrypt(ciphertext []byte) ([]byte, error) {
+	return kr.primary.Decrypt(ciphertext)
+}
+
+func (kr *MasterKeyring) GetWrapAlgorithm() string {
+	return kr.primary.GetWrapAlgorithm()
+}
+
+func (kr *MasterKeyring) GetMatDesc() string {
+	return kr.primary.GetMatDesc()
+}
+
+// matDescEqual 比较两个 MatDesc，JSON 格式时忽略字段顺序
+func matDescEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var ma, mb map[string]string
+	if json.Unmarshal([]byte(a), &ma) != nil || json.Unmarshal([]byte(b), &mb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(ma, mb)
+}
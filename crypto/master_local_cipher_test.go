@@ -0,0 +1,113 @@
+package coscrypto_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"github.com/tencentyun/cos-go-sdk-v5/crypto"
+	"io/ioutil"
+	"net/url"
+)
+
+func (s *CosTestSuite) TestMasterRsaCipher_TestPEM() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(s.T(), err, "GenerateKey Failed")
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(key)
+	pkix, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix})
+	pkcs1PEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	desc := map[string]string{"keyid": "rsa-1"}
+	encrypter, err := coscrypto.CreateMasterRsaFromPEM(publicPEM, desc)
+	assert.Nil(s.T(), err, "CreateMasterRsaFromPEM Failed")
+	assert.Equal(s.T(), coscrypto.CosRsaCryptoWrap, encrypter.GetWrapAlgorithm())
+	assert.Equal(s.T(), `{"keyid":"rsa-1"}`, encrypter.GetMatDesc())
+
+	originData := make([]byte, 32)
+	rand.Read(originData)
+	encryptedData, err := encrypter.Encrypt(originData)
+	assert.Nil(s.T(), err, "Encrypt Failed")
+	_, err = encrypter.Decrypt(encryptedData)
+	assert.NotNil(s.T(), err, "Decrypt without private key should fail")
+
+	for _, data := range [][]byte{privatePEM, pkcs1PEM, append(publicPEM, privatePEM...)} {
+		decrypter, err := coscrypto.CreateMasterRsaFromPEM(data, desc)
+		assert.Nil(s.T(), err, "CreateMasterRsaFromPEM Failed")
+		decryptedData, err := decrypter.Decrypt(encryptedData)
+		assert.Nil(s.T(), err, "Decrypt Failed")
+		assert.Equal(s.T(), bytes.Compare(originData, decryptedData), 0, "originData != decryptedData")
+	}
+	_, err = coscrypto.CreateMasterRsaFromPEM([]byte("invalid"), desc)
+	assert.NotNil(s.T(), err, "CreateMasterRsaFromPEM should fail")
+}
+
+func (s *CosTestSuite) TestMasterAESKeyWrapCipher_TestRFC5649() {
+	kek, _ := hex.DecodeString("5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8")
+	master, err := coscrypto.CreateMasterAESKeyWrap(kek, nil)
+	assert.Nil(s.T(), err, "CreateMasterAESKeyWrap Failed")
+	assert.Equal(s.T(), coscrypto.CosAesKeyWrapCryptoWrap, master.GetWrapAlgorithm())
+
+	vectors := [][2]string{
+		{"c37b7e6492584340bed12207808941155068f738", "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a"},
+		{"466f7250617369", "afbeb0f07dfbf5419200f2ccb50bb24f"},
+	}
+	for _, v := range vectors {
+		plain, _ := hex.DecodeString(v[0])
+		wrapped, err := master.Encrypt(plain)
+		assert.Nil(s.T(), err, "Encrypt Failed")
+		assert.Equal(s.T(), v[1], hex.EncodeToString(wrapped), "wrapped key is wrong")
+		unwrapped, err := master.Decrypt(wrapped)
+		assert.Nil(s.T(), err, "Decrypt Failed")
+		assert.Equal(s.T(), v[0], hex.EncodeToString(unwrapped), "unwrapped key is wrong")
+
+		wrapped[len(wrapped)-1] ^= 0x01
+		_, err = master.Decrypt(wrapped)
+		assert.NotNil(s.T(), err, "Decrypt tampered key should fail")
+	}
+}
+
+func (s *CosTestSuite) TestMasterKeyring_TestRotate() {
+	oldKey := make([]byte, 32)
+	newKey := make([]byte, 32)
+	rand.Read(oldKey)
+	rand.Read(newKey)
+	oldMaster, _ := coscrypto.CreateMasterAESKeyWrap(oldKey, map[string]string{"keyid": "k1"})
+	newMaster, _ := coscrypto.CreateMasterAESKeyWrap(newKey, map[string]string{"keyid": "k2"})
+
+	// 使用旧主密钥加密的对象
+	contentCipher, err := coscrypto.CreateAesGcmBuilder(oldMaster).ContentCipher()
+	assert.Nil(s.T(), err, "ContentCipher Failed")
+	originData := make([]byte, 1024*100)
+	rand.Read(originData)
+	reader, _ := contentCipher.EncryptContent(bytes.NewReader(originData))
+	encryptedData, _ := ioutil.ReadAll(reader)
+	server := newEncryptedObjectServer(encryptedData, contentCipher.GetCipherData(), int64(len(originData)))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	client := cos.NewClient(&cos.BaseURL{BucketURL: u}, nil)
+	client.Conf.EnableCRC = false
+
+	// 只有新主密钥时无法解密
+	_, err = coscrypto.NewCryptoClient(client, newMaster).Object.Get(context.Background(), "test", nil)
+	assert.NotNil(s.T(), err, "GetObject should fail")
+
+	keyring := coscrypto.NewMasterKeyring(newMaster, oldMaster)
+	assert.Equal(s.T(), newMaster.GetMatDesc(), keyring.GetMatDesc())
+	resp, err := coscrypto.NewCryptoClient(client, keyring).Object.Get(context.Background(), "test", nil)
+	assert.Nil(s.T(), err, "GetObject Failed")
+	decryptedData, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Nil(s.T(), err, "GetObject Read Failed")
+	assert.Equal(s.T(), bytes.Compare(originData, decryptedData), 0, "decryptData != originData")
+
+	_, err = keyring.ResolveMasterCipher(`{"keyid":"k3"}`, coscrypto.CosAesKeyWrapCryptoWrap)
+	assert.NotNil(s.T(), err, "ResolveMasterCipher should fail")
+}
@@ -0,0 +1,119 @@
+package coscrypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+)
+
+// MasterRsaCipher 使用本地 RSA 密钥(RSA-OAEP SHA-256)加密数据密钥，只提供公钥时仅能加密
+type MasterRsaCipher struct {
+	PublicKey  *rsa.PublicKey
+	PrivateKey *rsa.PrivateKey
+	MatDesc    string
+}
+
+// CreateMasterRsa 使用 RSA 公私钥创建 MasterCipher，privateKey 为空时只能用于加密
+func CreateMasterRsa(publicKey *rsa.PublicKey, privateKey *rsa.PrivateKey, desc map[string]string) (MasterCipher, error) {
+	if publicKey == nil && privateKey != nil {
+		publicKey = &privateKey.PublicKey
+	}
+	if publicKey == nil {
+		return nil, fmt.Errorf("rsa public key and private key are both empty")
+	}
+	jdesc, err := marshalMatDesc(desc)
+	if err != nil {
+		return nil, err
+	}
+	return &MasterRsaCipher{
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		MatDesc:    jdesc,
+	}, nil
+}
+
+// CreateMasterRsaFromPEM 从 PEM 数据创建 MasterCipher，支持 PKCS#1/PKCS#8 私钥以及 PKCS#1/PKIX 公钥，
+// 可以在同一份数据中同时包含公钥和私钥
+func CreateMasterRsaFromPEM(pemData []byte, desc map[string]string) (MasterCipher, error) {
+	var publicKey *rsa.PublicKey
+	var privateKey *rsa.PrivateKey
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			privateKey = key
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			rsaKey, ok := key.(*rsa.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("private key is not a rsa key")
+			}
+			privateKey = rsaKey
+		case "RSA PUBLIC KEY":
+			key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			publicKey = key
+		case "PUBLIC KEY":
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			rsaKey, ok := key.(*rsa.PublicKey)
+			if !ok {
+				return nil, fmt.Errorf("public key is not a rsa key")
+			}
+			publicKey = rsaKey
+		}
+	}
+	if publicKey == nil && privateKey == nil {
+		return nil, fmt.Errorf("no rsa key found in pem data")
+	}
+	return CreateMasterRsa(publicKey, privateKey, desc)
+}
+
+func (rc *MasterRsaCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, rc.PublicKey, plaintext, nil)
+}
+
+func (rc *MasterRsaCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if rc.PrivateKey == nil {
+		return nil, fmt.Errorf("rsa private key is empty, can't decrypt")
+	}
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, rc.PrivateKey, ciphertext, nil)
+}
+
+func (rc *MasterRsaCipher) GetWrapAlgorithm() string {
+	return CosRsaCryptoWrap
+}
+
+func (rc *MasterRsaCipher) GetMatDesc() string {
+	return rc.MatDesc
+}
+
+func marshalMatDesc(desc map[string]string) (string, error) {
+	if len(desc) == 0 {
+		return "", nil
+	}
+	bs, err := json.Marshal(desc)
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
@@ -3,6 +3,7 @@ package cos
 import (
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -26,6 +27,96 @@ func (r *RetryError) Add(err error) {
 	r.Errs = append(r.Errs, err)
 }
 
+// Unwrap 返回每次重试的错误
+func (r *RetryError) Unwrap() []error {
+	return r.Errs
+}
+
+// Is 任意一次重试的错误匹配 target 即返回 true
+func (r *RetryError) Is(target error) bool {
+	for _, err := range r.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As 返回第一个匹配 target 的重试错误
+func (r *RetryError) As(target interface{}) bool {
+	for _, err := range r.Errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorCode COS 返回的错误码，可以使用 errors.Is(err, cos.ErrNoSuchKey) 判断
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+// 常见的 COS 错误码
+const (
+	ErrNoSuchKey                  ErrorCode = "NoSuchKey"
+	ErrNoSuchBucket               ErrorCode = "NoSuchBucket"
+	ErrNoSuchUpload               ErrorCode = "NoSuchUpload"
+	ErrNoSuchVersion              ErrorCode = "NoSuchVersion"
+	ErrAccessDenied               ErrorCode = "AccessDenied"
+	ErrInvalidRequest             ErrorCode = "InvalidRequest"
+	ErrPreconditionFailed         ErrorCode = "PreconditionFailed"
+	ErrConditionalRequestConflict ErrorCode = "ConditionalRequestConflict"
+	ErrSlowDown                   ErrorCode = "SlowDown"
+	ErrInvalidObjectState         ErrorCode = "InvalidObjectState"
+	ErrBucketAlreadyExists        ErrorCode = "BucketAlreadyExists"
+	ErrBucketAlreadyOwnedByYou    ErrorCode = "BucketAlreadyOwnedByYou"
+	ErrBucketNotEmpty             ErrorCode = "BucketNotEmpty"
+	ErrSignatureDoesNotMatch      ErrorCode = "SignatureDoesNotMatch"
+	ErrRequestTimeTooSkewed       ErrorCode = "RequestTimeTooSkewed"
+	ErrRestoreAlreadyInProgress   ErrorCode = "RestoreAlreadyInProgress"
+)
+
+// HEAD 请求没有响应体，按状态码推断错误码；503 可能是 SlowDown 以外的错误(如 ServiceUnavailable)，不按状态码推断
+var statusErrorCodes = map[int]ErrorCode{
+	http.StatusForbidden:          ErrAccessDenied,
+	http.StatusPreconditionFailed: ErrPreconditionFailed,
+}
+
+// ChecksumMismatchError 数据校验(CRC64/SHA1)失败时返回的错误
+type ChecksumMismatchError struct {
+	// crc64ecma 或 sha1
+	Algorithm string
+	// Want 为本地计算的校验值，Return 为 COS 返回的校验值
+	Want   string
+	Return string
+	Header http.Header
+	// 解析服务端校验值失败时的错误
+	Err error
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("verification failed, %v want:%v, return:%v, err:%v, header:%+v", e.Algorithm, e.Want, e.Return, e.Err, e.Header)
+	}
+	return fmt.Sprintf("verification failed, %v want:%v, return:%v, header:%+v", e.Algorithm, e.Want, e.Return, e.Header)
+}
+
+func newCRC64MismatchError(want, ret uint64, header http.Header, err ...error) *ChecksumMismatchError {
+	e := &ChecksumMismatchError{
+		Algorithm: "crc64ecma",
+		Want:      strconv.FormatUint(want, 10),
+		Return:    strconv.FormatUint(ret, 10),
+		Header:    header,
+	}
+	if len(err) > 0 {
+		e.Err = err[0]
+	}
+	return e
+}
+
 // ErrorResponse 包含 API 返回的错误信息
 //
 // https://www.qcloud.com/document/product/436/7730
@@ -58,6 +149,37 @@ func (r *ErrorResponse) Error() string {
 		r.Response.StatusCode, r.Code, r.Message, RequestID, TraceID)
 }
 
+// ErrorCode 返回错误码，HEAD 等没有响应体的请求按状态码推断
+func (r *ErrorResponse) ErrorCode() ErrorCode {
+	if r.Code != "" {
+		return ErrorCode(r.Code)
+	}
+	if r.Response == nil {
+		return ""
+	}
+	if r.Response.StatusCode == http.StatusNotFound {
+		if r.Response.Request != nil && r.Response.Request.URL != nil && strings.Trim(r.Response.Request.URL.Path, "/") == "" {
+			return ErrNoSuchBucket
+		}
+		return ErrNoSuchKey
+	}
+	return statusErrorCodes[r.Response.StatusCode]
+}
+
+// Is 支持 errors.Is(err, cos.ErrNoSuchKey)
+func (r *ErrorResponse) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code != "" && r.ErrorCode() == code
+}
+
+// Unwrap 返回错误码，便于通过 errors.As 获取 ErrorCode
+func (r *ErrorResponse) Unwrap() error {
+	if code := r.ErrorCode(); code != "" {
+		return code
+	}
+	return nil
+}
+
 type jsonError struct {
 	Code      int    `json:"code,omitempty"`
 	Message   string `json:"message,omitempty"`
@@ -90,11 +212,8 @@ func checkResponse(r *http.Response) error {
 }
 
 func IsNotFoundError(e error) bool {
-	if e == nil {
-		return false
-	}
-	err, ok := e.(*ErrorResponse)
-	if !ok {
+	var err *ErrorResponse
+	if !errors.As(e, &err) {
 		return false
 	}
 	if err.Response != nil && err.Response.StatusCode == 404 {
@@ -104,9 +223,7 @@ func IsNotFoundError(e error) bool {
 }
 
 func IsCOSError(e error) (*ErrorResponse, bool) {
-	if e == nil {
-		return nil, false
-	}
-	err, ok := e.(*ErrorResponse)
+	var err *ErrorResponse
+	ok := errors.As(e, &err)
 	return err, ok
 }
@@ -1,6 +1,7 @@
 package cos
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -193,3 +194,83 @@ func Test_ErrorResponse(t *testing.T) {
 		t.Errorf("error message is invalid, return: %v, except: %v", err.Error(), except)
 	}
 }
+
+func Test_ErrorCode(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test_404", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+		}
+	})
+	mux.HandleFunc("/test_412", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+	})
+	for _, code := range []string{"SlowDown", "ServiceUnavailable"} {
+		code := code
+		mux.HandleFunc("/test_503_"+code, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, `<Error><Code>%v</Code></Error>`, code)
+		})
+	}
+
+	_, err := client.Object.Get(context.Background(), "test_404", nil)
+	if !errors.Is(err, ErrNoSuchKey) || errors.Is(err, ErrNoSuchBucket) {
+		t.Errorf("errors.Is returned unexpected result for %v", err)
+	}
+	var code ErrorCode
+	if !errors.As(err, &code) || code != ErrNoSuchKey {
+		t.Errorf("errors.As returned %v, want %v", code, ErrNoSuchKey)
+	}
+	// HEAD 请求没有响应体
+	_, err = client.Object.Head(context.Background(), "test_404", nil)
+	if !errors.Is(err, ErrNoSuchKey) {
+		t.Errorf("errors.Is(%v, ErrNoSuchKey) returned false", err)
+	}
+	// 503 只按错误码判断是否为 SlowDown
+	_, err = client.Object.Get(context.Background(), "test_503_SlowDown", nil)
+	if !errors.Is(err, ErrSlowDown) {
+		t.Errorf("errors.Is(%v, ErrSlowDown) returned false", err)
+	}
+	_, err = client.Object.Get(context.Background(), "test_503_ServiceUnavailable", nil)
+	if errors.Is(err, ErrSlowDown) {
+		t.Errorf("errors.Is(%v, ErrSlowDown) returned true", err)
+	}
+	_, err = client.Object.Head(context.Background(), "test_503_ServiceUnavailable", nil)
+	if errors.Is(err, ErrSlowDown) {
+		t.Errorf("errors.Is(%v, ErrSlowDown) returned true", err)
+	}
+	_, err = client.Object.Head(context.Background(), "test_412", nil)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("errors.Is(%v, ErrPreconditionFailed) returned false", err)
+	}
+
+	var retryErr RetryError
+	retryErr.Add(errors.New("timeout"))
+	retryErr.Add(err)
+	if !errors.Is(&retryErr, ErrPreconditionFailed) || errors.Is(&retryErr, ErrNoSuchKey) {
+		t.Errorf("RetryError.Is returned unexpected result")
+	}
+	if e, ok := IsCOSError(&retryErr); !ok || e.Response.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("IsCOSError(RetryError) returned %v, %v", e, ok)
+	}
+}
+
+func Test_ChecksumMismatchError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-cos-hash-crc64ecma", "1")
+	})
+	_, err := client.Object.Put(context.Background(), "test", strings.NewReader("test"), nil)
+	var e *ChecksumMismatchError
+	if !errors.As(err, &e) {
+		t.Fatalf("Object.Put returned %v, want ChecksumMismatchError", err)
+	}
+	if e.Algorithm != "crc64ecma" || e.Want != "18020588380933092773" || e.Return != "1" {
+		t.Errorf("ChecksumMismatchError is %+v", e)
+	}
+}
@@ -212,20 +212,24 @@ func CopyOptionsToMulti(opt *ObjectCopyOptions) *InitiateMultipartUploadOptions
 		return optini
 	}
 	optini.ObjectPutHeaderOptions = &ObjectPutHeaderOptions{
-		CacheControl:             opt.ObjectCopyHeaderOptions.CacheControl,
-		ContentDisposition:       opt.ObjectCopyHeaderOptions.ContentDisposition,
-		ContentEncoding:          opt.ObjectCopyHeaderOptions.ContentEncoding,
-		ContentType:              opt.ObjectCopyHeaderOptions.ContentType,
-		ContentLanguage:          opt.ObjectCopyHeaderOptions.ContentLanguage,
-		Expect:                   opt.ObjectCopyHeaderOptions.Expect,
-		Expires:                  opt.ObjectCopyHeaderOptions.Expires,
-		XCosMetaXXX:              opt.ObjectCopyHeaderOptions.XCosMetaXXX,
-		XCosStorageClass:         opt.ObjectCopyHeaderOptions.XCosStorageClass,
-		XCosServerSideEncryption: opt.ObjectCopyHeaderOptions.XCosServerSideEncryption,
-		XCosSSECustomerAglo:      opt.ObjectCopyHeaderOptions.XCosSSECustomerAglo,
-		XCosSSECustomerKey:       opt.ObjectCopyHeaderOptions.XCosSSECustomerKey,
-		XCosSSECustomerKeyMD5:    opt.ObjectCopyHeaderOptions.XCosSSECustomerKeyMD5,
-		XOptionHeader:            opt.ObjectCopyHeaderOptions.XOptionHeader,
+		CacheControl:                  opt.ObjectCopyHeaderOptions.CacheControl,
+		ContentDisposition:            opt.ObjectCopyHeaderOptions.ContentDisposition,
+		ContentEncoding:               opt.ObjectCopyHeaderOptions.ContentEncoding,
+		ContentType:                   opt.ObjectCopyHeaderOptions.ContentType,
+		ContentLanguage:               opt.ObjectCopyHeaderOptions.ContentLanguage,
+		Expect:                        opt.ObjectCopyHeaderOptions.Expect,
+		Expires:                       opt.ObjectCopyHeaderOptions.Expires,
+		XCosMetaXXX:                   opt.ObjectCopyHeaderOptions.XCosMetaXXX,
+		XCosStorageClass:              opt.ObjectCopyHeaderOptions.XCosStorageClass,
+		XCosObjectLockMode:            opt.ObjectCopyHeaderOptions.XCosObjectLockMode,
+		XCosObjectLockRetainUntilDate: opt.ObjectCopyHeaderOptions.XCosObjectLockRetainUntilDate,
+		XCosObjectLockLegalHold:       opt.ObjectCopyHeaderOptions.XCosObjectLockLegalHold,
+		XCosServerSideEncryption:      opt.ObjectCopyHeaderOptions.XCosServerSideEncryption,
+		XCosSSECustomerAglo:           opt.ObjectCopyHeaderOptions.XCosSSECustomerAglo,
+		XCosSSECustomerKey:            opt.ObjectCopyHeaderOptions.XCosSSECustomerKey,
+		XCosSSECustomerKeyMD5:         opt.ObjectCopyHeaderOptions.XCosSSECustomerKeyMD5,
+		SSE:                           opt.ObjectCopyHeaderOptions.SSE,
+		XOptionHeader:                 opt.ObjectCopyHeaderOptions.XOptionHeader,
 	}
 	return optini
 }
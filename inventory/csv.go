@@ -0,0 +1,48 @@
+package inventory
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+)
+
+// csvDecoder 解析 CSV 格式的数据文件，没有表头，字段顺序与 fileSchema 一致
+type csvDecoder struct{}
+
+func (csvDecoder) NewRecordReader(r io.Reader, fields []string) (RecordReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(fields)
+	cr.ReuseRecord = true
+	return &csvRecordReader{r: cr, fields: fields}, nil
+}
+
+type csvRecordReader struct {
+	r      *csv.Reader
+	fields []string
+}
+
+func (c *csvRecordReader) Read() (*Record, error) {
+	values, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	return ParseRecord(c.fields, values)
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func newGzipReadCloser(body io.ReadCloser) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, err
+	}
+	return &gzipReadCloser{Reader: zr, body: body}, nil
+}
+
+func (g *gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
@@ -0,0 +1,383 @@
+// Package inventory 读取存储桶清单(Inventory)报告。
+//
+// 清单报告由 manifest.json 与若干数据文件组成，Reader 读取 manifest.json 后依次下载数据文件，
+// 以 Record 的形式流式返回每个对象，可以代替 ListObjects 分页遍历整个存储桶。
+// 内置 CSV(gzip 压缩)格式的解析，ORC 与 Parquet 格式可以通过 RegisterDecoder 注册。
+package inventory
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// 清单报告的格式
+const (
+	FormatCSV     = "CSV"
+	FormatORC     = "ORC"
+	FormatParquet = "Parquet"
+)
+
+// ManifestFile manifest.json 中的一个数据文件
+type ManifestFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5Checksum string `json:"MD5checksum"`
+}
+
+// Manifest 清单报告的 manifest.json
+type Manifest struct {
+	SourceBucket      string         `json:"sourceBucket"`
+	DestinationBucket string         `json:"destinationBucket"`
+	Version           string         `json:"version"`
+	CreationTimestamp string         `json:"creationTimestamp"`
+	FileFormat        string         `json:"fileFormat"`
+	FileSchema        string         `json:"fileSchema"`
+	Files             []ManifestFile `json:"files"`
+}
+
+// ParseManifest 解析 manifest.json
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m.FileFormat == "" {
+		return nil, fmt.Errorf("fileFormat not found in manifest")
+	}
+	return &m, nil
+}
+
+// Fields 返回数据文件的字段，如 Bucket、Key、Size
+func (m *Manifest) Fields() []string {
+	var fields []string
+	for _, f := range strings.Split(m.FileSchema, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// CreationTime 清单报告的生成时间
+func (m *Manifest) CreationTime() time.Time {
+	sec, err := strconv.ParseInt(m.CreationTimestamp, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	if sec > 1e12 {
+		return time.Unix(0, sec*int64(time.Millisecond))
+	}
+	return time.Unix(sec, 0)
+}
+
+// Record 清单报告中的一个对象
+type Record struct {
+	Bucket              string
+	Key                 string
+	VersionId           string
+	IsLatest            bool
+	IsDeleteMarker      bool
+	Size                int64
+	LastModified        time.Time
+	ETag                string
+	StorageClass        string
+	IsMultipartUploaded bool
+	ReplicationStatus   string
+	Encryption          string
+	Tags                map[string]string
+	// 其它字段的原始值，以 fileSchema 中的字段名为键
+	Extra map[string]string
+}
+
+// ParseRecord 按字段名将一行数据转换为 Record，供各格式的 Decoder 使用
+func ParseRecord(fields, values []string) (*Record, error) {
+	if len(values) != len(fields) {
+		return nil, fmt.Errorf("record has %d values, but schema has %d fields", len(values), len(fields))
+	}
+	r := &Record{}
+	for i, field := range fields {
+		v := values[i]
+		var err error
+		switch strings.ToLower(field) {
+		case "bucket":
+			r.Bucket = v
+		case "key":
+			// Key 为 URL 编码
+			if r.Key, err = url.QueryUnescape(v); err != nil {
+				r.Key, err = v, nil
+			}
+		case "versionid":
+			r.VersionId = v
+		case "islatest":
+			r.IsLatest = strings.EqualFold(v, "true")
+		case "isdeletemarker":
+			r.IsDeleteMarker = strings.EqualFold(v, "true")
+		case "size":
+			if v != "" {
+				r.Size, err = strconv.ParseInt(v, 10, 64)
+			}
+		case "lastmodifieddate", "lastmodified":
+			if v != "" {
+				r.LastModified, err = time.Parse(time.RFC3339, v)
+			}
+		case "etag":
+			r.ETag = strings.Trim(v, "\"")
+		case "storageclass":
+			r.StorageClass = v
+		case "ismultipartuploaded":
+			r.IsMultipartUploaded = strings.EqualFold(v, "true")
+		case "replicationstatus":
+			r.ReplicationStatus = v
+		case "encryption", "encryptionstatus", "encryptiontype":
+			r.Encryption = v
+		case "tag", "tags":
+			r.Tags, err = parseTags(v)
+		default:
+			if r.Extra == nil {
+				r.Extra = map[string]string{}
+			}
+			r.Extra[field] = v
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v %q: %v", field, v, err)
+		}
+	}
+	return r, nil
+}
+
+// parseTags 标签为 URL 编码的 key1=value1&key2=value2
+func parseTags(v string) (map[string]string, error) {
+	if v == "" {
+		return nil, nil
+	}
+	q, err := url.ParseQuery(v)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(q))
+	for k, vs := range q {
+		tags[k] = vs[0]
+	}
+	return tags, nil
+}
+
+// RecordReader 逐条读取一个数据文件中的记录，读完时返回 io.EOF
+type RecordReader interface {
+	Read() (*Record, error)
+}
+
+// Decoder 解析一种格式的数据文件。r 为下载的数据文件内容，fields 为 manifest 中的字段；
+// ORC、Parquet 等格式需要随机读取时可以先将 r 读入内存或临时文件
+type Decoder interface {
+	NewRecordReader(r io.Reader, fields []string) (RecordReader, error)
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{
+		strings.ToUpper(FormatCSV): csvDecoder{},
+	}
+)
+
+// RegisterDecoder 注册 format 格式的 Decoder，会覆盖已有的 Decoder
+func RegisterDecoder(format string, d Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[strings.ToUpper(format)] = d
+}
+
+func lookupDecoder(format string) (Decoder, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	d, ok := decoders[strings.ToUpper(format)]
+	return d, ok
+}
+
+// Reader 流式读取清单报告中的所有记录
+type Reader struct {
+	ctx      context.Context
+	client   *cos.Client
+	manifest *Manifest
+	decoder  Decoder
+	fields   []string
+
+	next int
+	body io.ReadCloser
+	raw  *md5Reader
+	rr   RecordReader
+}
+
+// ReadManifest 从 client 所在的存储桶(清单报告的目标存储桶)下载并解析 manifest.json
+func ReadManifest(ctx context.Context, client *cos.Client, key string) (*Manifest, error) {
+	resp, err := client.Object.Get(ctx, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseManifest(data)
+}
+
+// NewReader 创建读取清单报告的 Reader，client 为清单报告的目标存储桶
+func NewReader(ctx context.Context, client *cos.Client, m *Manifest) (*Reader, error) {
+	d, ok := lookupDecoder(m.FileFormat)
+	if !ok {
+		return nil, fmt.Errorf("no decoder registered for inventory format %v", m.FileFormat)
+	}
+	return &Reader{
+		ctx:      ctx,
+		client:   client,
+		manifest: m,
+		decoder:  d,
+		fields:   m.Fields(),
+	}, nil
+}
+
+// Manifest 返回清单报告的 manifest
+func (r *Reader) Manifest() *Manifest {
+	return r.manifest
+}
+
+// Next 返回下一条记录，全部读完时返回 io.EOF
+func (r *Reader) Next() (*Record, error) {
+	for {
+		if r.rr == nil {
+			if r.next >= len(r.manifest.Files) {
+				return nil, io.EOF
+			}
+			if err := r.open(r.manifest.Files[r.next]); err != nil {
+				return nil, err
+			}
+			r.next++
+		}
+		rec, err := r.rr.Read()
+		if err == io.EOF {
+			// 解码器不一定读完原始数据，读完剩余的数据以校验 MD5
+			_, err = io.Copy(ioutil.Discard, r.raw)
+			r.closeFile()
+			if err != nil {
+				return nil, fmt.Errorf("read inventory file %v failed: %v", r.manifest.Files[r.next-1].Key, err)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read inventory file %v failed: %v", r.manifest.Files[r.next-1].Key, err)
+		}
+		return rec, nil
+	}
+}
+
+func (r *Reader) open(f ManifestFile) error {
+	resp, err := r.client.Object.Get(r.ctx, f.Key, nil)
+	if err != nil {
+		return err
+	}
+	// 在解压之前校验下载的原始数据
+	raw := &md5Reader{ReadCloser: resp.Body, hash: md5.New(), want: f.MD5Checksum}
+	body := io.ReadCloser(raw)
+	if strings.HasSuffix(f.Key, ".gz") {
+		if body, err = newGzipReadCloser(raw); err != nil {
+			raw.Close()
+			return fmt.Errorf("read inventory file %v failed: %v", f.Key, err)
+		}
+	}
+	rr, err := r.decoder.NewRecordReader(body, r.fields)
+	if err != nil {
+		body.Close()
+		return fmt.Errorf("read inventory file %v failed: %v", f.Key, err)
+	}
+	r.body, r.raw, r.rr = body, raw, rr
+	return nil
+}
+
+func (r *Reader) closeFile() {
+	if r.body != nil {
+		r.body.Close()
+	}
+	r.body, r.raw, r.rr = nil, nil, nil
+}
+
+// md5Reader 读到 EOF 时校验数据的 MD5 与 manifest.json 中的 MD5checksum 是否一致，want 为空时不校验
+type md5Reader struct {
+	io.ReadCloser
+	hash hash.Hash
+	want string
+	err  error
+}
+
+func (m *md5Reader) Read(p []byte) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	n, err := m.ReadCloser.Read(p)
+	m.hash.Write(p[:n])
+	if err == io.EOF && m.want != "" {
+		if got := hex.EncodeToString(m.hash.Sum(nil)); !strings.EqualFold(got, m.want) {
+			err = fmt.Errorf("md5 mismatch, manifest: %v, downloaded: %v", m.want, got)
+		}
+	}
+	m.err = err
+	return n, err
+}
+
+// Close 关闭正在读取的数据文件
+func (r *Reader) Close() error {
+	r.closeFile()
+	r.next = len(r.manifest.Files)
+	return nil
+}
+
+// Scan 读取 manifestKey 对应的清单报告，对每条记录调用 fn，fn 返回错误时停止
+func Scan(ctx context.Context, client *cos.Client, manifestKey string, fn func(*Record) error) error {
+	m, err := ReadManifest(ctx, client, manifestKey)
+	if err != nil {
+		return err
+	}
+	r, err := NewReader(ctx, client, m)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// LifecycleObject 转换为 cos.SimulateLifecycle 使用的对象
+func (r *Record) LifecycleObject() cos.LifecycleObject {
+	return cos.LifecycleObject{
+		Key:            r.Key,
+		VersionId:      r.VersionId,
+		Size:           r.Size,
+		StorageClass:   r.StorageClass,
+		LastModified:   r.LastModified,
+		Noncurrent:     r.VersionId != "" && !r.IsLatest,
+		IsDeleteMarker: r.IsDeleteMarker,
+		Tags:           r.Tags,
+	}
+}
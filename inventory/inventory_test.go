@@ -0,0 +1,207 @@
+package inventory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+const testManifest = `{
+	"sourceBucket": "examplebucket-1250000000",
+	"destinationBucket": "qcs::cos:ap-guangzhou::destbucket-1250000000",
+	"version": "2019-07-01",
+	"creationTimestamp": "1598258400",
+	"fileFormat": "CSV",
+	"fileSchema": "Bucket, Key, Size, LastModifiedDate, ETag, StorageClass, ReplicationStatus, EncryptionStatus, Tag, IsMultipartUploaded",
+	"files": [
+		{"key": "inventory/data/1.csv.gz", "size": 100, "MD5checksum": "%x"},
+		{"key": "inventory/data/2.csv", "size": 100, "MD5checksum": "%x"}
+	]
+}`
+
+func gzipData(s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.Bytes()
+}
+
+func newTestClient(t *testing.T, files map[string][]byte) (*cos.Client, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}))
+	u, _ := url.Parse(server.URL)
+	return cos.NewClient(&cos.BaseURL{BucketURL: u}, nil), server.Close
+}
+
+// testFiles 返回清单报告的文件，manifest.json 中是数据文件真实的 MD5
+func testFiles() map[string][]byte {
+	data1 := gzipData(
+		`"examplebucket-1250000000","dir%2Fa+b.txt","10","2024-01-02T03:04:05.000Z","""etag1""","STANDARD","COMPLETED","SSE-COS","k1=v1&k2=v%262","false"` + "\n" +
+			`"examplebucket-1250000000","c.txt","20","2024-01-03T03:04:05.000Z","etag2","ARCHIVE","","","","true"` + "\n")
+	data2 := []byte(`"examplebucket-1250000000","d.txt","30","2024-01-04T03:04:05.000Z","etag3","STANDARD_IA","","","","false"` + "\n")
+	return map[string][]byte{
+		"inventory/manifest.json": []byte(fmt.Sprintf(testManifest, md5.Sum(data1), md5.Sum(data2))),
+		"inventory/data/1.csv.gz": data1,
+		"inventory/data/2.csv":    data2,
+	}
+}
+
+func TestScan(t *testing.T) {
+	client, closeFn := newTestClient(t, testFiles())
+	defer closeFn()
+
+	var records []*Record
+	err := Scan(context.Background(), client, "inventory/manifest.json", func(r *Record) error {
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Scan returned %d records, want 3", len(records))
+	}
+	want := &Record{
+		Bucket:            "examplebucket-1250000000",
+		Key:               "dir/a b.txt",
+		Size:              10,
+		LastModified:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		ETag:              "etag1",
+		StorageClass:      "STANDARD",
+		ReplicationStatus: "COMPLETED",
+		Encryption:        "SSE-COS",
+		Tags:              map[string]string{"k1": "v1", "k2": "v&2"},
+	}
+	if !reflect.DeepEqual(records[0], want) {
+		t.Errorf("Scan returned %+v, want %+v", records[0], want)
+	}
+	if records[1].Key != "c.txt" || !records[1].IsMultipartUploaded || records[1].Tags != nil {
+		t.Errorf("Scan returned %+v", records[1])
+	}
+	if records[2].Key != "d.txt" || records[2].Size != 30 {
+		t.Errorf("Scan returned %+v", records[2])
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = Scan(context.Background(), client, "inventory/manifest.json", func(r *Record) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Errorf("Scan returned %v after %d records, want stop after 1", err, n)
+	}
+}
+
+func TestScan_MD5Mismatch(t *testing.T) {
+	// 替换为与 manifest.json 中 MD5 不一致、但仍然可以解析的数据
+	line := `"examplebucket-1250000000","e.txt","40","2024-01-05T03:04:05.000Z","etag4","STANDARD","","","","false"` + "\n"
+	for key, data := range map[string][]byte{
+		"inventory/data/1.csv.gz": gzipData(line),
+		"inventory/data/2.csv":    []byte(line),
+	} {
+		files := testFiles()
+		files[key] = data
+		client, closeFn := newTestClient(t, files)
+
+		err := Scan(context.Background(), client, "inventory/manifest.json", func(r *Record) error {
+			return nil
+		})
+		closeFn()
+		if err == nil || !strings.Contains(err.Error(), key) || !strings.Contains(err.Error(), "md5 mismatch") {
+			t.Errorf("Scan with corrupted %v returned %v, want md5 mismatch", key, err)
+		}
+	}
+}
+
+type lineDecoder struct{}
+
+type lineReader struct {
+	lines  []string
+	fields []string
+}
+
+func (lineDecoder) NewRecordReader(r io.Reader, fields []string) (RecordReader, error) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return &lineReader{lines: strings.Fields(buf.String()), fields: fields}, nil
+}
+
+func (l *lineReader) Read() (*Record, error) {
+	if len(l.lines) == 0 {
+		return nil, io.EOF
+	}
+	line := l.lines[0]
+	l.lines = l.lines[1:]
+	return ParseRecord(l.fields, strings.Split(line, "|"))
+}
+
+func TestReader_RegisterDecoder(t *testing.T) {
+	m, err := ParseManifest([]byte(`{"fileFormat": "Parquet", "fileSchema": "Key, Size, Custom", "files": [{"key": "1.parquet"}]}`))
+	if err != nil {
+		t.Fatalf("ParseManifest returned error: %v", err)
+	}
+	client, closeFn := newTestClient(t, map[string][]byte{"1.parquet": []byte("a|1|x b|2|y")})
+	defer closeFn()
+
+	if _, err := NewReader(context.Background(), client, m); err == nil {
+		t.Fatalf("NewReader should fail without a Parquet decoder")
+	}
+	RegisterDecoder(FormatParquet, lineDecoder{})
+	defer func() {
+		decodersMu.Lock()
+		delete(decoders, strings.ToUpper(FormatParquet))
+		decodersMu.Unlock()
+	}()
+	r, err := NewReader(context.Background(), client, m)
+	if err != nil {
+		t.Fatalf("NewReader returned error: %v", err)
+	}
+	defer r.Close()
+	var got []string
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Reader.Next returned error: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%v:%v:%v", rec.Key, rec.Size, rec.Extra["Custom"]))
+	}
+	want := []string{"a:1:x", "b:2:y"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reader returned %v, want %v", got, want)
+	}
+}
+
+func TestParseRecord_Invalid(t *testing.T) {
+	if _, err := ParseRecord([]string{"Key", "Size"}, []string{"a"}); err == nil {
+		t.Errorf("ParseRecord should fail on field count mismatch")
+	}
+	if _, err := ParseRecord([]string{"Size"}, []string{"abc"}); err == nil {
+		t.Errorf("ParseRecord should fail on invalid size")
+	}
+}
@@ -0,0 +1,248 @@
+// Package logs 解析通过存储桶日志(PutLogging)投递到 TargetBucket/TargetPrefix 的 COS 访问日志。
+//
+// 每个日志对象的每一行为一条访问记录，字段以空格分隔，空值为 "-"，含空格的字段为 URL 编码。
+// Reader 列出并流式读取日志对象，Parser 将每一行解析为 AccessLogEntry，Stats 提供常用的聚合统计。
+package logs
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFields 访问日志的字段顺序
+var DefaultFields = []string{
+	"eventVersion", "bucketName", "qcsRegion", "eventTime", "eventSource", "eventName",
+	"remoteIp", "userSecretKeyId", "reservedField", "reqBytesSent", "deltaDataSize", "reqPath",
+	"reqMethod", "userAgent", "resHttpCode", "resErrorCode", "resErrorMsg", "resBytesSent",
+	"resTotalTime", "logSourceType", "storageClass", "accountId", "resTurnAroundTime", "requester",
+	"requestId", "objectSize", "versionId", "targetStorageClass", "referer", "requestUri", "vpcId",
+}
+
+// AccessLogEntry 一条访问日志
+type AccessLogEntry struct {
+	Version string
+	Bucket  string
+	Region  string
+	Time    time.Time
+	// 访问的域名
+	Source string
+	// 操作，如 GetObject、PutObject
+	Operation   string
+	RemoteIP    string
+	SecretKeyID string
+	Method      string
+	// 对象键，不含开头的 /
+	Key        string
+	RequestURI string
+	UserAgent  string
+	Referer    string
+	Status     int
+	ErrorCode  string
+	ErrorMsg   string
+	// 请求与响应的字节数
+	RequestBytes int64
+	Bytes        int64
+	// 请求的总耗时与服务端处理耗时
+	Latency        time.Duration
+	TurnAroundTime time.Duration
+	Requester      string
+	RequestID      string
+	AccountID      string
+	StorageClass   string
+	ObjectSize     int64
+	VersionID      string
+	// 其它字段的原始值
+	Extra map[string]string
+}
+
+// IsError 请求是否失败(状态码不小于 400)
+func (e *AccessLogEntry) IsError() bool {
+	return e.Status >= 400
+}
+
+// Parser 解析访问日志的行
+type Parser struct {
+	// 字段顺序，为空时使用 DefaultFields
+	Fields []string
+}
+
+// ParseLine 使用 DefaultFields 解析一行访问日志
+func ParseLine(line string) (*AccessLogEntry, error) {
+	return (&Parser{}).ParseLine(line)
+}
+
+// ParseLine 解析一行访问日志
+func (p *Parser) ParseLine(line string) (*AccessLogEntry, error) {
+	fields := p.Fields
+	if len(fields) == 0 {
+		fields = DefaultFields
+	}
+	values := strings.Fields(line)
+	if len(values) < len(fields) {
+		return nil, fmt.Errorf("access log has %d fields, want %d", len(values), len(fields))
+	}
+	e := &AccessLogEntry{}
+	for i, field := range fields {
+		v := values[i]
+		if v == "-" {
+			continue
+		}
+		var err error
+		switch field {
+		case "eventVersion":
+			e.Version = v
+		case "bucketName":
+			e.Bucket = v
+		case "qcsRegion":
+			e.Region = v
+		case "eventTime":
+			e.Time, err = parseLogTime(v)
+		case "eventSource":
+			e.Source = v
+		case "eventName":
+			e.Operation = v
+		case "remoteIp":
+			e.RemoteIP = v
+		case "userSecretKeyId":
+			e.SecretKeyID = v
+		case "reqBytesSent":
+			e.RequestBytes, err = strconv.ParseInt(v, 10, 64)
+		case "reqPath":
+			e.Key = strings.TrimPrefix(unescape(v), "/")
+		case "reqMethod":
+			e.Method = v
+		case "userAgent":
+			e.UserAgent = unescape(v)
+		case "resHttpCode":
+			e.Status, err = strconv.Atoi(v)
+		case "resErrorCode":
+			e.ErrorCode = v
+		case "resErrorMsg":
+			e.ErrorMsg = unescape(v)
+		case "resBytesSent":
+			e.Bytes, err = strconv.ParseInt(v, 10, 64)
+		case "resTotalTime":
+			e.Latency, err = parseMillis(v)
+		case "resTurnAroundTime":
+			e.TurnAroundTime, err = parseMillis(v)
+		case "storageClass":
+			e.StorageClass = v
+		case "accountId":
+			e.AccountID = v
+		case "requester":
+			e.Requester = v
+		case "requestId":
+			e.RequestID = v
+		case "objectSize":
+			e.ObjectSize, err = strconv.ParseInt(v, 10, 64)
+		case "versionId":
+			e.VersionID = v
+		case "referer":
+			e.Referer = unescape(v)
+		case "requestUri":
+			e.RequestURI = unescape(v)
+		default:
+			if e.Extra == nil {
+				e.Extra = map[string]string{}
+			}
+			e.Extra[field] = v
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v %q: %v", field, v, err)
+		}
+	}
+	return e, nil
+}
+
+func unescape(v string) string {
+	if s, err := url.QueryUnescape(v); err == nil {
+		return s
+	}
+	return v
+}
+
+// parseLogTime 支持 ISO8601 时间与 Unix 时间戳(秒或毫秒)
+func parseLogTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if n > 1e12 {
+		return time.Unix(0, n*int64(time.Millisecond)), nil
+	}
+	return time.Unix(n, 0), nil
+}
+
+func parseMillis(v string) (time.Duration, error) {
+	ms, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms * float64(time.Millisecond)), nil
+}
+
+// Filter 过滤访问日志，返回 true 时保留
+type Filter func(e *AccessLogEntry) bool
+
+// And 所有过滤条件都满足时保留
+func And(filters ...Filter) Filter {
+	return func(e *AccessLogEntry) bool {
+		for _, f := range filters {
+			if f != nil && !f(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// TimeRange 保留 [start, end) 之间的日志，零值表示不限制
+func TimeRange(start, end time.Time) Filter {
+	return func(e *AccessLogEntry) bool {
+		return (start.IsZero() || !e.Time.Before(start)) && (end.IsZero() || e.Time.Before(end))
+	}
+}
+
+// Operations 保留指定操作的日志
+func Operations(ops ...string) Filter {
+	return func(e *AccessLogEntry) bool {
+		for _, op := range ops {
+			if strings.EqualFold(op, e.Operation) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// KeyPrefix 保留对象键以 prefix 开头的日志
+func KeyPrefix(prefix string) Filter {
+	return func(e *AccessLogEntry) bool {
+		return strings.HasPrefix(e.Key, prefix)
+	}
+}
+
+// Requesters 保留指定访问者的日志
+func Requesters(requesters ...string) Filter {
+	return func(e *AccessLogEntry) bool {
+		for _, r := range requesters {
+			if r == e.Requester {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Errors 只保留失败的请求
+func Errors() Filter {
+	return func(e *AccessLogEntry) bool {
+		return e.IsError()
+	}
+}
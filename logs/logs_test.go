@@ -0,0 +1,157 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+func logLine(tm, op, key, requester string, status int, bytes int64) string {
+	values := map[string]string{
+		"eventVersion":  "1.0",
+		"bucketName":    "examplebucket-1250000000",
+		"qcsRegion":     "ap-guangzhou",
+		"eventTime":     tm,
+		"eventSource":   "examplebucket-1250000000.cos.ap-guangzhou.myqcloud.com",
+		"eventName":     op,
+		"remoteIp":      "10.0.0.1",
+		"reqPath":       url.QueryEscape("/" + key),
+		"reqMethod":     "GET",
+		"userAgent":     url.QueryEscape("cos-go-sdk-v5/0.7 (linux)"),
+		"resHttpCode":   fmt.Sprint(status),
+		"resBytesSent":  fmt.Sprint(bytes),
+		"resTotalTime":  "12",
+		"requester":     requester,
+		"requestId":     "NWQ1ZjY4MTBfMjZiMjU4NjRfOWI1N180NDBiYTY=",
+		"referer":       url.QueryEscape("https://example.com/a b"),
+		"storageClass":  "STANDARD",
+		"reservedField": "-",
+	}
+	var fields []string
+	for _, f := range DefaultFields {
+		v, ok := values[f]
+		if !ok {
+			v = "-"
+		}
+		fields = append(fields, v)
+	}
+	return strings.Join(fields, " ")
+}
+
+func TestParseLine(t *testing.T) {
+	e, err := ParseLine(logLine("2024-01-02T03:04:05Z", "GetObject", "dir/a b.txt", "100000000001", 200, 1024))
+	if err != nil {
+		t.Fatalf("ParseLine returned error: %v", err)
+	}
+	want := &AccessLogEntry{
+		Version:      "1.0",
+		Bucket:       "examplebucket-1250000000",
+		Region:       "ap-guangzhou",
+		Time:         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:       "examplebucket-1250000000.cos.ap-guangzhou.myqcloud.com",
+		Operation:    "GetObject",
+		RemoteIP:     "10.0.0.1",
+		Method:       "GET",
+		Key:          "dir/a b.txt",
+		UserAgent:    "cos-go-sdk-v5/0.7 (linux)",
+		Referer:      "https://example.com/a b",
+		Status:       200,
+		Bytes:        1024,
+		Latency:      12 * time.Millisecond,
+		Requester:    "100000000001",
+		RequestID:    "NWQ1ZjY4MTBfMjZiMjU4NjRfOWI1N180NDBiYTY=",
+		StorageClass: "STANDARD",
+	}
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("ParseLine returned %+v, want %+v", e, want)
+	}
+
+	if _, err := ParseLine("1.0 examplebucket"); err == nil {
+		t.Errorf("ParseLine should fail on short line")
+	}
+}
+
+func TestScanAndStats(t *testing.T) {
+	logs := map[string]string{
+		"logs/examplebucket/2024/01/02/03/1.log": strings.Join([]string{
+			logLine("2024-01-02T03:00:00Z", "GetObject", "a", "u1", 200, 100),
+			logLine("2024-01-02T03:01:00Z", "GetObject", "a", "u2", 404, 0),
+			"",
+		}, "\n"),
+		"logs/examplebucket/2024/01/02/04/2.log": strings.Join([]string{
+			logLine("2024-01-02T04:00:00Z", "PutObject", "b", "u1", 200, 0),
+			logLine("2024-01-02T04:01:00Z", "GetObject", "a", "u2", 200, 300),
+			logLine("2024-01-02T05:01:00Z", "GetObject", "c", "u2", 500, 10),
+		}, "\n"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			if r.URL.Query().Get("marker") == "" {
+				fmt.Fprint(w, `<ListBucketResult><IsTruncated>true</IsTruncated>
+	<NextMarker>logs/examplebucket/2024/01/02/03/1.log</NextMarker>
+	<Contents><Key>logs/examplebucket/2024/01/02/03/1.log</Key></Contents>
+</ListBucketResult>`)
+				return
+			}
+			fmt.Fprint(w, `<ListBucketResult>
+	<Contents><Key>logs/examplebucket/2024/01/02/04/2.log</Key></Contents>
+</ListBucketResult>`)
+			return
+		}
+		fmt.Fprint(w, logs[strings.TrimPrefix(r.URL.Path, "/")])
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := cos.NewClient(&cos.BaseURL{BucketURL: u}, nil)
+
+	stats := NewStats()
+	opt := &ReaderOptions{
+		Filter: TimeRange(time.Time{}, time.Date(2024, 1, 2, 5, 0, 0, 0, time.UTC)),
+	}
+	err := Scan(context.Background(), client, "logs/", opt, func(e *AccessLogEntry) error {
+		stats.Add(e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if stats.Total.Requests != 4 || stats.Total.Errors != 1 || stats.ErrorRate() != 0.25 {
+		t.Errorf("Stats total is %+v", stats.Total)
+	}
+	top := stats.TopKeys(1)
+	if len(top) != 1 || top[0].Name != "a" || top[0].Requests != 3 || top[0].Bytes != 400 {
+		t.Errorf("Stats.TopKeys returned %+v", top)
+	}
+	bw := stats.BandwidthByRequester()
+	if len(bw) != 2 || bw[0].Name != "u2" || bw[0].Bytes != 300 || bw[1].Name != "u1" || bw[1].Bytes != 100 {
+		t.Errorf("Stats.BandwidthByRequester returned %+v", bw)
+	}
+	rates := stats.ErrorRateByOperation()
+	if rates["GetObject"] != 1.0/3 || rates["PutObject"] != 0 {
+		t.Errorf("Stats.ErrorRateByOperation returned %v", rates)
+	}
+	if errs := stats.TopErrorKeys(0); len(errs) != 1 || errs[0].Name != "a" {
+		t.Errorf("Stats.TopErrorKeys returned %+v", errs)
+	}
+
+	var keys []string
+	opt = &ReaderOptions{Filter: And(Operations("getobject"), Errors())}
+	err = Scan(context.Background(), client, "logs/", opt, func(e *AccessLogEntry) error {
+		keys = append(keys, e.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if !reflect.DeepEqual(keys, []string{"a", "c"}) {
+		t.Errorf("Scan with filter returned %v", keys)
+	}
+}
@@ -0,0 +1,177 @@
+package logs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// ReaderOptions is the option of NewReader
+type ReaderOptions struct {
+	Parser *Parser
+	Filter Filter
+	// 只读取对象键大于 StartAfter 的日志对象，日志对象键中包含时间，可用于增量读取
+	StartAfter string
+}
+
+// Reader 列出 prefix 下的日志对象并逐条返回访问日志
+type Reader struct {
+	ctx    context.Context
+	client *cos.Client
+	prefix string
+	opt    *ReaderOptions
+
+	keys        []string
+	marker      string
+	isTruncated bool
+	listed      bool
+
+	key     string
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader 创建读取访问日志的 Reader，client 为日志的目标存储桶(TargetBucket)，prefix 一般为 TargetPrefix
+func NewReader(ctx context.Context, client *cos.Client, prefix string, opt *ReaderOptions) *Reader {
+	if opt == nil {
+		opt = &ReaderOptions{}
+	}
+	if opt.Parser == nil {
+		opt.Parser = &Parser{}
+	}
+	return &Reader{
+		ctx:    ctx,
+		client: client,
+		prefix: prefix,
+		opt:    opt,
+		marker: opt.StartAfter,
+	}
+}
+
+// Key 返回当前正在读取的日志对象
+func (r *Reader) Key() string {
+	return r.key
+}
+
+// Next 返回下一条满足过滤条件的访问日志，全部读完时返回 io.EOF
+func (r *Reader) Next() (*AccessLogEntry, error) {
+	for {
+		if r.scanner == nil {
+			key, err := r.nextKey()
+			if err != nil {
+				return nil, err
+			}
+			if err = r.open(key); err != nil {
+				return nil, err
+			}
+		}
+		if !r.scanner.Scan() {
+			err := r.scanner.Err()
+			r.closeFile()
+			if err != nil {
+				return nil, fmt.Errorf("read log %v failed: %v", r.key, err)
+			}
+			continue
+		}
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, err := r.opt.Parser.ParseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("parse log %v line %d failed: %v", r.key, r.line, err)
+		}
+		if r.opt.Filter == nil || r.opt.Filter(e) {
+			return e, nil
+		}
+	}
+}
+
+func (r *Reader) nextKey() (string, error) {
+	for len(r.keys) == 0 {
+		if r.listed && !r.isTruncated {
+			return "", io.EOF
+		}
+		res, _, err := r.client.Bucket.Get(r.ctx, &cos.BucketGetOptions{
+			Prefix:  r.prefix,
+			Marker:  r.marker,
+			MaxKeys: 1000,
+		})
+		if err != nil {
+			return "", err
+		}
+		r.listed = true
+		r.isTruncated = res.IsTruncated
+		for _, obj := range res.Contents {
+			if !strings.HasSuffix(obj.Key, "/") {
+				r.keys = append(r.keys, obj.Key)
+			}
+		}
+		r.marker = res.NextMarker
+		if r.marker == "" && len(res.Contents) > 0 {
+			r.marker = res.Contents[len(res.Contents)-1].Key
+		}
+	}
+	key := r.keys[0]
+	r.keys = r.keys[1:]
+	return key, nil
+}
+
+func (r *Reader) open(key string) error {
+	resp, err := r.client.Object.Get(r.ctx, key, nil)
+	if err != nil {
+		return err
+	}
+	r.key, r.body, r.line = key, resp.Body, 0
+	var rd io.Reader = resp.Body
+	if strings.HasSuffix(key, ".gz") {
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			r.closeFile()
+			return fmt.Errorf("read log %v failed: %v", key, err)
+		}
+		rd = zr
+	}
+	r.scanner = bufio.NewScanner(rd)
+	r.scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return nil
+}
+
+func (r *Reader) closeFile() {
+	if r.body != nil {
+		r.body.Close()
+	}
+	r.body, r.scanner = nil, nil
+}
+
+// Close 关闭正在读取的日志对象
+func (r *Reader) Close() error {
+	r.closeFile()
+	r.keys, r.listed, r.isTruncated = nil, true, false
+	return nil
+}
+
+// Scan 读取 prefix 下的所有访问日志，对满足过滤条件的每条日志调用 fn，fn 返回错误时停止
+func Scan(ctx context.Context, client *cos.Client, prefix string, opt *ReaderOptions, fn func(*AccessLogEntry) error) error {
+	r := NewReader(ctx, client, prefix, opt)
+	defer r.Close()
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(e); err != nil {
+			return err
+		}
+	}
+}
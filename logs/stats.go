@@ -0,0 +1,140 @@
+package logs
+
+import (
+	"sort"
+	"time"
+)
+
+// Counter 一组访问日志的统计
+type Counter struct {
+	Requests int64
+	Errors   int64
+	// 响应的字节数
+	Bytes   int64
+	Latency time.Duration
+}
+
+// ErrorRate 失败请求的比例
+func (c *Counter) ErrorRate() float64 {
+	if c.Requests == 0 {
+		return 0
+	}
+	return float64(c.Errors) / float64(c.Requests)
+}
+
+// AvgLatency 平均耗时
+func (c *Counter) AvgLatency() time.Duration {
+	if c.Requests == 0 {
+		return 0
+	}
+	return c.Latency / time.Duration(c.Requests)
+}
+
+func (c *Counter) add(e *AccessLogEntry) {
+	c.Requests++
+	if e.IsError() {
+		c.Errors++
+	}
+	c.Bytes += e.Bytes
+	c.Latency += e.Latency
+}
+
+// NamedCounter 按名称(对象键、访问者、操作)分组的统计
+type NamedCounter struct {
+	Name string
+	Counter
+}
+
+// Stats 访问日志的聚合统计，非并发安全
+type Stats struct {
+	Total       Counter
+	ByKey       map[string]*Counter
+	ByRequester map[string]*Counter
+	ByOperation map[string]*Counter
+	ByStatus    map[int]int64
+}
+
+// NewStats 创建空的 Stats
+func NewStats() *Stats {
+	return &Stats{
+		ByKey:       map[string]*Counter{},
+		ByRequester: map[string]*Counter{},
+		ByOperation: map[string]*Counter{},
+		ByStatus:    map[int]int64{},
+	}
+}
+
+// Add 统计一条访问日志
+func (s *Stats) Add(e *AccessLogEntry) {
+	s.Total.add(e)
+	addNamed(s.ByKey, e.Key, e)
+	addNamed(s.ByRequester, e.Requester, e)
+	addNamed(s.ByOperation, e.Operation, e)
+	s.ByStatus[e.Status]++
+}
+
+func addNamed(m map[string]*Counter, name string, e *AccessLogEntry) {
+	c, ok := m[name]
+	if !ok {
+		c = &Counter{}
+		m[name] = c
+	}
+	c.add(e)
+}
+
+// ErrorRate 失败请求的比例
+func (s *Stats) ErrorRate() float64 {
+	return s.Total.ErrorRate()
+}
+
+// TopKeys 请求数最多的 n 个对象，n <= 0 时返回全部
+func (s *Stats) TopKeys(n int) []NamedCounter {
+	return top(s.ByKey, n, func(c *Counter) int64 { return c.Requests })
+}
+
+// TopErrorKeys 失败请求数最多的 n 个对象
+func (s *Stats) TopErrorKeys(n int) []NamedCounter {
+	res := top(s.ByKey, 0, func(c *Counter) int64 { return c.Errors })
+	for i, c := range res {
+		if c.Errors == 0 {
+			res = res[:i]
+			break
+		}
+	}
+	if n > 0 && len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// BandwidthByRequester 按响应字节数从大到小排列的访问者
+func (s *Stats) BandwidthByRequester() []NamedCounter {
+	return top(s.ByRequester, 0, func(c *Counter) int64 { return c.Bytes })
+}
+
+// ErrorRateByOperation 每个操作的失败请求比例
+func (s *Stats) ErrorRateByOperation() map[string]float64 {
+	res := make(map[string]float64, len(s.ByOperation))
+	for op, c := range s.ByOperation {
+		res[op] = c.ErrorRate()
+	}
+	return res
+}
+
+func top(m map[string]*Counter, n int, value func(*Counter) int64) []NamedCounter {
+	res := make([]NamedCounter, 0, len(m))
+	for name, c := range m {
+		res = append(res, NamedCounter{Name: name, Counter: *c})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		vi, vj := value(&res[i].Counter), value(&res[j].Counter)
+		if vi != vj {
+			return vi > vj
+		}
+		return res[i].Name < res[j].Name
+	})
+	if n > 0 && len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
@@ -35,10 +35,15 @@ type ObjectGetOptions struct {
 	CiProcess                  string `url:"ci-process,omitempty" header:"-"`
 	Range                      string `url:"-" header:"Range,omitempty"`
 	IfModifiedSince            string `url:"-" header:"If-Modified-Since,omitempty"`
+	IfUnmodifiedSince          string `url:"-" header:"If-Unmodified-Since,omitempty"`
+	IfMatch                    string `url:"-" header:"If-Match,omitempty"`
+	IfNoneMatch                string `url:"-" header:"If-None-Match,omitempty"`
 	// SSE-C
 	XCosSSECustomerAglo   string `header:"x-cos-server-side-encryption-customer-algorithm,omitempty" url:"-" xml:"-"`
 	XCosSSECustomerKey    string `header:"x-cos-server-side-encryption-customer-key,omitempty" url:"-" xml:"-"`
 	XCosSSECustomerKeyMD5 string `header:"x-cos-server-side-encryption-customer-key-MD5,omitempty" url:"-" xml:"-"`
+	// SSE-C 对象的密钥，与 XCosSSECustomer* 二选一
+	SSE *SSE `header:"x-cos-server-side-encryption-customer,omitempty" url:"-" xml:"-"`
 
 	//兼容其他自定义头部
 	XOptionHeader    *http.Header `header:"-,omitempty" url:"-" xml:"-"`
@@ -422,9 +427,16 @@ type ObjectPutHeaderOptions struct {
 	Expect             string `header:"Expect,omitempty" url:"-"`
 	Expires            string `header:"Expires,omitempty" url:"-"`
 	XCosContentSHA1    string `header:"x-cos-content-sha1,omitempty" url:"-"`
+	// 条件上传: IfMatch 为对象当前的 ETag 时才覆盖，IfNoneMatch 为 "*" 时仅当对象不存在时上传
+	IfMatch     string `header:"If-Match,omitempty" url:"-"`
+	IfNoneMatch string `header:"If-None-Match,omitempty" url:"-"`
 	// 自定义的 x-cos-meta-* header
 	XCosMetaXXX      *http.Header `header:"x-cos-meta-*,omitempty" url:"-"`
 	XCosStorageClass string       `header:"x-cos-storage-class,omitempty" url:"-"`
+	// 对象锁定: 保留模式(COMPLIANCE、GOVERNANCE)、保留截止时间(ISO8601)与法律保留状态(ON、OFF)
+	XCosObjectLockMode            string `header:"x-cos-object-lock-mode,omitempty" url:"-" xml:"-"`
+	XCosObjectLockRetainUntilDate string `header:"x-cos-object-lock-retain-until-date,omitempty" url:"-" xml:"-"`
+	XCosObjectLockLegalHold       string `header:"x-cos-object-lock-legal-hold,omitempty" url:"-" xml:"-"`
 	// 可选值: Normal, Appendable
 	//XCosObjectType string `header:"x-cos-object-type,omitempty" url:"-"`
 	// Enable Server Side Encryption, Only supported: AES256
@@ -433,6 +445,8 @@ type ObjectPutHeaderOptions struct {
 	XCosSSECustomerAglo   string `header:"x-cos-server-side-encryption-customer-algorithm,omitempty" url:"-" xml:"-"`
 	XCosSSECustomerKey    string `header:"x-cos-server-side-encryption-customer-key,omitempty" url:"-" xml:"-"`
 	XCosSSECustomerKeyMD5 string `header:"x-cos-server-side-encryption-customer-key-MD5,omitempty" url:"-" xml:"-"`
+	// 服务端加密，与 XCosServerSideEncryption、XCosSSECustomer* 二选一
+	SSE *SSE `header:"x-cos-server-side-encryption,omitempty" url:"-" xml:"-"`
 	//兼容其他自定义头部
 	XOptionHeader    *http.Header `header:"-,omitempty" url:"-" xml:"-"`
 	XCosTrafficLimit int          `header:"x-cos-traffic-limit,omitempty" url:"-" xml:"-"`
@@ -578,7 +592,14 @@ type ObjectCopyHeaderOptions struct {
 	XCosCopySourceIfUnmodifiedSince string `header:"x-cos-copy-source-If-Unmodified-Since,omitempty" url:"-" xml:"-"`
 	XCosCopySourceIfMatch           string `header:"x-cos-copy-source-If-Match,omitempty" url:"-" xml:"-"`
 	XCosCopySourceIfNoneMatch       string `header:"x-cos-copy-source-If-None-Match,omitempty" url:"-" xml:"-"`
-	XCosStorageClass                string `header:"x-cos-storage-class,omitempty" url:"-" xml:"-"`
+	// 目标对象的条件
+	IfMatch          string `header:"If-Match,omitempty" url:"-" xml:"-"`
+	IfNoneMatch      string `header:"If-None-Match,omitempty" url:"-" xml:"-"`
+	XCosStorageClass string `header:"x-cos-storage-class,omitempty" url:"-" xml:"-"`
+	// 目标对象的对象锁定设置
+	XCosObjectLockMode            string `header:"x-cos-object-lock-mode,omitempty" url:"-" xml:"-"`
+	XCosObjectLockRetainUntilDate string `header:"x-cos-object-lock-retain-until-date,omitempty" url:"-" xml:"-"`
+	XCosObjectLockLegalHold       string `header:"x-cos-object-lock-legal-hold,omitempty" url:"-" xml:"-"`
 	// 自定义的 x-cos-meta-* header
 	XCosMetaXXX              *http.Header `header:"x-cos-meta-*,omitempty" url:"-"`
 	XCosCopySource           string       `header:"x-cos-copy-source" url:"-" xml:"-"`
@@ -590,6 +611,10 @@ type ObjectCopyHeaderOptions struct {
 	XCosCopySourceSSECustomerAglo   string `header:"x-cos-copy-source-server-side-encryption-customer-algorithm,omitempty" url:"-" xml:"-"`
 	XCosCopySourceSSECustomerKey    string `header:"x-cos-copy-source-server-side-encryption-customer-key,omitempty" url:"-" xml:"-"`
 	XCosCopySourceSSECustomerKeyMD5 string `header:"x-cos-copy-source-server-side-encryption-customer-key-MD5,omitempty" url:"-" xml:"-"`
+	// 目标对象的服务端加密
+	SSE *SSE `header:"x-cos-server-side-encryption,omitempty" url:"-" xml:"-"`
+	// 源对象为 SSE-C 加密时的密钥
+	CopySourceSSE *SSE `header:"x-cos-copy-source-server-side-encryption-customer,omitempty" url:"-" xml:"-"`
 	//兼容其他自定义头部
 	XOptionHeader *http.Header `header:"-,omitempty" url:"-" xml:"-"`
 }
@@ -681,6 +706,9 @@ func (s *ObjectService) Copy(ctx context.Context, name, sourceURL string, opt *O
 }
 
 type ObjectDeleteOptions struct {
+	// 条件删除
+	IfMatch           string `header:"If-Match,omitempty" url:"-" xml:"-"`
+	IfUnmodifiedSince string `header:"If-Unmodified-Since,omitempty" url:"-" xml:"-"`
 	// SSE-C
 	XCosSSECustomerAglo   string `header:"x-cos-server-side-encryption-customer-algorithm,omitempty" url:"-" xml:"-"`
 	XCosSSECustomerKey    string `header:"x-cos-server-side-encryption-customer-key,omitempty" url:"-" xml:"-"`
@@ -725,11 +753,15 @@ func (s *ObjectService) Delete(ctx context.Context, name string, opt ...*ObjectD
 
 // ObjectHeadOptions is the option of HeadObject
 type ObjectHeadOptions struct {
-	IfModifiedSince string `url:"-" header:"If-Modified-Since,omitempty"`
+	IfModifiedSince   string `url:"-" header:"If-Modified-Since,omitempty"`
+	IfUnmodifiedSince string `url:"-" header:"If-Unmodified-Since,omitempty"`
+	IfMatch           string `url:"-" header:"If-Match,omitempty"`
+	IfNoneMatch       string `url:"-" header:"If-None-Match,omitempty"`
 	// SSE-C
 	XCosSSECustomerAglo   string       `header:"x-cos-server-side-encryption-customer-algorithm,omitempty" url:"-" xml:"-"`
 	XCosSSECustomerKey    string       `header:"x-cos-server-side-encryption-customer-key,omitempty" url:"-" xml:"-"`
 	XCosSSECustomerKeyMD5 string       `header:"x-cos-server-side-encryption-customer-key-MD5,omitempty" url:"-" xml:"-"`
+	SSE                   *SSE         `header:"x-cos-server-side-encryption-customer,omitempty" url:"-" xml:"-"`
 	XOptionHeader         *http.Header `header:"-,omitempty" url:"-" xml:"-"`
 }
 
@@ -889,7 +921,12 @@ func (s *ObjectService) Append(ctx context.Context, name string, position int, r
 		if s.client.Conf.EnableCRC && reader.writer != nil {
 			wanted := hex.EncodeToString(reader.Sum())
 			if wanted != resp.Header.Get("x-cos-content-sha1") {
-				return res, resp, fmt.Errorf("append verification failed, want:%v, return:%v, header:%+v", wanted, resp.Header.Get("x-cos-content-sha1"), resp.Header)
+				return res, resp, &ChecksumMismatchError{
+					Algorithm: "sha1",
+					Want:      wanted,
+					Return:    resp.Header.Get("x-cos-content-sha1"),
+					Header:    resp.Header,
+				}
 			}
 		}
 		np, err := strconv.ParseInt(resp.Header.Get("x-cos-next-append-position"), 10, 64)
@@ -952,10 +989,12 @@ type Object struct {
 // MultiUploadOptions is the option of the multiupload,
 // ThreadPoolSize default is one
 type MultiUploadOptions struct {
-	OptIni          *InitiateMultipartUploadOptions
-	PartSize        int64
-	ThreadPoolSize  int
-	CheckPoint      bool
+	OptIni         *InitiateMultipartUploadOptions
+	PartSize       int64
+	ThreadPoolSize int
+	CheckPoint     bool
+	// 断点文件路径，仅用于需要在本地保存断点信息的 CryptoObjectService.Upload，默认为 <filepath>.cryptouploadtask
+	CheckPointFile  string
 	DisableChecksum bool
 }
 
@@ -1332,7 +1371,7 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 			scoscrc := rsp.Header.Get("x-cos-hash-crc64ecma")
 			icoscrc, _ := strconv.ParseUint(scoscrc, 10, 64)
 			if icoscrc != localcrc {
-				return result, rsp, fmt.Errorf("verification failed, want:%v, return:%v, header:%+v", localcrc, icoscrc, rsp.Header)
+				return result, rsp, newCRC64MismatchError(localcrc, icoscrc, rsp.Header)
 			}
 		}
 		return result, rsp, nil
@@ -1351,6 +1390,18 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 
 	// 2.Init
 	optini := opt.OptIni
+	optcom := &CompleteMultipartUploadOptions{}
+	if optini != nil && optini.ObjectPutHeaderOptions != nil && (optini.IfMatch != "" || optini.IfNoneMatch != "") {
+		// 条件头部只作用于 CompleteMultipartUpload
+		optcom.IfMatch = optini.IfMatch
+		optcom.IfNoneMatch = optini.IfNoneMatch
+		putHeader := *optini.ObjectPutHeaderOptions
+		putHeader.IfMatch, putHeader.IfNoneMatch = "", ""
+		optini = &InitiateMultipartUploadOptions{
+			ACLHeaderOptions:       optini.ACLHeaderOptions,
+			ObjectPutHeaderOptions: &putHeader,
+		}
+	}
 	if !resumableFlag {
 		res, _, err := s.InitiateMultipartUpload(ctx, name, optini)
 		if err != nil {
@@ -1368,7 +1419,6 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 
 	chjobs := make(chan *Jobs, 100)
 	chresults := make(chan *Results, 10000)
-	optcom := &CompleteMultipartUploadOptions{}
 
 	// 3.Start worker
 	for w := 1; w <= poolSize; w++ {
@@ -1398,6 +1448,7 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 				partOpt.XCosSSECustomerAglo = optini.XCosSSECustomerAglo
 				partOpt.XCosSSECustomerKey = optini.XCosSSECustomerKey
 				partOpt.XCosSSECustomerKeyMD5 = optini.XCosSSECustomerKeyMD5
+				partOpt.SSE = optini.SSE
 				partOpt.XCosTrafficLimit = optini.XCosTrafficLimit
 				partOpt.XOptionHeader = optini.XOptionHeader
 			}
@@ -1466,7 +1517,7 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 		scoscrc := resp.Header.Get("x-cos-hash-crc64ecma")
 		icoscrc, err := strconv.ParseUint(scoscrc, 10, 64)
 		if icoscrc != localcrc {
-			return v, resp, fmt.Errorf("verification failed, want:%v, return:%v, x-cos-hash-crc64ecma: %v, err:%v, header:%+v", localcrc, icoscrc, scoscrc, err, resp.Header)
+			return v, resp, newCRC64MismatchError(localcrc, icoscrc, resp.Header, err)
 		}
 	}
 	return v, resp, err
@@ -1597,6 +1648,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 		headOpt.XCosSSECustomerAglo = opt.Opt.XCosSSECustomerAglo
 		headOpt.XCosSSECustomerKey = opt.Opt.XCosSSECustomerKey
 		headOpt.XCosSSECustomerKeyMD5 = opt.Opt.XCosSSECustomerKeyMD5
+		headOpt.SSE = opt.Opt.SSE
 		headOpt.XOptionHeader = opt.Opt.XOptionHeader
 	}
 	resp, err := s.Head(ctx, name, headOpt, id...)
@@ -1635,7 +1687,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 				return rsp, err
 			}
 			if localcrc != icoscrc {
-				return rsp, fmt.Errorf("verification failed, want:%v, return:%v, header:%+v", icoscrc, localcrc, resp.Header)
+				return rsp, newCRC64MismatchError(localcrc, icoscrc, resp.Header)
 			}
 		}
 		return rsp, err
@@ -1774,7 +1826,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 			return resp, err
 		}
 		if localcrc != icoscrc {
-			return resp, fmt.Errorf("verification failed, want:%v, return:%v, header:%+v", icoscrc, localcrc, resp.Header)
+			return resp, newCRC64MismatchError(localcrc, icoscrc, resp.Header)
 		}
 	}
 	event = newProgressEvent(ProgressCompletedEvent, 0, consumedBytes, totalBytes)
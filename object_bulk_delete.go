@@ -0,0 +1,229 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// DeleteMulti 单次最多删除的对象数
+const maxDeleteMultiObjects = 1000
+
+// ObjectIterator 依次返回待处理的对象，遍历结束时返回 io.EOF
+type ObjectIterator interface {
+	Next() (Object, error)
+}
+
+// ObjectIteratorFunc 将函数适配为 ObjectIterator
+type ObjectIteratorFunc func() (Object, error)
+
+func (f ObjectIteratorFunc) Next() (Object, error) {
+	return f()
+}
+
+// NewObjectSliceIterator 遍历 objs 的 ObjectIterator
+func NewObjectSliceIterator(objs []Object) ObjectIterator {
+	i := 0
+	return ObjectIteratorFunc(func() (Object, error) {
+		if i >= len(objs) {
+			return Object{}, io.EOF
+		}
+		i++
+		return objs[i-1], nil
+	})
+}
+
+// DeleteObjectsOptions is the option of DeletePrefix and DeleteKeys
+type DeleteObjectsOptions struct {
+	// 并发的 DeleteMulti 请求数，默认为 1
+	ThreadPoolSize int
+	// 删除失败的对象的重试次数，默认 3 次
+	RetryTimes int
+	// 同时删除所有历史版本与删除标记，仅对 DeletePrefix 有效
+	AllVersions bool
+	// 允许 DeletePrefix 的 prefix 为空，即删除存储桶内的所有对象
+	AllowEmptyPrefix bool
+	// 只遍历待删除的对象，不实际删除
+	DryRun bool
+}
+
+// DeleteObjectsResult is the result of DeletePrefix and DeleteKeys
+type DeleteObjectsResult struct {
+	// 遍历到的待删除对象数
+	Matched int
+	// 删除成功的对象数
+	Deleted int
+	// 重试后仍删除失败的对象
+	Failed []ObjectDeleteError
+	// DryRun 时为将要删除的对象
+	DryRunObjects []Object
+}
+
+// newObjectListIterator 按页遍历 prefix 下的对象
+func (s *ObjectService) newObjectListIterator(ctx context.Context, prefix string) ObjectIterator {
+	bucket := &BucketService{client: s.client}
+	opt := &BucketGetOptions{
+		Prefix:  prefix,
+		MaxKeys: maxDeleteMultiObjects,
+	}
+	var buf []Object
+	done := false
+	return ObjectIteratorFunc(func() (Object, error) {
+		for len(buf) == 0 {
+			if done {
+				return Object{}, io.EOF
+			}
+			res, _, err := bucket.Get(ctx, opt)
+			if err != nil {
+				return Object{}, err
+			}
+			buf = res.Contents
+			done = !res.IsTruncated
+			opt.Marker = res.NextMarker
+			if !done && opt.Marker == "" && len(res.Contents) > 0 {
+				opt.Marker = res.Contents[len(res.Contents)-1].Key
+			}
+		}
+		obj := buf[0]
+		buf = buf[1:]
+		return obj, nil
+	})
+}
+
+// DeletePrefix 删除 prefix 下的所有对象，AllVersions 为 true 时同时删除所有历史版本与删除标记。
+// prefix 为空时需设置 AllowEmptyPrefix
+func (s *ObjectService) DeletePrefix(ctx context.Context, prefix string, opt *DeleteObjectsOptions) (*DeleteObjectsResult, error) {
+	if prefix == "" && (opt == nil || !opt.AllowEmptyPrefix) {
+		return nil, errors.New("prefix is empty, set AllowEmptyPrefix to delete all objects in the bucket")
+	}
+	var it ObjectIterator
+	if opt != nil && opt.AllVersions {
+		vit := s.newVersionIterator(ctx, prefix)
+		it = ObjectIteratorFunc(func() (Object, error) {
+			v, err := vit.next()
+			if err != nil {
+				return Object{}, err
+			}
+			return Object{Key: v.Key, VersionId: v.VersionId}, nil
+		})
+	} else {
+		it = s.newObjectListIterator(ctx, prefix)
+	}
+	return s.DeleteKeys(ctx, it, opt)
+}
+
+// DeleteKeys 以 Quiet 模式并发地调用 DeleteMulti 删除 it 返回的对象(每批 1000 个)，
+// 只重试删除失败的对象。遍历出错时停止遍历，删除已遍历到的对象后返回错误
+func (s *ObjectService) DeleteKeys(ctx context.Context, it ObjectIterator, opt ...*DeleteObjectsOptions) (*DeleteObjectsResult, error) {
+	dopt := &DeleteObjectsOptions{}
+	if len(opt) > 0 && opt[0] != nil {
+		dopt = opt[0]
+	}
+	poolSize := dopt.ThreadPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+	retryTimes := dopt.RetryTimes
+	if retryTimes <= 0 {
+		retryTimes = 3
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	res := &DeleteObjectsResult{}
+	var mu sync.Mutex
+	batches := make(chan []Object, poolSize)
+	var wg sync.WaitGroup
+	for i := 0; i < poolSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				deleted, failed := s.deleteBatch(ctx, batch, retryTimes)
+				mu.Lock()
+				res.Deleted += deleted
+				res.Failed = append(res.Failed, failed...)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+	batch := make([]Object, 0, maxDeleteMultiObjects)
+	for {
+		var obj Object
+		obj, err = it.Next()
+		if err != nil {
+			break
+		}
+		res.Matched++
+		if dopt.DryRun {
+			res.DryRunObjects = append(res.DryRunObjects, obj)
+			continue
+		}
+		batch = append(batch, Object{Key: obj.Key, VersionId: obj.VersionId})
+		if len(batch) == maxDeleteMultiObjects {
+			batches <- batch
+			batch = make([]Object, 0, maxDeleteMultiObjects)
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	if len(batch) > 0 {
+		batches <- batch
+	}
+	close(batches)
+	wg.Wait()
+	return res, err
+}
+
+// deleteBatch 删除一批对象，失败的对象最多重试 retryTimes 次
+func (s *ObjectService) deleteBatch(ctx context.Context, objs []Object, retryTimes int) (int, []ObjectDeleteError) {
+	deleted := 0
+	var failed []ObjectDeleteError
+	for nr := 0; nr <= retryTimes && len(objs) > 0; nr++ {
+		failed = failed[:0]
+		res, _, err := s.DeleteMulti(ctx, &ObjectDeleteMultiOptions{
+			Quiet:   true,
+			Objects: objs,
+		})
+		if err != nil {
+			for _, obj := range objs {
+				failed = append(failed, newObjectDeleteError(obj, err))
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		deleted += len(objs) - len(res.Errors)
+		retry := make([]Object, 0, len(res.Errors))
+		for _, e := range res.Errors {
+			retry = append(retry, Object{Key: e.Key, VersionId: e.VersionId})
+			failed = append(failed, ObjectDeleteError{
+				Key:       e.Key,
+				VersionId: e.VersionId,
+				Code:      e.Code,
+				Message:   e.Message,
+			})
+		}
+		objs = retry
+	}
+	return deleted, failed
+}
+
+func newObjectDeleteError(obj Object, err error) ObjectDeleteError {
+	e := ObjectDeleteError{
+		Key:       obj.Key,
+		VersionId: obj.VersionId,
+		Message:   err.Error(),
+	}
+	var cosErr *ErrorResponse
+	if errors.As(err, &cosErr) {
+		e.Code, e.Message = string(cosErr.ErrorCode()), cosErr.Message
+	}
+	return e
+}
@@ -0,0 +1,124 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestObjectService_DeletePrefix(t *testing.T) {
+	setup()
+	defer teardown()
+
+	total := 2500
+	var mu sync.Mutex
+	deleted := map[string]int{}
+	requests := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if r.URL.Query().Get("prefix") != "dir/" {
+				t.Errorf("Object.DeletePrefix list prefix is %v", r.URL.Query().Get("prefix"))
+			}
+			start := 0
+			if m := r.URL.Query().Get("marker"); m != "" {
+				start, _ = strconv.Atoi(m[len("dir/"):])
+				start++
+			}
+			res := BucketGetResult{Prefix: "dir/"}
+			for i := start; i < total && i < start+1000; i++ {
+				res.Contents = append(res.Contents, Object{Key: fmt.Sprintf("dir/%04d", i)})
+			}
+			res.IsTruncated = start+1000 < total
+			xml.NewEncoder(w).Encode(res)
+			return
+		}
+		testMethod(t, r, http.MethodPost)
+		body := &ObjectDeleteMultiOptions{}
+		xml.NewDecoder(r.Body).Decode(body)
+		if !body.Quiet || len(body.Objects) > 1000 {
+			t.Errorf("Object.DeletePrefix quiet: %v, objects: %v", body.Quiet, len(body.Objects))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		for _, obj := range body.Objects {
+			deleted[obj.Key]++
+		}
+		// dir/0000 始终删除失败
+		if deleted["dir/0000"] > 0 && body.Objects[0].Key == "dir/0000" {
+			fmt.Fprint(w, `<DeleteResult><Error><Key>dir/0000</Key><Code>AccessDenied</Code></Error></DeleteResult>`)
+			return
+		}
+		fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	})
+
+	res, err := client.Object.DeletePrefix(context.Background(), "dir/", &DeleteObjectsOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Object.DeletePrefix returned error: %v", err)
+	}
+	if res.Matched != total || len(res.DryRunObjects) != total || requests != 0 {
+		t.Fatalf("Object.DeletePrefix dry run matched %v, requests %v", res.Matched, requests)
+	}
+
+	res, err = client.Object.DeletePrefix(context.Background(), "dir/", &DeleteObjectsOptions{
+		ThreadPoolSize: 3,
+		RetryTimes:     2,
+	})
+	if err != nil {
+		t.Fatalf("Object.DeletePrefix returned error: %v", err)
+	}
+	if res.Matched != total || res.Deleted != total-1 || len(deleted) != total {
+		t.Errorf("Object.DeletePrefix matched %v, deleted %v, keys %v", res.Matched, res.Deleted, len(deleted))
+	}
+	if len(res.Failed) != 1 || res.Failed[0].Key != "dir/0000" || res.Failed[0].Code != "AccessDenied" {
+		t.Errorf("Object.DeletePrefix failed %+v", res.Failed)
+	}
+	// 3 批请求，失败的对象重试 2 次
+	if requests != 5 || deleted["dir/0000"] != 3 {
+		t.Errorf("Object.DeletePrefix requests %v, dir/0000 deleted %v times", requests, deleted["dir/0000"])
+	}
+}
+
+func TestObjectService_DeleteKeys(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var objs []Object
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body := &ObjectDeleteMultiOptions{}
+		xml.NewDecoder(r.Body).Decode(body)
+		objs = append(objs, body.Objects...)
+		fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	})
+
+	want := []Object{{Key: "a", VersionId: "v1"}, {Key: "b"}}
+	res, err := client.Object.DeleteKeys(context.Background(), NewObjectSliceIterator(want))
+	if err != nil {
+		t.Fatalf("Object.DeleteKeys returned error: %v", err)
+	}
+	if res.Deleted != 2 || len(objs) != 2 || objs[0] != want[0] || objs[1] != want[1] {
+		t.Errorf("Object.DeleteKeys deleted %+v, want %+v", objs, want)
+	}
+
+	iterErr := errors.New("iterate error")
+	n := 0
+	res, err = client.Object.DeleteKeys(context.Background(), ObjectIteratorFunc(func() (Object, error) {
+		if n++; n > 1 {
+			return Object{}, iterErr
+		}
+		return Object{Key: "c"}, nil
+	}))
+	// 遍历出错前已遍历到的对象仍会被删除
+	if err != iterErr || res.Matched != 1 || res.Deleted != 1 || objs[len(objs)-1].Key != "c" {
+		t.Errorf("Object.DeleteKeys returned %v, result %+v", err, res)
+	}
+
+	if _, err = client.Object.DeletePrefix(context.Background(), "", nil); err == nil {
+		t.Errorf("Object.DeletePrefix should fail for empty prefix")
+	}
+}
@@ -0,0 +1,93 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// UpdateWithRetryOptions is the option of UpdateWithRetry
+type UpdateWithRetryOptions struct {
+	// 发生冲突时的最大重试次数，默认 10 次
+	MaxRetries int
+	// 重试间隔，每次冲突后线性递增，默认 100ms
+	Interval time.Duration
+	// 上传时使用的参数，IfMatch 与 IfNoneMatch 会被覆盖
+	PutOptions *ObjectPutOptions
+}
+
+// PutIfAbsent 仅当对象不存在时上传(If-None-Match: *)，对象已存在时返回的错误满足 errors.Is(err, ErrPreconditionFailed)
+func (s *ObjectService) PutIfAbsent(ctx context.Context, name string, r io.Reader, opt *ObjectPutOptions) (*Response, error) {
+	opt = CloneObjectPutOptions(opt)
+	opt.IfMatch = ""
+	opt.IfNoneMatch = "*"
+	return s.Put(ctx, name, r, opt)
+}
+
+// UpdateWithRetry 以 ETag 实现乐观并发的读-改-写：读取对象后调用 fn 计算新内容，再以 If-Match 条件上传；
+// 对象不存在时 fn 的参数为 nil，并以 If-None-Match: * 条件上传。上传因并发修改失败(PreconditionFailed)时重新读取并重试。
+func (s *ObjectService) UpdateWithRetry(ctx context.Context, name string, fn func(old []byte) ([]byte, error), opt ...*UpdateWithRetryOptions) (*Response, error) {
+	uopt := &UpdateWithRetryOptions{}
+	if len(opt) > 0 && opt[0] != nil {
+		uopt = opt[0]
+	}
+	maxRetries := uopt.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 10
+	}
+	interval := uopt.Interval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+
+	var resp *Response
+	var err error
+	for nr := 0; nr <= maxRetries; nr++ {
+		if nr > 0 {
+			select {
+			case <-ctx.Done():
+				return resp, ctx.Err()
+			case <-time.After(interval * time.Duration(nr)):
+			}
+		}
+		var old []byte
+		var etag string
+		resp, err = s.Get(ctx, name, nil)
+		if err == nil {
+			etag = resp.Header.Get("ETag")
+			old, err = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return resp, err
+			}
+		} else if !errors.Is(err, ErrNoSuchKey) {
+			return resp, err
+		}
+
+		data, ferr := fn(old)
+		if ferr != nil {
+			return nil, ferr
+		}
+		putOpt := CloneObjectPutOptions(uopt.PutOptions)
+		putOpt.IfMatch, putOpt.IfNoneMatch = "", ""
+		if etag != "" {
+			putOpt.IfMatch = etag
+		} else {
+			putOpt.IfNoneMatch = "*"
+		}
+		putOpt.ContentLength = int64(len(data))
+		resp, err = s.Put(ctx, name, bytes.NewReader(data), putOpt)
+		if err == nil || !isConditionConflict(err) {
+			return resp, err
+		}
+	}
+	return resp, err
+}
+
+// isConditionConflict 条件不满足或并发的条件请求冲突
+func isConditionConflict(err error) bool {
+	return errors.Is(err, ErrPreconditionFailed) || errors.Is(err, ErrConditionalRequestConflict)
+}
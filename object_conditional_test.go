@@ -0,0 +1,153 @@
+package cos
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestObjectService_PutIfAbsent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	exists := false
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		testHeader(t, r, "If-None-Match", "*")
+		if exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(w, `<Error><Code>PreconditionFailed</Code></Error>`)
+			return
+		}
+		exists = true
+	})
+
+	opt := &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{IfMatch: "etag"},
+	}
+	client.Conf.EnableCRC = false
+	_, err := client.Object.PutIfAbsent(context.Background(), "test", strings.NewReader("test"), opt)
+	if err != nil {
+		t.Fatalf("Object.PutIfAbsent returned error: %v", err)
+	}
+	_, err = client.Object.PutIfAbsent(context.Background(), "test", strings.NewReader("test"), opt)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("Object.PutIfAbsent returned %v, want PreconditionFailed", err)
+	}
+	if opt.IfMatch != "etag" || opt.IfNoneMatch != "" {
+		t.Errorf("Object.PutIfAbsent should not modify opt")
+	}
+}
+
+func TestObjectService_UpdateWithRetry(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	var data []byte
+	etag := func() string {
+		return fmt.Sprintf("\"%x\"", md5.Sum(data))
+	}
+	conflicts := 2
+	mux.HandleFunc("/counter", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			if data == nil {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+				return
+			}
+			w.Header().Set("ETag", etag())
+			w.Write(data)
+		case http.MethodPut:
+			if data == nil && r.Header.Get("If-None-Match") != "*" || data != nil && r.Header.Get("If-Match") != etag() {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				fmt.Fprint(w, `<Error><Code>PreconditionFailed</Code></Error>`)
+				return
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			// 模拟其它客户端的并发修改
+			if conflicts > 0 {
+				conflicts--
+				data = append([]byte(nil), body...)
+				data = append(data, '+')
+				w.WriteHeader(http.StatusPreconditionFailed)
+				fmt.Fprint(w, `<Error><Code>PreconditionFailed</Code></Error>`)
+				return
+			}
+			data = body
+		}
+	})
+
+	client.Conf.EnableCRC = false
+	calls := 0
+	incr := func(old []byte) ([]byte, error) {
+		calls++
+		return append(old, 'x'), nil
+	}
+	opt := &UpdateWithRetryOptions{Interval: time.Millisecond}
+	_, err := client.Object.UpdateWithRetry(context.Background(), "counter", incr, opt)
+	if err != nil {
+		t.Fatalf("Object.UpdateWithRetry returned error: %v", err)
+	}
+	if string(data) != "x+x+x" || calls != 3 {
+		t.Errorf("Object.UpdateWithRetry data: %s, calls: %v", data, calls)
+	}
+
+	conflicts = 10
+	opt.MaxRetries = 2
+	_, err = client.Object.UpdateWithRetry(context.Background(), "counter", incr, opt)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Object.UpdateWithRetry returned %v, want PreconditionFailed", err)
+	}
+
+	want := errors.New("abort")
+	_, err = client.Object.UpdateWithRetry(context.Background(), "counter", func([]byte) ([]byte, error) {
+		return nil, want
+	})
+	if err != want {
+		t.Errorf("Object.UpdateWithRetry returned %v, want %v", err, want)
+	}
+}
+
+func TestObjectService_ConditionalHeaders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "If-Match", "\"etag\"")
+		if r.Method == http.MethodGet {
+			testHeader(t, r, "If-Unmodified-Since", "Mon, 19 Oct 2026 00:00:00 GMT")
+		}
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"etag"</ETag></CompleteMultipartUploadResult>`)
+		}
+	})
+	_, err := client.Object.Get(context.Background(), "test", &ObjectGetOptions{
+		IfMatch:           "\"etag\"",
+		IfUnmodifiedSince: "Mon, 19 Oct 2026 00:00:00 GMT",
+	})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	_, err = client.Object.Delete(context.Background(), "test", &ObjectDeleteOptions{IfMatch: "\"etag\""})
+	if err != nil {
+		t.Fatalf("Object.Delete returned error: %v", err)
+	}
+	_, _, err = client.Object.CompleteMultipartUpload(context.Background(), "test", "uploadid", &CompleteMultipartUploadOptions{
+		Parts:   []Object{{PartNumber: 1, ETag: "\"etag\""}},
+		IfMatch: "\"etag\"",
+	})
+	if err != nil {
+		t.Fatalf("Object.CompleteMultipartUpload returned error: %v", err)
+	}
+}
@@ -65,6 +65,8 @@ type ObjectUploadPartOptions struct {
 	XCosSSECustomerAglo   string `header:"x-cos-server-side-encryption-customer-algorithm,omitempty" url:"-" xml:"-"`
 	XCosSSECustomerKey    string `header:"x-cos-server-side-encryption-customer-key,omitempty" url:"-" xml:"-"`
 	XCosSSECustomerKeyMD5 string `header:"x-cos-server-side-encryption-customer-key-MD5,omitempty" url:"-" xml:"-"`
+	// SSE-C 对象的密钥，与 XCosSSECustomer* 二选一
+	SSE *SSE `header:"x-cos-server-side-encryption-customer,omitempty" url:"-" xml:"-"`
 
 	XCosTrafficLimit int `header:"x-cos-traffic-limit,omitempty" url:"-" xml:"-"`
 
@@ -214,8 +216,11 @@ func (s *ObjectService) ListParts(ctx context.Context, name, uploadID string, op
 
 // CompleteMultipartUploadOptions is the option of CompleteMultipartUpload
 type CompleteMultipartUploadOptions struct {
-	XMLName       xml.Name     `xml:"CompleteMultipartUpload" header:"-" url:"-"`
-	Parts         []Object     `xml:"Part" header:"-" url:"-"`
+	XMLName xml.Name `xml:"CompleteMultipartUpload" header:"-" url:"-"`
+	Parts   []Object `xml:"Part" header:"-" url:"-"`
+	// 条件完成: IfMatch 为对象当前的 ETag 时才覆盖，IfNoneMatch 为 "*" 时仅当对象不存在时完成
+	IfMatch       string       `header:"If-Match,omitempty" xml:"-" url:"-"`
+	IfNoneMatch   string       `header:"If-None-Match,omitempty" xml:"-" url:"-"`
 	XOptionHeader *http.Header `header:"-,omitempty" xml:"-" url:"-"`
 }
 
@@ -336,6 +341,10 @@ type ObjectCopyPartOptions struct {
 	XCosCopySourceSSECustomerAglo   string `header:"x-cos-copy-source-server-side-encryption-customer-algorithm,omitempty" url:"-" xml:"-"`
 	XCosCopySourceSSECustomerKey    string `header:"x-cos-copy-source-server-side-encryption-customer-key,omitempty" url:"-" xml:"-"`
 	XCosCopySourceSSECustomerKeyMD5 string `header:"x-cos-copy-source-server-side-encryption-customer-key-MD5,omitempty" url:"-" xml:"-"`
+	// 目标对象为 SSE-C 加密时的密钥
+	SSE *SSE `header:"x-cos-server-side-encryption-customer,omitempty" url:"-" xml:"-"`
+	// 源对象为 SSE-C 加密时的密钥
+	CopySourceSSE *SSE `header:"x-cos-copy-source-server-side-encryption-customer,omitempty" url:"-" xml:"-"`
 	//兼容其他自定义头部
 	XOptionHeader *http.Header `header:"-,omitempty" url:"-" xml:"-"`
 }
@@ -515,18 +524,18 @@ func (s *ObjectService) innerHead(ctx context.Context, sourceURL string, opt *Ob
 		Transport: s.client.client.Transport,
 	})
 	if len(id) > 0 {
-		return client.Object.Head(ctx, surl[1], nil, id[0])
+		return client.Object.Head(ctx, surl[1], opt, id[0])
 	} else {
 		keyAndVer := strings.SplitN(surl[1], "?", 2)
 		if len(keyAndVer) < 2 {
 			// 不存在versionId
-			return client.Object.Head(ctx, surl[1], nil)
+			return client.Object.Head(ctx, surl[1], opt)
 		} else {
 			q, err := url.ParseQuery(keyAndVer[1])
 			if err != nil {
 				return nil, fmt.Errorf("sourceURL format error: %s", sourceURL)
 			}
-			return client.Object.Head(ctx, keyAndVer[0], nil, q.Get("versionId"))
+			return client.Object.Head(ctx, keyAndVer[0], opt, q.Get("versionId"))
 		}
 	}
 	return nil, fmt.Errorf("Head Err")
@@ -538,7 +547,17 @@ func (s *ObjectService) MultiCopy(ctx context.Context, name string, sourceURL st
 		return nil, nil, errors.New("sourceURL format is invalid.")
 	}
 
-	resp, err := s.innerHead(ctx, sourceURL, nil, id)
+	var headOpt *ObjectHeadOptions
+	if opt != nil && opt.OptCopy != nil && opt.OptCopy.ObjectCopyHeaderOptions != nil {
+		// 源对象为 SSE-C 加密时需要带上源对象的密钥
+		headOpt = &ObjectHeadOptions{
+			XCosSSECustomerAglo:   opt.OptCopy.XCosCopySourceSSECustomerAglo,
+			XCosSSECustomerKey:    opt.OptCopy.XCosCopySourceSSECustomerKey,
+			XCosSSECustomerKeyMD5: opt.OptCopy.XCosCopySourceSSECustomerKeyMD5,
+			SSE:                   opt.OptCopy.CopySourceSSE,
+		}
+	}
+	resp, err := s.innerHead(ctx, sourceURL, headOpt, id)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -585,6 +604,10 @@ func (s *ObjectService) MultiCopy(ctx context.Context, name string, sourceURL st
 	chjobs := make(chan *CopyJobs, 100)
 	chresults := make(chan *CopyResults, 10000)
 	optcom := &CompleteMultipartUploadOptions{}
+	if opt.OptCopy != nil && opt.OptCopy.ObjectCopyHeaderOptions != nil {
+		optcom.IfMatch = opt.OptCopy.IfMatch
+		optcom.IfNoneMatch = opt.OptCopy.IfNoneMatch
+	}
 
 	for w := 1; w <= poolSize; w++ {
 		go copyworker(ctx, s, chjobs, chresults)
@@ -603,6 +626,8 @@ func (s *ObjectService) MultiCopy(ctx context.Context, name string, sourceURL st
 				partOpt.XCosCopySourceSSECustomerAglo = opt.OptCopy.XCosCopySourceSSECustomerAglo
 				partOpt.XCosCopySourceSSECustomerKey = opt.OptCopy.XCosCopySourceSSECustomerKey
 				partOpt.XCosCopySourceSSECustomerKeyMD5 = opt.OptCopy.XCosCopySourceSSECustomerKeyMD5
+				partOpt.CopySourceSSE = opt.OptCopy.CopySourceSSE
+				partOpt.SSE = opt.OptCopy.SSE
 			}
 			job := &CopyJobs{
 				Name:       name,
@@ -0,0 +1,147 @@
+package cos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PresignMultipartUploadOptions is the option of PresignMultipartUpload
+type PresignMultipartUploadOptions struct {
+	// 初始化分块上传的参数
+	OptIni *InitiateMultipartUploadOptions
+	// 签名有效时间，为空时使用 expired
+	AuthTime *AuthTime
+	// 是否签入 Host，默认签入
+	SignHost *bool
+}
+
+// PresignedPart 单个分块的预签名信息
+type PresignedPart struct {
+	PartNumber int
+	Offset     int64
+	Size       int64
+	URL        *url.URL
+}
+
+// PresignMultipartUploadResult is the result of PresignMultipartUpload
+type PresignMultipartUploadResult struct {
+	Key      string
+	UploadID string
+	PartSize int64
+	// 按 PartNumber 升序排列
+	Parts       []PresignedPart
+	CompleteURL *url.URL
+	AbortURL    *url.URL
+	Expiration  time.Time
+}
+
+// PresignMultipartUpload 初始化分块上传，并为每个 UploadPart 以及 CompleteMultipartUpload、AbortMultipartUpload 生成预签名 URL，
+// 便于客户端（如浏览器）直接并发上传分块。size 与 partSize 单位为字节，partSize <= 0 时自动计算分块大小。
+//
+// 客户端上传完成后，可将各分块返回的 ETag 交由服务端调用 CompletePresignedMultipartUpload 完成上传。
+func (s *ObjectService) PresignMultipartUpload(ctx context.Context, name string, size, partSize int64, expired time.Duration, opt ...*PresignMultipartUploadOptions) (*PresignMultipartUploadResult, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("size must be >= 0")
+	}
+	var popt *PresignMultipartUploadOptions
+	if len(opt) > 0 && opt[0] != nil {
+		popt = opt[0]
+	} else {
+		popt = &PresignMultipartUploadOptions{}
+	}
+	chunks, _, err := SplitSizeIntoChunks(size, partSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		chunks = []Chunk{{Number: 1}}
+	}
+	if partSize <= 0 {
+		partSize = chunks[0].Size
+	}
+
+	authTime := popt.AuthTime
+	if authTime == nil {
+		authTime = NewAuthTime(expired)
+	}
+	signHost := true
+	if popt.SignHost != nil {
+		signHost = *popt.SignHost
+	}
+
+	res, _, err := s.InitiateMultipartUpload(ctx, name, popt.OptIni)
+	if err != nil {
+		return nil, err
+	}
+	result := &PresignMultipartUploadResult{
+		Key:        name,
+		UploadID:   res.UploadID,
+		PartSize:   partSize,
+		Parts:      make([]PresignedPart, 0, len(chunks)),
+		Expiration: authTime.SignEndTime,
+	}
+	presign := func(method string, query url.Values) (*url.URL, error) {
+		signOpt := &PresignedURLOptions{
+			Query:    &query,
+			AuthTime: authTime,
+		}
+		return s.GetPresignedURL2(ctx, method, name, expired, signOpt, signHost)
+	}
+	for _, chunk := range chunks {
+		u, err := presign(http.MethodPut, url.Values{
+			"partNumber": []string{strconv.Itoa(chunk.Number)},
+			"uploadId":   []string{res.UploadID},
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Parts = append(result.Parts, PresignedPart{
+			PartNumber: chunk.Number,
+			Offset:     chunk.OffSet,
+			Size:       chunk.Size,
+			URL:        u,
+		})
+	}
+	uploadQuery := url.Values{"uploadId": []string{res.UploadID}}
+	if result.CompleteURL, err = presign(http.MethodPost, uploadQuery); err != nil {
+		return nil, err
+	}
+	if result.AbortURL, err = presign(http.MethodDelete, uploadQuery); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CompletePresignedMultipartUpload 根据客户端上报的 PartNumber -> ETag 完成分块上传，ETag 可带或不带引号
+func (s *ObjectService) CompletePresignedMultipartUpload(ctx context.Context, name, uploadID string, etags map[int]string, opt ...*CompleteMultipartUploadOptions) (*CompleteMultipartUploadResult, *Response, error) {
+	if len(etags) == 0 {
+		return nil, nil, fmt.Errorf("etags is empty")
+	}
+	optcom := &CompleteMultipartUploadOptions{}
+	if len(opt) > 0 && opt[0] != nil {
+		// 保留调用方设置的 IfMatch、IfNoneMatch 等参数，只替换 Parts
+		*optcom = *opt[0]
+		optcom.Parts = nil
+	}
+	for number, etag := range etags {
+		if number < 1 || number > 10000 {
+			return nil, nil, fmt.Errorf("invalid part number: %v", number)
+		}
+		etag = strings.Trim(etag, "\"")
+		if etag == "" {
+			return nil, nil, fmt.Errorf("empty etag of part %v", number)
+		}
+		optcom.Parts = append(optcom.Parts, Object{
+			PartNumber: number,
+			ETag:       "\"" + etag + "\"",
+		})
+	}
+	sort.Sort(ObjectList(optcom.Parts))
+	return s.CompleteMultipartUpload(ctx, name, uploadID, optcom)
+}
@@ -0,0 +1,136 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestObjectService_PresignMultipartUpload(t *testing.T) {
+	setup()
+	defer teardown()
+
+	u, _ := url.Parse(server.URL)
+	client := NewClient(&BaseURL{u, u, u, u, u, u}, &http.Client{
+		Transport: &AuthorizationTransport{
+			SecretID:  "QmFzZTY0IGlzIGEgZ*******",
+			SecretKey: "ZfbOA78asKUYBcXFrJD0a1I*******",
+		},
+	})
+	name := "test/hello.txt"
+	uploadID := "149795194893578fd83aceef3a88f708f81f00e879fda5ea8a80bf15aba52746d42d512387"
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		testFormValues(t, r, values{
+			"uploads": "",
+		})
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult>
+	<Bucket>test-1253846586</Bucket>
+	<Key>test/hello.txt</Key>
+	<UploadId>%v</UploadId>
+</InitiateMultipartUploadResult>`, uploadID)
+	})
+
+	startTime := time.Unix(int64(1622702557), 0)
+	endTime := time.Unix(int64(1622706157), 0)
+	opt := &PresignMultipartUploadOptions{
+		AuthTime: &AuthTime{
+			SignStartTime: startTime,
+			SignEndTime:   endTime,
+			KeyStartTime:  startTime,
+			KeyEndTime:    endTime,
+		},
+	}
+	size := int64(5*1024*1024 + 100)
+	res, err := client.Object.PresignMultipartUpload(context.Background(), name, size, 1024*1024*2, time.Hour, opt)
+	if err != nil {
+		t.Fatalf("Object.PresignMultipartUpload returned error: %v", err)
+	}
+	if res.UploadID != uploadID || res.PartSize != 2*1024*1024 || !res.Expiration.Equal(endTime) {
+		t.Fatalf("Object.PresignMultipartUpload returned %+v", res)
+	}
+	if len(res.Parts) != 3 {
+		t.Fatalf("Object.PresignMultipartUpload returned %v parts, want 3", len(res.Parts))
+	}
+	var total int64
+	for i, part := range res.Parts {
+		if part.PartNumber != i+1 || part.Offset != total {
+			t.Errorf("part %v is %+v", i, part)
+		}
+		total += part.Size
+		q := part.URL.Query()
+		if q.Get("partNumber") != fmt.Sprint(i+1) || q.Get("uploadId") != uploadID {
+			t.Errorf("part %v url query: %v", i, q)
+		}
+		if q.Get("q-url-param-list") != "partnumber;uploadid" {
+			t.Errorf("part %v url param list: %v", i, q.Get("q-url-param-list"))
+		}
+		if q.Get("q-header-list") != "host" {
+			t.Errorf("part %v header list: %v", i, q.Get("q-header-list"))
+		}
+	}
+	if total != size {
+		t.Errorf("parts total size is %v, want %v", total, size)
+	}
+	for _, u := range []*url.URL{res.CompleteURL, res.AbortURL} {
+		q := u.Query()
+		if u.Path != "/test/hello.txt" || q.Get("uploadId") != uploadID || q.Get("q-url-param-list") != "uploadid" {
+			t.Errorf("wrong url: %v", u)
+		}
+	}
+}
+
+func TestObjectService_CompletePresignedMultipartUpload(t *testing.T) {
+	setup()
+	defer teardown()
+	name := "test/hello.txt"
+	uploadID := "149795194893578fd83aceef3a88f708f81f00e879fda5ea8a80bf15aba52746d42d512387"
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		testFormValues(t, r, values{
+			"uploadId": uploadID,
+		})
+		testHeader(t, r, "If-None-Match", "*")
+		v := new(CompleteMultipartUploadOptions)
+		xml.NewDecoder(r.Body).Decode(v)
+		want := &CompleteMultipartUploadOptions{
+			XMLName: xml.Name{Local: "CompleteMultipartUpload"},
+			Parts: []Object{
+				{PartNumber: 1, ETag: "\"fae3dba15f4d9b2d76cbaed5de3a08e3\""},
+				{PartNumber: 2, ETag: "\"c81982550f2f965118d486176d9541d4\""},
+			},
+		}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Object.CompletePresignedMultipartUpload request body: %+v, want %+v", v, want)
+		}
+		fmt.Fprint(w, `<CompleteMultipartUploadResult>
+	<Location>test-1253846586.cos.ap-guangzhou.myqcloud.com/test/hello.txt</Location>
+	<Bucket>test-1253846586</Bucket>
+	<Key>test/hello.txt</Key>
+	<ETag>&quot;594f98b11c6901c0f0683de1085a6d0e-2&quot;</ETag>
+</CompleteMultipartUploadResult>`)
+	})
+
+	etags := map[int]string{
+		2: "c81982550f2f965118d486176d9541d4",
+		1: "\"fae3dba15f4d9b2d76cbaed5de3a08e3\"",
+	}
+	opt := &CompleteMultipartUploadOptions{
+		Parts:       []Object{{PartNumber: 3, ETag: "ignored"}},
+		IfNoneMatch: "*",
+	}
+	res, _, err := client.Object.CompletePresignedMultipartUpload(context.Background(), name, uploadID, etags, opt)
+	if err != nil {
+		t.Fatalf("Object.CompletePresignedMultipartUpload returned error: %v", err)
+	}
+	if res.ETag != "\"594f98b11c6901c0f0683de1085a6d0e-2\"" {
+		t.Errorf("Object.CompletePresignedMultipartUpload returned %+v", res)
+	}
+}
@@ -0,0 +1,271 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RestoreStatus 由 x-cos-restore 头部解析得到的归档对象恢复状态
+type RestoreStatus struct {
+	// 是否正在恢复
+	OngoingRequest bool
+	// 恢复出的临时副本的过期时间，恢复未完成时为零值
+	ExpiryDate time.Time
+}
+
+// ParseRestoreHeader 解析 x-cos-restore 头部，例如:
+// ongoing-request="false", expiry-date="Thu, 01 Dec 2022 00:00:00 GMT"
+// 头部为空(对象没有发起过恢复)时返回 nil
+func ParseRestoreHeader(v string) (*RestoreStatus, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	status := &RestoreStatus{}
+	hasOngoing := false
+	for v != "" {
+		i := strings.Index(v, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid x-cos-restore: %v", v)
+		}
+		key := strings.TrimSpace(v[:i])
+		v = strings.TrimSpace(v[i+1:])
+		var value string
+		if strings.HasPrefix(v, "\"") {
+			j := strings.Index(v[1:], "\"")
+			if j < 0 {
+				return nil, fmt.Errorf("invalid x-cos-restore: %v", v)
+			}
+			value, v = v[1:j+1], v[j+2:]
+		} else {
+			j := strings.Index(v, ",")
+			if j < 0 {
+				j = len(v)
+			}
+			value, v = v[:j], v[j:]
+		}
+		v = strings.TrimLeft(strings.TrimSpace(v), ",")
+		v = strings.TrimSpace(v)
+
+		switch strings.ToLower(key) {
+		case "ongoing-request":
+			hasOngoing = true
+			status.OngoingRequest = strings.EqualFold(value, "true")
+		case "expiry-date":
+			t, err := http.ParseTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expiry-date in x-cos-restore: %v", value)
+			}
+			status.ExpiryDate = t
+		}
+	}
+	if !hasOngoing {
+		return nil, fmt.Errorf("ongoing-request not found in x-cos-restore")
+	}
+	return status, nil
+}
+
+// RestoreAndWaitOptions is the option of RestoreAndWait
+type RestoreAndWaitOptions struct {
+	// 首次轮询间隔，默认 30s，之后每次翻倍，最大为 MaxInterval(默认 10min)
+	Interval    time.Duration
+	MaxInterval time.Duration
+	VersionId   string
+}
+
+// RestoreAndWait 发起归档对象的恢复并轮询 Head 直到对象可读，已在恢复中(RestoreAlreadyInProgress)时直接开始轮询。
+// tier 可选值: Expedited、Standard、Bulk
+func (s *ObjectService) RestoreAndWait(ctx context.Context, name string, days int, tier string, opt ...*RestoreAndWaitOptions) (*RestoreStatus, error) {
+	ropt := &RestoreAndWaitOptions{}
+	if len(opt) > 0 && opt[0] != nil {
+		ropt = opt[0]
+	}
+	interval := ropt.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	maxInterval := ropt.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = 10 * time.Minute
+	}
+	var id []string
+	if ropt.VersionId != "" {
+		id = append(id, ropt.VersionId)
+	}
+
+	status, err := s.headRestoreStatus(ctx, name, id...)
+	if err != nil {
+		return nil, err
+	}
+	if status != nil && !status.OngoingRequest {
+		return status, nil
+	}
+	if status == nil {
+		restoreOpt := &ObjectRestoreOptions{
+			Days: days,
+			Tier: &CASJobParameters{Tier: tier},
+		}
+		_, err = s.PostRestore(ctx, name, restoreOpt, id...)
+		if err != nil && !errors.Is(err, ErrRestoreAlreadyInProgress) {
+			return nil, err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-time.After(interval):
+		}
+		status, err = s.headRestoreStatus(ctx, name, id...)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil && !status.OngoingRequest {
+			return status, nil
+		}
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+func (s *ObjectService) headRestoreStatus(ctx context.Context, name string, id ...string) (*RestoreStatus, error) {
+	resp, err := s.Head(ctx, name, nil, id...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRestoreHeader(resp.Header.Get("x-cos-restore"))
+}
+
+// isArchived 对象是否处于需要恢复才能读取的归档存储类型或归档层
+func isArchived(obj *Object) bool {
+	for _, c := range []string{obj.StorageClass, obj.StorageTier} {
+		switch strings.ToUpper(c) {
+		case "ARCHIVE", "DEEP_ARCHIVE", "ARCHIVE_ACCESS", "DEEP_ARCHIVE_ACCESS":
+			return true
+		}
+	}
+	return false
+}
+
+// RestorePrefixOptions is the option of RestorePrefix
+type RestorePrefixOptions struct {
+	// 并发恢复的对象数，默认为 1
+	ThreadPoolSize int
+	// 是否等待每个对象恢复完成
+	Wait        bool
+	WaitOptions *RestoreAndWaitOptions
+	// 每处理完一个对象回调一次
+	Listener func(progress *RestorePrefixResult)
+}
+
+// RestorePrefixResult 是 RestorePrefix 的结果与进度
+type RestorePrefixResult struct {
+	// 遍历到的归档对象数
+	Total int
+	// 已发起恢复(Wait 为 true 时为已恢复完成)的对象数
+	Restored int
+	// 已在恢复中的对象数
+	InProgress int
+	// 非归档存储类型而跳过的对象数
+	Skipped int
+	Failed  map[string]error
+}
+
+func (r *RestorePrefixResult) clone() *RestorePrefixResult {
+	res := *r
+	res.Failed = make(map[string]error, len(r.Failed))
+	for k, v := range r.Failed {
+		res.Failed[k] = v
+	}
+	return &res
+}
+
+// RestorePrefix 恢复 prefix 下所有归档存储类型的对象
+func (s *ObjectService) RestorePrefix(ctx context.Context, prefix string, days int, tier string, opt *RestorePrefixOptions) (*RestorePrefixResult, error) {
+	if opt == nil {
+		opt = &RestorePrefixOptions{}
+	}
+	poolSize := opt.ThreadPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+
+	res := &RestorePrefixResult{Failed: map[string]error{}}
+	var mu sync.Mutex
+	report := func(update func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		update()
+		if opt.Listener != nil {
+			opt.Listener(res.clone())
+		}
+	}
+
+	keys := make(chan string, poolSize)
+	var wg sync.WaitGroup
+	for i := 0; i < poolSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				inProgress := false
+				var err error
+				if opt.Wait {
+					_, err = s.RestoreAndWait(ctx, key, days, tier, opt.WaitOptions)
+				} else {
+					_, err = s.PostRestore(ctx, key, &ObjectRestoreOptions{
+						Days: days,
+						Tier: &CASJobParameters{Tier: tier},
+					})
+					if errors.Is(err, ErrRestoreAlreadyInProgress) {
+						inProgress, err = true, nil
+					}
+				}
+				report(func() {
+					switch {
+					case err != nil:
+						res.Failed[key] = err
+					case inProgress:
+						res.InProgress++
+					default:
+						res.Restored++
+					}
+				})
+			}
+		}()
+	}
+
+	var err error
+	it := s.newObjectListIterator(ctx, prefix)
+	for {
+		var obj Object
+		obj, err = it.Next()
+		if err != nil {
+			break
+		}
+		if !isArchived(&obj) {
+			mu.Lock()
+			res.Skipped++
+			mu.Unlock()
+			continue
+		}
+		mu.Lock()
+		res.Total++
+		mu.Unlock()
+		keys <- obj.Key
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	close(keys)
+	wg.Wait()
+	return res, err
+}
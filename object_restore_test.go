@@ -0,0 +1,137 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRestoreHeader(t *testing.T) {
+	cases := []struct {
+		header string
+		want   *RestoreStatus
+		err    bool
+	}{
+		{"", nil, false},
+		{`ongoing-request="true"`, &RestoreStatus{OngoingRequest: true}, false},
+		{`ongoing-request="false", expiry-date="Thu, 01 Dec 2022 00:00:00 GMT"`, &RestoreStatus{
+			ExpiryDate: time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC),
+		}, false},
+		{`expiry-date="Thu, 01 Dec 2022 00:00:00 GMT"`, nil, true},
+		{`ongoing-request="false", expiry-date="invalid"`, nil, true},
+		{`ongoing-request`, nil, true},
+	}
+	for _, c := range cases {
+		res, err := ParseRestoreHeader(c.header)
+		if (err != nil) != c.err {
+			t.Errorf("ParseRestoreHeader(%v) returned error: %v", c.header, err)
+			continue
+		}
+		if !c.err && !reflect.DeepEqual(res, c.want) {
+			t.Errorf("ParseRestoreHeader(%v) returned %+v, want %+v", c.header, res, c.want)
+		}
+	}
+}
+
+func TestObjectService_RestoreAndWait(t *testing.T) {
+	setup()
+	defer teardown()
+
+	heads := 0
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			testFormValues(t, r, values{"restore": ""})
+			body := &ObjectRestoreOptions{}
+			xml.NewDecoder(r.Body).Decode(body)
+			if body.Days != 3 || body.Tier.Tier != "Expedited" {
+				t.Errorf("Object.RestoreAndWait body: %+v", body)
+			}
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `<Error><Code>RestoreAlreadyInProgress</Code></Error>`)
+		case http.MethodHead:
+			heads++
+			switch {
+			case heads == 1:
+			case heads < 4:
+				w.Header().Set("x-cos-restore", `ongoing-request="true"`)
+			default:
+				w.Header().Set("x-cos-restore", `ongoing-request="false", expiry-date="Thu, 01 Dec 2022 00:00:00 GMT"`)
+			}
+		}
+	})
+
+	status, err := client.Object.RestoreAndWait(context.Background(), "test", 3, "Expedited", &RestoreAndWaitOptions{
+		Interval:    time.Millisecond,
+		MaxInterval: 2 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Object.RestoreAndWait returned error: %v", err)
+	}
+	if status.OngoingRequest || status.ExpiryDate.IsZero() || heads != 4 {
+		t.Errorf("Object.RestoreAndWait returned %+v after %v heads", status, heads)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	heads = 1
+	_, err = client.Object.RestoreAndWait(ctx, "test", 3, "Expedited", &RestoreAndWaitOptions{
+		Interval: time.Second,
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("Object.RestoreAndWait returned %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestObjectService_RestorePrefix(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `<ListBucketResult>
+	<Contents><Key>dir/a</Key><StorageClass>ARCHIVE</StorageClass></Contents>
+	<Contents><Key>dir/b</Key><StorageClass>DEEP_ARCHIVE</StorageClass></Contents>
+	<Contents><Key>dir/c</Key><StorageClass>INTELLIGENT_TIERING</StorageClass><StorageTier>ARCHIVE_ACCESS</StorageTier></Contents>
+	<Contents><Key>dir/d</Key><StorageClass>STANDARD</StorageClass></Contents>
+	<Contents><Key>dir/e</Key><StorageClass>ARCHIVE</StorageClass></Contents>
+</ListBucketResult>`)
+			return
+		}
+		testMethod(t, r, http.MethodPost)
+		switch strings.TrimPrefix(r.URL.Path, "/") {
+		case "dir/b":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `<Error><Code>RestoreAlreadyInProgress</Code></Error>`)
+		case "dir/e":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code></Error>`)
+		}
+	})
+
+	var mu sync.Mutex
+	var reports []*RestorePrefixResult
+	res, err := client.Object.RestorePrefix(context.Background(), "dir/", 1, "Bulk", &RestorePrefixOptions{
+		ThreadPoolSize: 2,
+		Listener: func(p *RestorePrefixResult) {
+			mu.Lock()
+			reports = append(reports, p)
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.RestorePrefix returned error: %v", err)
+	}
+	if res.Total != 4 || res.Restored != 2 || res.InProgress != 1 || res.Skipped != 1 || len(res.Failed) != 1 || res.Failed["dir/e"] == nil {
+		t.Errorf("Object.RestorePrefix returned %+v", res)
+	}
+	if len(reports) != 4 {
+		t.Errorf("Object.RestorePrefix reported %v times, want 4", len(reports))
+	}
+}
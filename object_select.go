@@ -58,6 +58,12 @@ type ObjectSelectOptions struct {
 	InputSerialization  *SelectInputSerialization  `xml:"InputSerialization"`
 	OutputSerialization *SelectOutputSerialization `xml:"OutputSerialization"`
 	RequestProgress     string                     `xml:"RequestProgress>Enabled,omitempty"`
+	// SSE-C 对象的密钥
+	SSE *SSE `xml:"-"`
+}
+
+type objectSelectHeaderOptions struct {
+	SSE *SSE `header:"x-cos-server-side-encryption-customer,omitempty"`
 }
 
 func (s *ObjectService) Select(ctx context.Context, name string, opt *ObjectSelectOptions) (io.ReadCloser, error) {
@@ -69,6 +75,9 @@ func (s *ObjectService) Select(ctx context.Context, name string, opt *ObjectSele
 		body:             opt,
 		disableCloseBody: true,
 	}
+	if opt != nil && opt.SSE != nil {
+		sendOpt.optHeader = &objectSelectHeaderOptions{SSE: opt.SSE}
+	}
 	resp, err := s.client.send(ctx, &sendOpt)
 	if err != nil {
 		return nil, err
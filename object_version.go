@@ -0,0 +1,240 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ObjectVersion 对象的一个历史版本或删除标记
+type ObjectVersion struct {
+	Key            string
+	VersionId      string
+	IsLatest       bool
+	IsDeleteMarker bool
+	LastModified   time.Time
+	ETag           string
+	Size           int64
+	StorageClass   string
+}
+
+// ObjectDeleteError 批量删除时单个对象(版本)的删除失败信息
+type ObjectDeleteError struct {
+	Key       string
+	VersionId string
+	Code      string
+	Message   string
+}
+
+func (e *ObjectDeleteError) Error() string {
+	return fmt.Sprintf("delete %v(versionId: %v) failed: %v %v", e.Key, e.VersionId, e.Code, e.Message)
+}
+
+// PurgeVersionsResult is the result of PurgeVersions
+type PurgeVersionsResult struct {
+	// 删除成功的版本与删除标记数
+	Deleted int
+	Failed  []ObjectDeleteError
+}
+
+// versionsOfPage 合并一页结果中的版本与删除标记，按 key 升序、同一 key 按修改时间降序排列
+func versionsOfPage(res *BucketGetObjectVersionsResult) ([]ObjectVersion, error) {
+	vs := make([]ObjectVersion, 0, len(res.Version)+len(res.DeleteMarker))
+	for _, v := range res.Version {
+		t, err := time.Parse(time.RFC3339, v.LastModified)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LastModified of %v: %v", v.Key, err)
+		}
+		vs = append(vs, ObjectVersion{
+			Key:          v.Key,
+			VersionId:    v.VersionId,
+			IsLatest:     v.IsLatest,
+			LastModified: t,
+			ETag:         v.ETag,
+			Size:         v.Size,
+			StorageClass: v.StorageClass,
+		})
+	}
+	for _, m := range res.DeleteMarker {
+		t, err := time.Parse(time.RFC3339, m.LastModified)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LastModified of %v: %v", m.Key, err)
+		}
+		vs = append(vs, ObjectVersion{
+			Key:            m.Key,
+			VersionId:      m.VersionId,
+			IsLatest:       m.IsLatest,
+			IsDeleteMarker: true,
+			LastModified:   t,
+		})
+	}
+	sort.SliceStable(vs, func(i, j int) bool {
+		if vs[i].Key != vs[j].Key {
+			return vs[i].Key < vs[j].Key
+		}
+		if vs[i].IsLatest != vs[j].IsLatest {
+			return vs[i].IsLatest
+		}
+		return vs[i].LastModified.After(vs[j].LastModified)
+	})
+	return vs, nil
+}
+
+// versionIterator 逐个遍历 prefix 下的所有版本与删除标记，按页调用 GetObjectVersions
+type versionIterator struct {
+	ctx    context.Context
+	bucket *BucketService
+	opt    *BucketGetObjectVersionsOptions
+	buf    []ObjectVersion
+	done   bool
+}
+
+func (s *ObjectService) newVersionIterator(ctx context.Context, prefix string) *versionIterator {
+	return &versionIterator{
+		ctx:    ctx,
+		bucket: &BucketService{client: s.client},
+		opt: &BucketGetObjectVersionsOptions{
+			Prefix:  prefix,
+			MaxKeys: 1000,
+		},
+	}
+}
+
+// next 返回下一个版本，遍历结束时返回 io.EOF
+func (it *versionIterator) next() (ObjectVersion, error) {
+	for len(it.buf) == 0 {
+		if it.done {
+			return ObjectVersion{}, io.EOF
+		}
+		res, _, err := it.bucket.GetObjectVersions(it.ctx, it.opt)
+		if err != nil {
+			return ObjectVersion{}, err
+		}
+		it.buf, err = versionsOfPage(res)
+		if err != nil {
+			return ObjectVersion{}, err
+		}
+		it.done = !res.IsTruncated
+		it.opt.KeyMarker = res.NextKeyMarker
+		it.opt.VersionIdMarker = res.NextVersionIdMarker
+	}
+	v := it.buf[0]
+	it.buf = it.buf[1:]
+	return v, nil
+}
+
+// ListKeyVersions 返回 key 的全部历史版本与删除标记，最新的在前
+func (s *ObjectService) ListKeyVersions(ctx context.Context, key string) ([]ObjectVersion, error) {
+	var res []ObjectVersion
+	it := s.newVersionIterator(ctx, key)
+	for {
+		v, err := it.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if v.Key == key {
+			res = append(res, v)
+		} else if v.Key > key {
+			// 已遍历到以 key 为前缀的其它对象
+			break
+		}
+	}
+	return res, nil
+}
+
+// RestoreVersion 将 key 的历史版本 versionID 拷贝为最新版本，opt 可为 nil
+func (s *ObjectService) RestoreVersion(ctx context.Context, key, versionID string, opt *ObjectCopyOptions) (*ObjectCopyResult, *Response, error) {
+	if versionID == "" {
+		return nil, nil, errors.New("versionID is empty")
+	}
+	sourceURL := fmt.Sprintf("%s/%s", s.client.BaseURL.BucketURL.Host, key)
+	return s.Copy(ctx, key, sourceURL, opt, versionID)
+}
+
+// Undelete 删除 key 最新的删除标记，使之前的版本重新成为最新版本
+func (s *ObjectService) Undelete(ctx context.Context, key string) (*Response, error) {
+	vs, err := s.ListKeyVersions(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(vs) == 0 || !vs[0].IsDeleteMarker {
+		return nil, fmt.Errorf("the latest version of %v is not a delete marker", key)
+	}
+	return s.Delete(ctx, key, &ObjectDeleteOptions{VersionId: vs[0].VersionId})
+}
+
+// PurgeVersions 删除 prefix 下每个对象除最新 keepLast 个版本以外、且修改时间早于 olderThan 的版本与删除标记，
+// 删除标记不计入 keepLast，olderThan 为零值时不限制时间。通过 DeleteMulti 每批删除 1000 个版本
+func (s *ObjectService) PurgeVersions(ctx context.Context, prefix string, keepLast int, olderThan time.Time) (*PurgeVersionsResult, error) {
+	res := &PurgeVersionsResult{}
+	var pending []Object
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		dres, _, err := s.DeleteMulti(ctx, &ObjectDeleteMultiOptions{
+			Quiet:   true,
+			Objects: pending,
+		})
+		if err != nil {
+			return err
+		}
+		res.Deleted += len(pending) - len(dres.Errors)
+		for _, e := range dres.Errors {
+			res.Failed = append(res.Failed, ObjectDeleteError{
+				Key:       e.Key,
+				VersionId: e.VersionId,
+				Code:      e.Code,
+				Message:   e.Message,
+			})
+		}
+		pending = pending[:0]
+		return nil
+	}
+
+	var curKey string
+	var count int
+	it := s.newVersionIterator(ctx, prefix)
+	for {
+		v, err := it.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return res, err
+		}
+		if v.Key != curKey {
+			curKey, count = v.Key, 0
+		}
+		if v.IsDeleteMarker {
+			// 删除标记不计入 keepLast，保留最新的删除标记以免恢复已删除的对象
+			if keepLast > 0 && v.IsLatest {
+				continue
+			}
+		} else {
+			count++
+			if count <= keepLast {
+				continue
+			}
+		}
+		if !olderThan.IsZero() && !v.LastModified.Before(olderThan) {
+			continue
+		}
+		pending = append(pending, Object{Key: v.Key, VersionId: v.VersionId})
+		if len(pending) == 1000 {
+			if err = flush(); err != nil {
+				return res, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return res, err
+	}
+	return res, nil
+}
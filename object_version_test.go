@@ -0,0 +1,164 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testListVersionsResult = `<ListVersionsResult>
+	<Prefix>%v</Prefix>
+	<IsTruncated>false</IsTruncated>
+	<Version>
+		<Key>a</Key>
+		<VersionId>a3</VersionId>
+		<IsLatest>false</IsLatest>
+		<LastModified>2026-10-03T00:00:00.000Z</LastModified>
+	</Version>
+	<Version>
+		<Key>a</Key>
+		<VersionId>a2</VersionId>
+		<IsLatest>false</IsLatest>
+		<LastModified>2026-10-02T00:00:00.000Z</LastModified>
+	</Version>
+	<Version>
+		<Key>a</Key>
+		<VersionId>a1</VersionId>
+		<IsLatest>false</IsLatest>
+		<LastModified>2026-10-01T00:00:00.000Z</LastModified>
+	</Version>
+	<Version>
+		<Key>ab</Key>
+		<VersionId>ab1</VersionId>
+		<IsLatest>true</IsLatest>
+		<LastModified>2026-10-01T00:00:00.000Z</LastModified>
+	</Version>
+	<DeleteMarker>
+		<Key>a</Key>
+		<VersionId>a4</VersionId>
+		<IsLatest>true</IsLatest>
+		<LastModified>2026-10-04T00:00:00.000Z</LastModified>
+	</DeleteMarker>
+</ListVersionsResult>`
+
+func TestObjectService_ListKeyVersions(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		testFormValues(t, r, values{"versions": "", "prefix": "a", "max-keys": "1000"})
+		fmt.Fprintf(w, testListVersionsResult, "a")
+	})
+
+	vs, err := client.Object.ListKeyVersions(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Object.ListKeyVersions returned error: %v", err)
+	}
+	var ids []string
+	for _, v := range vs {
+		if v.Key != "a" {
+			t.Errorf("Object.ListKeyVersions returned key %v", v.Key)
+		}
+		ids = append(ids, v.VersionId)
+	}
+	if want := []string{"a4", "a3", "a2", "a1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Object.ListKeyVersions returned %v, want %v", ids, want)
+	}
+	if !vs[0].IsDeleteMarker || !vs[0].IsLatest {
+		t.Errorf("Object.ListKeyVersions latest should be a delete marker")
+	}
+}
+
+func TestObjectService_Undelete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, testListVersionsResult, "a")
+	})
+	deleted := ""
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodDelete)
+		deleted = r.URL.Query().Get("VersionId")
+	})
+	_, err := client.Object.Undelete(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Object.Undelete returned error: %v", err)
+	}
+	if deleted != "a4" {
+		t.Errorf("Object.Undelete deleted %v, want a4", deleted)
+	}
+
+	_, err = client.Object.Undelete(context.Background(), "ab")
+	if err == nil {
+		t.Errorf("Object.Undelete should return error when the latest is not a delete marker")
+	}
+}
+
+func TestObjectService_RestoreVersion(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		source := r.Header.Get("x-cos-copy-source")
+		if !strings.HasSuffix(source, "/a?versionId=a2") {
+			t.Errorf("Object.RestoreVersion copy source is %v", source)
+		}
+		fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+	})
+	_, _, err := client.Object.RestoreVersion(context.Background(), "a", "a2", nil)
+	if err != nil {
+		t.Fatalf("Object.RestoreVersion returned error: %v", err)
+	}
+}
+
+func TestObjectService_PurgeVersions(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var deleted []Object
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, testListVersionsResult, "a")
+			return
+		}
+		testMethod(t, r, http.MethodPost)
+		body := &ObjectDeleteMultiOptions{}
+		xml.NewDecoder(r.Body).Decode(body)
+		if !body.Quiet {
+			t.Errorf("Object.PurgeVersions should use quiet mode")
+		}
+		deleted = append(deleted, body.Objects...)
+		fmt.Fprint(w, `<DeleteResult><Error><Key>a</Key><VersionId>a1</VersionId><Code>AccessDenied</Code></Error></DeleteResult>`)
+	})
+
+	olderThan := time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC)
+	res, err := client.Object.PurgeVersions(context.Background(), "a", 1, olderThan)
+	if err != nil {
+		t.Fatalf("Object.PurgeVersions returned error: %v", err)
+	}
+	want := []Object{{Key: "a", VersionId: "a2"}, {Key: "a", VersionId: "a1"}}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("Object.PurgeVersions deleted %+v, want %+v", deleted, want)
+	}
+	if res.Deleted != 1 || len(res.Failed) != 1 || res.Failed[0].VersionId != "a1" {
+		t.Errorf("Object.PurgeVersions returned %+v", res)
+	}
+	// 最新的删除标记 a4 不计入 keepLast
+	deleted = nil
+	_, err = client.Object.PurgeVersions(context.Background(), "a", 2, time.Time{})
+	if err != nil {
+		t.Fatalf("Object.PurgeVersions returned error: %v", err)
+	}
+	want = []Object{{Key: "a", VersionId: "a1"}}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("Object.PurgeVersions deleted %+v, want %+v", deleted, want)
+	}
+}
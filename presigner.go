@@ -0,0 +1,145 @@
+package cos
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PresignOptions is the option of Presigner
+type PresignOptions struct {
+	// 需要签入的请求参数
+	Query *url.Values
+	// 需要签入的请求头部，只有 NeedSignHeaders 中的头部、x-cos-/x-ci- 前缀头部以及 SignHeaders 指定的头部会被签入
+	Header *http.Header
+	// 签名生效时间，为空时使用当前时间
+	StartTime time.Time
+	// 签名有效时长，为 0 时默认一小时
+	Expire time.Duration
+	// 是否签入 Host，为空时默认签入
+	SignHost *bool
+	// 额外需要签入的头部
+	SignHeaders []string
+	// 签名合并到 sign 参数中
+	SignMerged bool
+}
+
+// Presigner 不依赖 Client 的离线签名工具，适用于边缘函数等无法发起网络请求的场景
+type Presigner struct {
+	BaseURL    *BaseURL
+	Credential CredentialIface
+}
+
+// NewPresigner 创建 Presigner，BaseURL 只需设置 BucketURL
+func NewPresigner(uri *BaseURL, cred CredentialIface) *Presigner {
+	return &Presigner{
+		BaseURL:    uri,
+		Credential: cred,
+	}
+}
+
+// PresignURL 生成预签名 URL，临时密钥的 token 以 x-cos-security-token 参数的形式带上
+func (p *Presigner) PresignURL(method, name string, opt *PresignOptions) (*url.URL, error) {
+	if opt == nil {
+		opt = &PresignOptions{}
+	}
+	query := url.Values{}
+	if opt.Query != nil {
+		for k, v := range *opt.Query {
+			query[k] = append([]string{}, v...)
+		}
+	}
+	if token := p.Credential.GetToken(); token != "" {
+		query.Set("x-cos-security-token", token)
+	}
+	req, authorization, err := p.sign(method, name, query, opt)
+	if err != nil {
+		return nil, err
+	}
+	var sign string
+	if opt.SignMerged {
+		sign = "sign=" + encodeURIComponent(authorization)
+	} else {
+		sign = encodeURIComponent(authorization, []byte{'&', '='})
+	}
+	if req.URL.RawQuery == "" {
+		req.URL.RawQuery = sign
+	} else {
+		req.URL.RawQuery = fmt.Sprintf("%s&%s", req.URL.RawQuery, sign)
+	}
+	return req.URL, nil
+}
+
+// SignHeader 生成请求所需的头部，包括 Authorization、x-cos-security-token 以及 opt.Header 中的头部
+func (p *Presigner) SignHeader(method, name string, opt *PresignOptions) (http.Header, error) {
+	if opt == nil {
+		opt = &PresignOptions{}
+	}
+	query := url.Values{}
+	if opt.Query != nil {
+		query = *opt.Query
+	}
+	req, authorization, err := p.sign(method, name, query, opt)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorization)
+	return req.Header, nil
+}
+
+func (p *Presigner) sign(method, name string, query url.Values, opt *PresignOptions) (*http.Request, string, error) {
+	if p.BaseURL == nil || p.BaseURL.BucketURL == nil {
+		return nil, "", invalidBucketErr
+	}
+	if p.Credential == nil {
+		return nil, "", fmt.Errorf("credential is empty")
+	}
+	if name == "" {
+		return nil, "", fmt.Errorf("object key is empty.")
+	}
+	// 兼容 name 以 / 开头的情况
+	if strings.HasPrefix(name, "/") {
+		name = encodeURIComponent("/") + encodeURIComponent(name[1:], []byte{'/'})
+	} else {
+		name = encodeURIComponent(name, []byte{'/'})
+	}
+	urlStr := fmt.Sprintf("%s://%s/%s", p.BaseURL.BucketURL.Scheme, p.BaseURL.BucketURL.Host, name)
+	if qs := query.Encode(); qs != "" {
+		urlStr = urlStr + "?" + qs
+	}
+	req, err := http.NewRequest(method, urlStr, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if opt.Header != nil {
+		for k, v := range *opt.Header {
+			req.Header[k] = append([]string{}, v...)
+		}
+	}
+	if token := p.Credential.GetToken(); token != "" && query.Get("x-cos-security-token") == "" {
+		req.Header.Set("x-cos-security-token", token)
+	}
+
+	startTime := opt.StartTime
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+	expire := opt.Expire
+	if expire == 0 {
+		expire = defaultAuthExpire
+	}
+	authTime := &AuthTime{
+		SignStartTime: startTime,
+		SignEndTime:   startTime.Add(expire),
+		KeyStartTime:  startTime,
+		KeyEndTime:    startTime.Add(expire),
+	}
+	signHost := true
+	if opt.SignHost != nil {
+		signHost = *opt.SignHost
+	}
+	authorization := newAuthorization(p.Credential.GetSecretId(), p.Credential.GetSecretKey(), req, authTime, signHost, opt.SignHeaders...)
+	return req, authorization, nil
+}
@@ -0,0 +1,229 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func TestPresigner_Golden(t *testing.T) {
+	u, _ := url.Parse("https://examplebucket-1250000000.cos.ap-guangzhou.myqcloud.com")
+	startTime := time.Unix(int64(1622702557), 0)
+	cred := &Credential{
+		SecretID:  "QmFzZTY0IGlzIGEgZ*******",
+		SecretKey: "ZfbOA78asKUYBcXFrJD0a1I*******",
+	}
+	tokenCred := NewTokenCredential(cred.SecretID, cred.SecretKey, "token-example")
+	query := &url.Values{}
+	query.Set("response-content-type", "application/json")
+	query.Set("versionId", "MTg0NDUxNTc1NjIzMTQ1MDAwODg")
+	header := &http.Header{}
+	header.Set("Content-Type", "text/plain")
+	header.Set("X-Cos-Meta-Author", "cos")
+	header.Set("Content-Language", "zh-CN")
+
+	cases := []struct {
+		name   string
+		cred   CredentialIface
+		method string
+		key    string
+		opt    *PresignOptions
+	}{
+		{"put-without-host", cred, http.MethodPut, "test.jpg", &PresignOptions{StartTime: startTime, SignHost: Bool(false)}},
+		{"get-with-host", cred, http.MethodGet, "dir/test.jpg", &PresignOptions{StartTime: startTime, Expire: time.Minute}},
+		{"get-with-query", cred, http.MethodGet, "/dir/测试 file.txt", &PresignOptions{StartTime: startTime, Query: query}},
+		{"put-with-header", cred, http.MethodPut, "test.txt", &PresignOptions{StartTime: startTime, Header: header}},
+		{"put-with-extra-header", cred, http.MethodPut, "test.txt", &PresignOptions{StartTime: startTime, Header: header, SignHeaders: []string{"content-language"}}},
+		{"get-with-token-merged", tokenCred, http.MethodGet, "test.txt", &PresignOptions{StartTime: startTime, Query: query, SignMerged: true}},
+	}
+
+	got := map[string]string{}
+	for _, c := range cases {
+		p := NewPresigner(&BaseURL{BucketURL: u}, c.cred)
+		presignedURL, err := p.PresignURL(c.method, c.key, c.opt)
+		if err != nil {
+			t.Fatalf("%v: PresignURL returned error: %v", c.name, err)
+		}
+		got[c.name+"/url"] = presignedURL.String()
+		h, err := p.SignHeader(c.method, c.key, c.opt)
+		if err != nil {
+			t.Fatalf("%v: SignHeader returned error: %v", c.name, err)
+		}
+		got[c.name+"/authorization"] = h.Get("Authorization")
+	}
+
+	golden := filepath.Join("testdata", "presigner.golden.json")
+	if *updateGolden {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		enc.Encode(got)
+		if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bs, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{}
+	if err = json.Unmarshal(bs, &want); err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != len(got) {
+		t.Errorf("golden has %v entries, got %v", len(want), len(got))
+	}
+	for k, v := range got {
+		if want[k] != v {
+			t.Errorf("%v: got %v, want %v", k, v, want[k])
+		}
+	}
+
+	// 与 GetPresignedURL 相同的签名向量
+	exceptSign := "q-sign-algorithm=sha1&q-ak=QmFzZTY0IGlzIGEgZ*******&q-sign-time=1622702557%3B1622706157&q-key-time=1622702557%3B1622706157&q-header-list=&q-url-param-list=&q-signature=820975b5a8eccce9455b94d4ebed14d66654bf3c"
+	if want["put-without-host/url"] != u.String()+"/test.jpg?"+exceptSign {
+		t.Errorf("put-without-host: got %v", want["put-without-host/url"])
+	}
+}
+
+func TestPresigner_MatchGetPresignedURL(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ak := "QmFzZTY0IGlzIGEgZ*******"
+	sk := "ZfbOA78asKUYBcXFrJD0a1I*******"
+	startTime := time.Unix(int64(1622702557), 0)
+	endTime := startTime.Add(time.Hour)
+	query := &url.Values{}
+	query.Set("test", "params")
+	opt := &PresignedURLOptions{
+		Query: query,
+		AuthTime: &AuthTime{
+			SignStartTime: startTime,
+			SignEndTime:   endTime,
+			KeyStartTime:  startTime,
+			KeyEndTime:    endTime,
+		},
+	}
+	want, err := client.Object.GetPresignedURL(context.Background(), http.MethodGet, "dir/test.jpg", ak, sk, time.Hour, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPresigner(client.BaseURL, NewTokenCredential(ak, sk, ""))
+	got, err := p.PresignURL(http.MethodGet, "dir/test.jpg", &PresignOptions{
+		Query:     query,
+		StartTime: startTime,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("Presigner.PresignURL returned %v, want %v", got, want)
+	}
+}
+
+// hmacSHA1Hex 与 sha1Hex 按 COS 签名文档独立计算签名，不依赖 auth.go 的实现
+func hmacSHA1Hex(key, msg string) string {
+	h := hmac.New(sha1.New, []byte(key))
+	h.Write([]byte(msg))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sha1Hex(msg string) string {
+	h := sha1.Sum([]byte(msg))
+	return hex.EncodeToString(h[:])
+}
+
+// cosSignature 计算 q-signature，kv 中的 key 需为小写
+func cosSignature(secretKey, method, path, keyTime string, params, headers map[string]string) string {
+	format := func(kv map[string]string) string {
+		keys := make([]string, 0, len(kv))
+		for k := range kv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, k+"="+strings.Replace(url.QueryEscape(kv[k]), "+", "%20", -1))
+		}
+		return strings.Join(pairs, "&")
+	}
+	httpString := strings.ToLower(method) + "\n" + path + "\n" + format(params) + "\n" + format(headers) + "\n"
+	stringToSign := "sha1\n" + keyTime + "\n" + sha1Hex(httpString) + "\n"
+	return hmacSHA1Hex(hmacSHA1Hex(secretKey, keyTime), stringToSign)
+}
+
+func TestPresigner_IndependentVectors(t *testing.T) {
+	u, _ := url.Parse("https://examplebucket-1250000000.cos.ap-guangzhou.myqcloud.com")
+	sk := "ZfbOA78asKUYBcXFrJD0a1I*******"
+	startTime := time.Unix(int64(1622702557), 0)
+	host := u.Host
+	cred := &Credential{SecretID: "QmFzZTY0IGlzIGEgZ*******", SecretKey: sk}
+	query := &url.Values{}
+	query.Set("response-content-type", "application/json")
+	query.Set("versionId", "MTg0NDUxNTc1NjIzMTQ1MDAwODg")
+	header := &http.Header{}
+	header.Set("Content-Type", "text/plain")
+	header.Set("X-Cos-Meta-Author", "cos")
+
+	cases := []struct {
+		name    string
+		method  string
+		key     string
+		opt     *PresignOptions
+		keyTime string
+		params  map[string]string
+		headers map[string]string
+	}{
+		{
+			"put-without-host", http.MethodPut, "test.jpg",
+			&PresignOptions{StartTime: startTime, SignHost: Bool(false)},
+			"1622702557;1622706157", nil, nil,
+		},
+		{
+			"get-with-host", http.MethodGet, "dir/test.jpg",
+			&PresignOptions{StartTime: startTime, Expire: time.Minute},
+			"1622702557;1622702617", nil, map[string]string{"host": host},
+		},
+		{
+			"get-with-query", http.MethodGet, "dir/test.jpg",
+			&PresignOptions{StartTime: startTime, Query: query},
+			"1622702557;1622706157",
+			map[string]string{"response-content-type": "application/json", "versionid": "MTg0NDUxNTc1NjIzMTQ1MDAwODg"},
+			map[string]string{"host": host},
+		},
+		{
+			"put-with-header", http.MethodPut, "test.txt",
+			&PresignOptions{StartTime: startTime, Header: header},
+			"1622702557;1622706157", nil,
+			map[string]string{"content-type": "text/plain", "host": host, "x-cos-meta-author": "cos"},
+		},
+	}
+	for _, c := range cases {
+		p := NewPresigner(&BaseURL{BucketURL: u}, cred)
+		h, err := p.SignHeader(c.method, c.key, c.opt)
+		if err != nil {
+			t.Fatalf("%v: SignHeader returned error: %v", c.name, err)
+		}
+		want := cosSignature(sk, c.method, "/"+c.key, c.keyTime, c.params, c.headers)
+		if !strings.HasSuffix(h.Get("Authorization"), "&q-signature="+want) {
+			t.Errorf("%v: got %v, want signature %v", c.name, h.Get("Authorization"), want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package cos
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	testSSECKey    = "0123456789ABCDEF0123456789ABCDEF"
+	testSSECKeyB64 = "MDEyMzQ1Njc4OUFCQ0RFRjAxMjM0NTY3ODlBQkNERUY="
+	testSSECKeyMD5 = "U5L61r7jcwdNvT7frmUG8g=="
+)
+
+func TestSSE_EncodeHeader(t *testing.T) {
+	cases := []struct {
+		sse  *SSE
+		key  string
+		want map[string]string
+	}{
+		{NewSSECOS(), "x-cos-server-side-encryption", map[string]string{
+			"x-cos-server-side-encryption": "AES256",
+		}},
+		{NewSSECOS(), "x-cos-server-side-encryption-customer", map[string]string{}},
+		{NewSSEKMS("kms-id", `{"k":"v"}`), "x-cos-server-side-encryption", map[string]string{
+			"x-cos-server-side-encryption":                "cos/kms",
+			"x-cos-server-side-encryption-cos-kms-key-id": "kms-id",
+			"x-cos-server-side-encryption-context":        base64.StdEncoding.EncodeToString([]byte(`{"k":"v"}`)),
+		}},
+		{NewSSEC([]byte(testSSECKey)), "x-cos-server-side-encryption", map[string]string{
+			"x-cos-server-side-encryption-customer-algorithm": "AES256",
+			"x-cos-server-side-encryption-customer-key":       testSSECKeyB64,
+			"x-cos-server-side-encryption-customer-key-MD5":   testSSECKeyMD5,
+		}},
+		{NewSSEC([]byte(testSSECKey)), "x-cos-copy-source-server-side-encryption-customer", map[string]string{
+			"x-cos-copy-source-server-side-encryption-customer-algorithm": "AES256",
+			"x-cos-copy-source-server-side-encryption-customer-key":       testSSECKeyB64,
+			"x-cos-copy-source-server-side-encryption-customer-key-MD5":   testSSECKeyMD5,
+		}},
+	}
+	for _, c := range cases {
+		h := http.Header{}
+		if err := c.sse.EncodeHeader(c.key, &h); err != nil {
+			t.Fatalf("EncodeHeader returned error: %v", err)
+		}
+		if len(h) != len(c.want) {
+			t.Errorf("EncodeHeader(%v) returned %v, want %v", c.key, h, c.want)
+		}
+		for k, v := range c.want {
+			if h.Get(k) != v {
+				t.Errorf("EncodeHeader(%v) header %v is %v, want %v", c.key, k, h.Get(k), v)
+			}
+		}
+	}
+
+	h := http.Header{}
+	if err := NewSSEC([]byte("short")).EncodeHeader("x-cos-server-side-encryption", &h); err == nil {
+		t.Errorf("EncodeHeader should return error for invalid SSE-C key")
+	}
+}
+
+func TestObjectService_SSE(t *testing.T) {
+	setup()
+	defer teardown()
+
+	sse := NewSSEC([]byte(testSSECKey))
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "x-cos-server-side-encryption-customer-key", testSSECKeyB64)
+		testHeader(t, r, "x-cos-server-side-encryption-customer-key-MD5", testSSECKeyMD5)
+		if r.Method == http.MethodPut {
+			testHeader(t, r, "x-cos-copy-source-server-side-encryption-customer-key", testSSECKeyB64)
+			w.Write([]byte("<CopyObjectResult><ETag>\"etag\"</ETag></CopyObjectResult>"))
+		}
+	})
+	_, err := client.Object.Get(context.Background(), "test", &ObjectGetOptions{SSE: sse})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	_, err = client.Object.Head(context.Background(), "test", &ObjectHeadOptions{SSE: sse})
+	if err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	sourceURL := strings.TrimPrefix(client.BaseURL.BucketURL.String(), "http://") + "/test"
+	_, _, err = client.Object.Copy(context.Background(), "test", sourceURL, &ObjectCopyOptions{
+		ObjectCopyHeaderOptions: &ObjectCopyHeaderOptions{
+			SSE:           sse,
+			CopySourceSSE: sse,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.Copy returned error: %v", err)
+	}
+
+	mux.HandleFunc("/kms", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		testHeader(t, r, "x-cos-server-side-encryption", "cos/kms")
+		testHeader(t, r, "x-cos-server-side-encryption-cos-kms-key-id", "kms-id")
+		w.Header().Set("x-cos-hash-crc64ecma", "18020588380933092773")
+	})
+	_, err = client.Object.Put(context.Background(), "kms", strings.NewReader("test"), &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			SSE: NewSSEKMS("kms-id", ""),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+}